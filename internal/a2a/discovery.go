@@ -10,6 +10,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/port402/x402-cli/internal/exitcode"
 )
 
 // DiscoveryPaths lists A2A agent card locations in priority order.
@@ -19,10 +21,30 @@ var DiscoveryPaths = []string{
 	"/.well-known/agents.json",     // Wildcard spec
 }
 
+// Option configures agent card discovery.
+type Option func(*discoverConfig)
+
+type discoverConfig struct {
+	transport http.RoundTripper
+}
+
+// WithTransport sets the transport used for discovery requests, e.g. to
+// record or replay traffic. A nil transport keeps the default.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *discoverConfig) {
+		c.transport = rt
+	}
+}
+
 // Discover attempts to find an agent card at the given URL.
 // If customPath is provided, only that path is tried.
 // Returns a Result with all attempted paths and any discovered card.
-func Discover(ctx context.Context, rawURL string, customPath string, timeout time.Duration) *Result {
+func Discover(ctx context.Context, rawURL string, customPath string, timeout time.Duration, opts ...Option) *Result {
+	var cfg discoverConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	result := &Result{
 		URL:        rawURL,
 		TriedPaths: []PathAttempt{},
@@ -31,13 +53,14 @@ func Discover(ctx context.Context, rawURL string, customPath string, timeout tim
 	baseURL, err := ExtractBaseURL(rawURL)
 	if err != nil {
 		result.Error = err.Error()
-		result.ExitCode = 4
+		result.ExitCode = int(exitcode.Input)
 		return result
 	}
 	result.BaseURL = baseURL
 
 	httpClient := &http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: cfg.transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= 3 {
 				return fmt.Errorf("stopped after 3 redirects")
@@ -75,7 +98,7 @@ func Discover(ctx context.Context, rawURL string, customPath string, timeout tim
 	}
 
 	if allNetworkErrors(result.TriedPaths) {
-		result.ExitCode = 3
+		result.ExitCode = int(exitcode.Network)
 		if result.Error == "" && len(result.TriedPaths) > 0 {
 			result.Error = result.TriedPaths[0].Error
 		}
@@ -98,10 +121,10 @@ func buildPaths(customPath string) []string {
 // evaluateAttempt checks if the attempt should terminate discovery with an error.
 func evaluateAttempt(status int, err error, customPath string) (exitCode int, errMsg string) {
 	if status == 0 && err != nil && customPath != "" {
-		return 3, err.Error()
+		return int(exitcode.Network), err.Error()
 	}
 	if status == http.StatusOK && err != nil {
-		return 4, err.Error()
+		return int(exitcode.Protocol), err.Error()
 	}
 	return 0, ""
 }
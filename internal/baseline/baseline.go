@@ -0,0 +1,184 @@
+// Package baseline pins the payment options an endpoint advertises and
+// detects drift from them, so a tampered or misconfigured 402 response
+// (e.g. a different payTo address) is caught before anything is paid.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileVersion is the current baseline file format version.
+const FileVersion = 1
+
+// Option is a pinned payment option.
+type Option struct {
+	Scheme  string `json:"scheme"`
+	Network string `json:"network"`
+	Asset   string `json:"asset"`
+	Amount  string `json:"amount"`
+	PayTo   string `json:"payTo"`
+}
+
+// Endpoint holds the pinned options for one method and URL.
+type Endpoint struct {
+	URL      string    `json:"url"`
+	Method   string    `json:"method"`
+	PinnedAt time.Time `json:"pinnedAt"`
+	Options  []Option  `json:"options"`
+}
+
+// File is a set of pinned endpoints as stored on disk.
+type File struct {
+	Version   int        `json:"version"`
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Drift is a single difference between the pinned and the current options.
+// Pinned and Current hold the differing values; for added and removed
+// options they summarize the whole option.
+type Drift struct {
+	Network string `json:"network"`
+	Asset   string `json:"asset"`
+	Field   string `json:"field"` // "amount", "payTo", "added" or "removed"
+	Pinned  string `json:"pinned,omitempty"`
+	Current string `json:"current,omitempty"`
+}
+
+// String formats the drift for terminal output.
+func (d Drift) String() string {
+	switch d.Field {
+	case "added":
+		return fmt.Sprintf("%s %s: option not in baseline (%s)", d.Network, d.Asset, d.Current)
+	case "removed":
+		return fmt.Sprintf("%s %s: pinned option missing (%s)", d.Network, d.Asset, d.Pinned)
+	default:
+		return fmt.Sprintf("%s %s: %s %s → %s", d.Network, d.Asset, d.Field, d.Pinned, d.Current)
+	}
+}
+
+// Load reads a baseline file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	if f.Version != FileVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", f.Version)
+	}
+	return &f, nil
+}
+
+// LoadOrNew reads a baseline file, or returns an empty one if it does not exist.
+func LoadOrNew(path string) (*File, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &File{Version: FileVersion}, nil
+	}
+	return Load(path)
+}
+
+// Save writes the baseline file with endpoints sorted for stable diffs.
+func (f *File) Save(path string) error {
+	sort.Slice(f.Endpoints, func(i, j int) bool {
+		if f.Endpoints[i].URL != f.Endpoints[j].URL {
+			return f.Endpoints[i].URL < f.Endpoints[j].URL
+		}
+		return f.Endpoints[i].Method < f.Endpoints[j].Method
+	})
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the pinned endpoint for method and url, or nil.
+func (f *File) Lookup(method, url string) *Endpoint {
+	for i := range f.Endpoints {
+		if f.Endpoints[i].URL == url && strings.EqualFold(f.Endpoints[i].Method, method) {
+			return &f.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+// Pin adds or replaces the endpoint for its method and URL.
+func (f *File) Pin(e Endpoint) {
+	if existing := f.Lookup(e.Method, e.URL); existing != nil {
+		*existing = e
+		return
+	}
+	f.Endpoints = append(f.Endpoints, e)
+}
+
+// Compare returns every difference between the pinned and current options.
+// Options are matched on network and asset; a changed asset therefore shows
+// up as one option removed and another added.
+func Compare(pinned, current []Option) []Drift {
+	var drifts []Drift
+	matched := make([]bool, len(pinned))
+
+	for _, cur := range current {
+		idx := -1
+		for i, pin := range pinned {
+			if !matched[i] && pin.Network == cur.Network && sameAddress(pin.Asset, cur.Asset) {
+				idx = i
+				break
+			}
+		}
+
+		if idx < 0 {
+			drifts = append(drifts, Drift{
+				Network: cur.Network,
+				Asset:   cur.Asset,
+				Field:   "added",
+				Current: describe(cur),
+			})
+			continue
+		}
+		matched[idx] = true
+
+		pin := pinned[idx]
+		if pin.Amount != cur.Amount {
+			drifts = append(drifts, Drift{Network: cur.Network, Asset: cur.Asset, Field: "amount", Pinned: pin.Amount, Current: cur.Amount})
+		}
+		if !sameAddress(pin.PayTo, cur.PayTo) {
+			drifts = append(drifts, Drift{Network: cur.Network, Asset: cur.Asset, Field: "payTo", Pinned: pin.PayTo, Current: cur.PayTo})
+		}
+	}
+
+	for i, pin := range pinned {
+		if !matched[i] {
+			drifts = append(drifts, Drift{Network: pin.Network, Asset: pin.Asset, Field: "removed", Pinned: describe(pin)})
+		}
+	}
+
+	return drifts
+}
+
+// describe summarizes an option that has no counterpart on the other side.
+func describe(o Option) string {
+	return fmt.Sprintf("%s %s to %s", o.Scheme, o.Amount, o.PayTo)
+}
+
+// sameAddress compares addresses, ignoring case for EVM hex addresses only
+// (Solana base58 addresses are case-sensitive).
+func sameAddress(a, b string) bool {
+	if strings.HasPrefix(a, "0x") && strings.HasPrefix(b, "0x") {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
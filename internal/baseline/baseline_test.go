@@ -0,0 +1,98 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func usdcOption() Option {
+	return Option{
+		Scheme:  "exact",
+		Network: "eip155:84532",
+		Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+		Amount:  "1000",
+		PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+	}
+}
+
+func TestCompare(t *testing.T) {
+	pinned := []Option{usdcOption()}
+
+	tests := []struct {
+		name   string
+		modify func(o *Option)
+		fields []string
+	}{
+		{"unchanged", func(o *Option) {}, nil},
+		{"payTo case only", func(o *Option) { o.PayTo = "0x64c2310bd1151266aa2ad2410447e133b7f84e29" }, nil},
+		{"amount", func(o *Option) { o.Amount = "2000" }, []string{"amount"}},
+		{"payTo", func(o *Option) { o.PayTo = "0x0000000000000000000000000000000000000001" }, []string{"payTo"}},
+		{"amount and payTo", func(o *Option) { o.Amount = "1"; o.PayTo = "0x01" }, []string{"amount", "payTo"}},
+		{"asset", func(o *Option) { o.Asset = "0xdead" }, []string{"added", "removed"}},
+		{"network", func(o *Option) { o.Network = "eip155:8453" }, []string{"added", "removed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := usdcOption()
+			tt.modify(&current)
+
+			var fields []string
+			for _, d := range Compare(pinned, []Option{current}) {
+				fields = append(fields, d.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestCompare_SolanaCaseSensitive(t *testing.T) {
+	pinned := []Option{{Network: "solana:devnet", Asset: "Mint", Amount: "1", PayTo: "AbCd"}}
+	current := []Option{{Network: "solana:devnet", Asset: "Mint", Amount: "1", PayTo: "abcd"}}
+
+	drift := Compare(pinned, current)
+	require.Len(t, drift, 1)
+	assert.Equal(t, "solana:devnet Mint: payTo AbCd → abcd", drift[0].String())
+}
+
+func TestFile_PinSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pinned.json")
+
+	f, err := LoadOrNew(path)
+	require.NoError(t, err)
+	assert.Empty(t, f.Endpoints)
+
+	pinnedAt := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	f.Pin(Endpoint{URL: "https://b.example", Method: "GET", PinnedAt: pinnedAt, Options: []Option{usdcOption()}})
+	f.Pin(Endpoint{URL: "https://a.example", Method: "POST", PinnedAt: pinnedAt})
+	f.Pin(Endpoint{URL: "https://b.example", Method: "GET", PinnedAt: pinnedAt.Add(time.Hour)})
+	require.NoError(t, f.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Endpoints, 2)
+	assert.Equal(t, "https://a.example", loaded.Endpoints[0].URL)
+
+	e := loaded.Lookup("get", "https://b.example")
+	require.NotNil(t, e)
+	assert.Equal(t, pinnedAt.Add(time.Hour), e.PinnedAt)
+	assert.Empty(t, e.Options)
+	assert.Nil(t, loaded.Lookup("GET", "https://a.example"))
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read baseline")
+
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte(`{"version": 9, "endpoints": []}`), 0600))
+	_, err = Load(bad)
+	assert.ErrorContains(t, err, "unsupported baseline version 9")
+}
@@ -0,0 +1,149 @@
+// Package bazaar reads the resource lists facilitators publish for discovery
+// (the "bazaar", e.g. GET /discovery/resources), which advertise each paid
+// resource together with the payment options it accepts.
+package bazaar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// maxPages bounds how many pages Fetch follows, in case a server reports an
+// inconsistent total.
+const maxPages = 100
+
+// List is a page of a discovery resource list.
+type List struct {
+	X402Version int         `json:"x402Version"`
+	Items       []Resource  `json:"items"`
+	Pagination  *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes where a page sits in the full list.
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// Resource is a listed resource and the payment options it advertises.
+type Resource struct {
+	Resource    string                 `json:"resource"`
+	Type        string                 `json:"type"`
+	X402Version int                    `json:"x402Version"`
+	Accepts     []Accept               `json:"accepts"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Accept is an advertised payment option. v1 listings describe the expected
+// request in outputSchema.
+type Accept struct {
+	x402.PaymentRequirement
+	OutputSchema *OutputSchema `json:"outputSchema,omitempty"`
+}
+
+// OutputSchema describes how to call a resource.
+type OutputSchema struct {
+	Input struct {
+		Type   string `json:"type"`
+		Method string `json:"method"`
+	} `json:"input"`
+}
+
+// IsHTTP reports whether the resource is an HTTP endpoint. Resources without
+// a type are assumed to be.
+func (r Resource) IsHTTP() bool {
+	return r.Type == "" || strings.EqualFold(r.Type, "http")
+}
+
+// Method returns the HTTP method the listing advertises, defaulting to GET.
+func (r Resource) Method() string {
+	for _, a := range r.Accepts {
+		if a.OutputSchema != nil && a.OutputSchema.Input.Method != "" {
+			return strings.ToUpper(a.OutputSchema.Input.Method)
+		}
+	}
+	return "GET"
+}
+
+// Parse decodes a discovery list.
+func Parse(data []byte) (*List, error) {
+	var list List
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse discovery list: %w", err)
+	}
+	if list.Items == nil {
+		return nil, fmt.Errorf("failed to parse discovery list: missing \"items\"")
+	}
+	return &list, nil
+}
+
+// Fetch downloads a discovery list, following pagination until every item
+// reported by the server has been read.
+func Fetch(ctx context.Context, client *http.Client, rawURL string) (*List, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery URL: %w", err)
+	}
+
+	var all *List
+	for page := 0; page < maxPages; page++ {
+		pageURL := *base
+		if all != nil {
+			query := pageURL.Query()
+			query.Set("offset", strconv.Itoa(len(all.Items)))
+			pageURL.RawQuery = query.Encode()
+		}
+
+		list, err := fetchPage(ctx, client, pageURL.String())
+		if err != nil {
+			return nil, err
+		}
+
+		if all == nil {
+			all = list
+		} else {
+			all.Items = append(all.Items, list.Items...)
+		}
+
+		p := list.Pagination
+		if p == nil || len(list.Items) == 0 || len(all.Items) >= p.Total {
+			break
+		}
+	}
+
+	all.Pagination = nil
+	return all, nil
+}
+
+func fetchPage(ctx context.Context, client *http.Client, pageURL string) (*List, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery list returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discovery list: %w", err)
+	}
+	return Parse(data)
+}
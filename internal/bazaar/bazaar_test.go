@@ -0,0 +1,77 @@
+package bazaar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	data, err := os.ReadFile("testdata/resources.json")
+	require.NoError(t, err)
+
+	list, err := Parse(data)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 3)
+
+	weather := list.Items[0]
+	assert.True(t, weather.IsHTTP())
+	assert.Equal(t, "POST", weather.Method())
+	require.Len(t, weather.Accepts, 1)
+	assert.Equal(t, "10000", weather.Accepts[0].GetAmount())
+	assert.Equal(t, "base-sepolia", weather.Accepts[0].Network)
+
+	search := list.Items[1]
+	assert.Equal(t, "GET", search.Method())
+	assert.Equal(t, "50000", search.Accepts[0].GetAmount())
+
+	assert.False(t, list.Items[2].IsHTTP())
+
+	_, err = Parse([]byte(`{"x402Version": 1}`))
+	assert.ErrorContains(t, err, `missing "items"`)
+
+	_, err = Parse([]byte(`not json`))
+	assert.ErrorContains(t, err, "failed to parse discovery list")
+}
+
+func TestFetch(t *testing.T) {
+	const total = 5
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		assert.Equal(t, "http", r.URL.Query().Get("type"))
+
+		list := List{X402Version: 1, Pagination: &Pagination{Limit: 2, Offset: offset, Total: total}}
+		for i := offset; i < offset+2 && i < total; i++ {
+			list.Items = append(list.Items, Resource{Resource: "https://api.example.com/" + strconv.Itoa(i)})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+
+	list, err := Fetch(context.Background(), server.Client(), server.URL+"/discovery/resources?type=http")
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, requests)
+	require.Len(t, list.Items, total)
+	assert.Equal(t, "https://api.example.com/4", list.Items[4].Resource)
+	assert.Nil(t, list.Pagination)
+}
+
+func TestFetch_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := Fetch(context.Background(), server.Client(), server.URL)
+	assert.ErrorContains(t, err, "discovery list returned 503")
+}
@@ -0,0 +1,354 @@
+// Package cassette records HTTP exchanges to a directory and replays them
+// without network access, for deterministic tests against third-party APIs.
+//
+// Requests are matched on method, URL, body and which x402 payment headers
+// are present, never on payment header values, so replays stay stable even
+// though payment nonces and timestamps change on every run. Repeated
+// identical requests are replayed in the order they were recorded.
+//
+// Credential headers and signed payment payloads are redacted before they
+// are written, so cassettes can be committed.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// Interaction is one recorded exchange, stored as a JSON file.
+type Interaction struct {
+	Request  RecordedRequest   `json:"request"`
+	Response *RecordedResponse `json:"response,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// RecordedRequest is the request half of an interaction.
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response half of an interaction.
+type RecordedResponse struct {
+	Status       int         `json:"status"`
+	StatusText   string      `json:"statusText"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"` // "base64" for binary bodies
+}
+
+// redactedValue replaces the value of redacted headers.
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedHeaders lists the headers that are always redacted in
+// recorded requests and responses: credentials, cookies and the signed
+// payment payload of every protocol version.
+func DefaultRedactedHeaders() []string {
+	return append([]string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Api-Key",
+	}, paymentHeaders()...)
+}
+
+// ErrNotRecorded is returned in replay mode for requests missing from the cassette.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Recorder is an http.RoundTripper that performs requests and saves each
+// exchange to a directory.
+type Recorder struct {
+	dir      string
+	next     http.RoundTripper
+	redact   []string
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+// Option configures the Recorder.
+type Option func(*Recorder)
+
+// WithRedactedHeaders redacts these headers in addition to
+// DefaultRedactedHeaders, e.g. a custom API key header.
+func WithRedactedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		r.redact = append(r.redact, names...)
+	}
+}
+
+// NewRecorder creates dir if needed and returns a recording transport.
+// Interactions left in dir by an earlier recording are removed, so a replay
+// never serves stale exchanges. A nil next uses http.DefaultTransport.
+func NewRecorder(dir string, next http.RoundTripper, opts ...Option) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	// Every JSON file in the directory is replayed as an interaction
+	stale, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette directory: %w", err)
+	}
+	for _, file := range stale {
+		if err := os.Remove(file); err != nil {
+			return nil, fmt.Errorf("failed to clear cassette: %w", err)
+		}
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{dir: dir, next: next, redact: DefaultRedactedHeaders(), counters: make(map[string]int)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{Request: RecordedRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: r.redactHeaders(req.Header),
+		Body:    string(reqBody),
+	}}
+
+	resp, rtErr := r.next.RoundTrip(req)
+	if rtErr != nil {
+		interaction.Error = rtErr.Error()
+	} else {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		interaction.Response = recordResponse(resp, body)
+		interaction.Response.Headers = r.redactHeaders(interaction.Response.Headers)
+	}
+
+	key := matchKey(req.Method, req.URL.String(), req.Header, reqBody)
+	if err := r.save(key, interaction); err != nil {
+		return nil, err
+	}
+
+	return resp, rtErr
+}
+
+// redactHeaders returns a copy of header with redacted values replaced.
+// Redacted headers stay present, so replay still matches on which payment
+// headers a request carried.
+func (r *Recorder) redactHeaders(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range r.redact {
+		if values, ok := header[http.CanonicalHeaderKey(name)]; ok {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return header
+}
+
+func (r *Recorder) save(key string, interaction *Interaction) error {
+	r.mu.Lock()
+	r.counters[key]++
+	n := r.counters[key]
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode interaction: %w", err)
+	}
+
+	path := filepath.Join(r.dir, fileName(interaction.Request.Method, interaction.Request.URL, key, n))
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an http.RoundTripper that serves recorded exchanges and never
+// touches the network.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]*Interaction
+}
+
+// NewReplayer loads every interaction recorded in dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded interactions in %s", dir)
+	}
+	// File names end in the occurrence number, so sorting keeps recorded order
+	sort.Strings(files)
+
+	r := &Replayer{interactions: make(map[string][]*Interaction)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", filepath.Base(file), err)
+		}
+
+		req := interaction.Request
+		key := matchKey(req.Method, req.URL, req.Headers, []byte(req.Body))
+		r.interactions[key] = append(r.interactions[key], &interaction)
+	}
+
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := matchKey(req.Method, req.URL.String(), req.Header, reqBody)
+
+	r.mu.Lock()
+	queue := r.interactions[key]
+	var interaction *Interaction
+	if len(queue) > 0 {
+		interaction = queue[0]
+		r.interactions[key] = queue[1:]
+	}
+	r.mu.Unlock()
+
+	if interaction == nil {
+		return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, req.URL)
+	}
+	if interaction.Response == nil {
+		return nil, errors.New(interaction.Error)
+	}
+
+	return replayResponse(req, interaction.Response)
+}
+
+// matchKey identifies a request independent of payment header values.
+func matchKey(method, url string, header http.Header, body []byte) string {
+	var present []string
+	for _, name := range paymentHeaders() {
+		if header.Get(name) != "" {
+			present = append(present, name)
+		}
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", strings.ToUpper(method), url, strings.Join(present, ","))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// paymentHeaders lists the request headers that carry per-run payment data.
+func paymentHeaders() []string {
+	var names []string
+	for _, p := range x402.Protocols() {
+		names = append(names, p.PaymentHeader)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// fileName builds a stable, readable file name for an interaction.
+func fileName(method, rawURL, key string, n int) string {
+	slug := rawURL
+	if i := strings.Index(slug, "://"); i >= 0 {
+		slug = slug[i+3:]
+	}
+	slug = strings.Trim(unsafeFileChars.ReplaceAllString(slug, "_"), "_")
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	return fmt.Sprintf("%s_%s_%s_%03d.json", strings.ToLower(method), slug, key[:12], n)
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func recordResponse(resp *http.Response, body []byte) *RecordedResponse {
+	recorded := &RecordedResponse{
+		Status:     resp.StatusCode,
+		StatusText: resp.Status,
+		Headers:    resp.Header.Clone(),
+	}
+	if utf8.Valid(body) {
+		recorded.Body = string(body)
+	} else {
+		recorded.Body = base64.StdEncoding.EncodeToString(body)
+		recorded.BodyEncoding = "base64"
+	}
+	return recorded
+}
+
+func replayResponse(req *http.Request, recorded *RecordedResponse) (*http.Response, error) {
+	body := []byte(recorded.Body)
+	if recorded.BodyEncoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(recorded.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recorded body: %w", err)
+		}
+		body = decoded
+	}
+
+	header := recorded.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        recorded.StatusText,
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
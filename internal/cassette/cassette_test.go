@@ -0,0 +1,231 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func doRequest(t *testing.T, rt http.RoundTripper, method, url, body string, header map[string]string) (*http.Response, string, error) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data), nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.Header.Get(x402.HeaderPaymentSignature) == "" {
+			w.Header().Set(x402.HeaderPaymentRequired, "eyJ4NDAyVmVyc2lvbiI6Mn0=")
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.Write([]byte("paid #" + string(rune('0'+n))))
+	}))
+
+	dir := filepath.Join(t.TempDir(), "cassette")
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+
+	resp, _, err := doRequest(t, recorder, "GET", server.URL+"/data", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+
+	_, body, err := doRequest(t, recorder, "GET", server.URL+"/data", "", map[string]string{x402.HeaderPaymentSignature: "nonce-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "paid #2", body)
+
+	_, body, err = doRequest(t, recorder, "GET", server.URL+"/data", "", map[string]string{x402.HeaderPaymentSignature: "nonce-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "paid #3", body)
+
+	server.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	resp, _, err = doRequest(t, replayer, "GET", server.URL+"/data", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+	assert.Equal(t, "eyJ4NDAyVmVyc2lvbiI6Mn0=", resp.Header.Get(x402.HeaderPaymentRequired))
+
+	// Payment header values differ from the recording but still match, in order
+	_, body, err = doRequest(t, replayer, "GET", server.URL+"/data", "", map[string]string{x402.HeaderPaymentSignature: "fresh-nonce"})
+	require.NoError(t, err)
+	assert.Equal(t, "paid #2", body)
+
+	_, body, err = doRequest(t, replayer, "GET", server.URL+"/data", "", map[string]string{x402.HeaderPaymentSignature: "other-nonce"})
+	require.NoError(t, err)
+	assert.Equal(t, "paid #3", body)
+
+	// Exhausted
+	_, _, err = doRequest(t, replayer, "GET", server.URL+"/data", "", map[string]string{x402.HeaderPaymentSignature: "x"})
+	assert.True(t, errors.Is(err, ErrNotRecorded))
+}
+
+func TestRecorder_RedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server still sees the real values
+		assert.Equal(t, "Bearer sk-live-secret", r.Header.Get("Authorization"))
+		w.Header().Set("Set-Cookie", "session=cookie-secret")
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil, WithRedactedHeaders("X-Custom-Token"))
+	require.NoError(t, err)
+
+	_, _, err = doRequest(t, recorder, "GET", server.URL+"/data", "", map[string]string{
+		"Authorization":             "Bearer sk-live-secret",
+		"X-Custom-Token":            "custom-secret",
+		x402.HeaderPaymentSignature: "signed-payload-secret",
+		x402.HeaderXPayment:         "v1-payload-secret",
+		"Accept":                    "application/json",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	for _, secret := range []string{"sk-live-secret", "custom-secret", "signed-payload-secret", "v1-payload-secret", "cookie-secret"} {
+		assert.NotContains(t, string(data), secret)
+	}
+	assert.Contains(t, string(data), "application/json")
+
+	// Redacted payment headers still match a fresh request on replay
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	_, body, err := doRequest(t, replayer, "GET", server.URL+"/data", "", map[string]string{
+		x402.HeaderPaymentSignature: "new-payload",
+		x402.HeaderXPayment:         "new-v1-payload",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", body)
+}
+
+func TestRecorder_ReplacesEarlierRecording(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+	_, _, err = doRequest(t, recorder, "GET", server.URL+"/old", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o644))
+
+	// Recording again starts from an empty cassette
+	recorder, err = NewRecorder(dir, nil)
+	require.NoError(t, err)
+	_, _, err = doRequest(t, recorder, "GET", server.URL+"/new", "", nil)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	_, _, err = doRequest(t, replayer, "GET", server.URL+"/old", "", nil)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+	_, body, err := doRequest(t, replayer, "GET", server.URL+"/new", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/new", body)
+}
+
+func TestReplay_MatchesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("echo:"), body...))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+
+	_, _, err = doRequest(t, recorder, "POST", server.URL, "a", nil)
+	require.NoError(t, err)
+	_, _, err = doRequest(t, recorder, "POST", server.URL, "b", nil)
+	require.NoError(t, err)
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	_, body, err := doRequest(t, replayer, "POST", server.URL, "b", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "echo:b", body)
+
+	_, _, err = doRequest(t, replayer, "POST", server.URL, "c", nil)
+	assert.True(t, errors.Is(err, ErrNotRecorded))
+}
+
+func TestRecordAndReplay_NetworkError(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+
+	_, _, err = doRequest(t, recorder, "GET", "http://localhost:59999/down", "", nil)
+	require.Error(t, err)
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	_, _, err = doRequest(t, replayer, "GET", "http://localhost:59999/down", "", nil)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotRecorded))
+}
+
+func TestNewReplayer_Errors(t *testing.T) {
+	_, err := NewReplayer(t.TempDir())
+	assert.ErrorContains(t, err, "no recorded interactions")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0600))
+	_, err = NewReplayer(dir)
+	assert.ErrorContains(t, err, "invalid cassette bad.json")
+}
+
+func TestFileName(t *testing.T) {
+	name := fileName("GET", "https://api.example.com/v1/data?x=1", strings.Repeat("ab", 32), 2)
+	assert.Equal(t, "get_api.example.com_v1_data_x_1_abababababab_002.json", name)
+}
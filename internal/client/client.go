@@ -3,6 +3,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,25 @@ import (
 type Client struct {
 	httpClient *http.Client
 	headers    map[string]string
+	recorder   Recorder
+	retry      RetryPolicy
+}
+
+// Exchange is a completed request/response pair captured by a Recorder.
+type Exchange struct {
+	Request      *http.Request
+	RequestBody  []byte
+	Response     *http.Response // nil if the request failed
+	ResponseBody []byte
+	Err          error
+	Started      time.Time
+	Duration     time.Duration
+}
+
+// Recorder receives every exchange made through Client.Do, including each
+// retried attempt.
+type Recorder interface {
+	Record(exchange *Exchange)
 }
 
 // Option configures the Client.
@@ -41,6 +61,25 @@ func WithHeaders(headers map[string]string) Option {
 	}
 }
 
+// WithTransport sets the transport used to send requests, e.g. to record or
+// replay traffic. A nil transport keeps the default.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		if rt != nil {
+			c.httpClient.Transport = rt
+		}
+	}
+}
+
+// WithRecorder captures every request and response made by the client.
+// Response bodies are buffered so the recorder and the caller both see them.
+// A nil recorder is ignored.
+func WithRecorder(r Recorder) Option {
+	return func(c *Client) {
+		c.recorder = r
+	}
+}
+
 // New creates a new Client with the given options.
 func New(opts ...Option) *Client {
 	c := &Client{
@@ -58,8 +97,8 @@ func New(opts ...Option) *Client {
 }
 
 // Get performs a GET request to the given URL.
-func (c *Client) Get(url string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -68,8 +107,8 @@ func (c *Client) Get(url string) (*http.Response, error) {
 }
 
 // GetWithHeader performs a GET request with an additional header.
-func (c *Client) GetWithHeader(url, headerName, headerValue string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func (c *Client) GetWithHeader(ctx context.Context, url, headerName, headerValue string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -80,13 +119,61 @@ func (c *Client) GetWithHeader(url, headerName, headerValue string) (*http.Respo
 }
 
 // Request performs an HTTP request with the given method, URL, headers, and body.
-func (c *Client) Request(method, url string, headers map[string]string, body []byte) (*http.Response, error) {
+// Cancelling ctx aborts the request.
+func (c *Client) Request(ctx context.Context, method, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, err := newRequest(ctx, method, url, headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+// Do performs the HTTP request with default headers applied, retrying it if
+// the client has a retry policy that allows it.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	resp, _, err := c.do(req)
+	return resp, err
+}
+
+// do is Do, also describing the attempt that produced the response.
+func (c *Client) do(req *http.Request) (*http.Response, attempt, error) {
+	// Apply default headers
+	for k, v := range c.headers {
+		if req.Header.Get(k) == "" { // Don't override if already set
+			req.Header.Set(k, v)
+		}
+	}
+
+	return c.doWithRetry(req)
+}
+
+// send performs a single attempt of the request.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.recorder == nil {
+		return c.roundTrip(req)
+	}
+	return c.doRecorded(req)
+}
+
+// roundTrip sends req over the network, timing the response body transfer if
+// req is traced.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err == nil {
+		traceBody(req, resp)
+	}
+	return resp, err
+}
+
+// newRequest builds a request with a replayable body and the given headers.
+func newRequest(ctx context.Context, method, url string, headers map[string]string, body []byte) (*http.Request, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		bodyReader = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -96,50 +183,77 @@ func (c *Client) Request(method, url string, headers map[string]string, body []b
 		req.Header.Set(k, v)
 	}
 
-	return c.Do(req)
+	return req, nil
 }
 
-// Do performs the HTTP request with default headers applied.
-func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	// Apply default headers
-	for k, v := range c.headers {
-		if req.Header.Get(k) == "" { // Don't override if already set
-			req.Header.Set(k, v)
+// doRecorded performs the request and hands a copy of the exchange to the recorder.
+func (c *Client) doRecorded(req *http.Request) (*http.Response, error) {
+	exchange := &Exchange{Request: req, Started: time.Now()}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			exchange.RequestBody, _ = io.ReadAll(body)
+			body.Close()
 		}
 	}
 
-	return c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
+	if err != nil {
+		exchange.Err = err
+		exchange.Duration = time.Since(exchange.Started)
+		c.recorder.Record(exchange)
+		return nil, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	exchange.Duration = time.Since(exchange.Started)
+	exchange.Response = resp
+	exchange.ResponseBody = respBody
+	exchange.Err = readErr
+	c.recorder.Record(exchange)
+
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", readErr)
+	}
+	return resp, nil
 }
 
 // RequestResult contains timing and response information.
 type RequestResult struct {
 	Response  *http.Response
-	Latency   time.Duration
+	Latency   time.Duration // Of the final attempt
 	LatencyMs int64
+	Attempts  int // 1 unless the request was retried
+
+	trace *tracer
+}
+
+// Timings returns the phase timings of the final attempt. Transfer is only
+// known once the response body has been read to the end or closed.
+func (r *RequestResult) Timings() Timings {
+	if r.trace == nil {
+		return Timings{}
+	}
+	return r.trace.timings()
 }
 
 // TimedGet performs a GET request and records the latency.
-func (c *Client) TimedGet(url string) (*RequestResult, error) {
-	start := time.Now()
-	resp, err := c.Get(url)
-	latency := time.Since(start)
+func (c *Client) TimedGet(ctx context.Context, url string) (*RequestResult, error) {
+	return c.TimedRequest(ctx, http.MethodGet, url, nil, nil)
+}
 
+// TimedRequest performs a timed HTTP request.
+func (c *Client) TimedRequest(ctx context.Context, method, url string, headers map[string]string, body []byte) (*RequestResult, error) {
+	req, err := newRequest(ctx, method, url, headers, body)
 	if err != nil {
 		return nil, err
 	}
 
-	return &RequestResult{
-		Response:  resp,
-		Latency:   latency,
-		LatencyMs: latency.Milliseconds(),
-	}, nil
-}
-
-// TimedRequest performs a timed HTTP request.
-func (c *Client) TimedRequest(method, url string, headers map[string]string, body []byte) (*RequestResult, error) {
-	start := time.Now()
-	resp, err := c.Request(method, url, headers, body)
-	latency := time.Since(start)
+	resp, last, err := c.do(req)
+	latency := time.Since(last.started)
 
 	if err != nil {
 		return nil, err
@@ -149,6 +263,8 @@ func (c *Client) TimedRequest(method, url string, headers map[string]string, bod
 		Response:  resp,
 		Latency:   latency,
 		LatencyMs: latency.Milliseconds(),
+		Attempts:  last.number,
+		trace:     last.trace,
 	}, nil
 }
 
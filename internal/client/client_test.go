@@ -1,6 +1,8 @@
 package client
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -43,7 +45,7 @@ func TestClient_Get_Success(t *testing.T) {
 	defer server.Close()
 
 	c := New()
-	resp, err := c.Get(server.URL)
+	resp, err := c.Get(context.Background(), server.URL)
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
@@ -54,7 +56,7 @@ func TestClient_Get_Error(t *testing.T) {
 	c := New(WithTimeout(100 * time.Millisecond))
 
 	// Try to connect to a non-existent server
-	_, err := c.Get("http://localhost:99999/nonexistent")
+	_, err := c.Get(context.Background(), "http://localhost:99999/nonexistent")
 	require.Error(t, err)
 }
 
@@ -66,7 +68,7 @@ func TestClient_GetWithHeader(t *testing.T) {
 	defer server.Close()
 
 	c := New()
-	resp, err := c.GetWithHeader(server.URL, "X-Test", "test-value")
+	resp, err := c.GetWithHeader(context.Background(), server.URL, "X-Test", "test-value")
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
@@ -83,6 +85,7 @@ func TestClient_Request_POST(t *testing.T) {
 
 	c := New()
 	resp, err := c.Request(
+		context.Background(),
 		http.MethodPost,
 		server.URL,
 		map[string]string{"Content-Type": "application/json"},
@@ -104,6 +107,7 @@ func TestClient_Request_CustomHeaders(t *testing.T) {
 
 	c := New()
 	resp, err := c.Request(
+		context.Background(),
 		http.MethodGet,
 		server.URL,
 		map[string]string{
@@ -126,7 +130,7 @@ func TestClient_Do_DefaultHeaders(t *testing.T) {
 	defer server.Close()
 
 	c := New(WithHeader("X-Default", "default-value"))
-	resp, err := c.Get(server.URL)
+	resp, err := c.Get(context.Background(), server.URL)
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
@@ -142,7 +146,7 @@ func TestClient_Do_NoOverrideExisting(t *testing.T) {
 	defer server.Close()
 
 	c := New(WithHeader("X-Custom", "default-value"))
-	resp, err := c.GetWithHeader(server.URL, "X-Custom", "request-value")
+	resp, err := c.GetWithHeader(context.Background(), server.URL, "X-Custom", "request-value")
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
@@ -157,7 +161,7 @@ func TestTimedGet_Latency(t *testing.T) {
 	defer server.Close()
 
 	c := New()
-	result, err := c.TimedGet(server.URL)
+	result, err := c.TimedGet(context.Background(), server.URL)
 	require.NoError(t, err)
 	defer result.Response.Body.Close()
 
@@ -173,7 +177,7 @@ func TestTimedRequest_Latency(t *testing.T) {
 	defer server.Close()
 
 	c := New()
-	result, err := c.TimedRequest(http.MethodGet, server.URL, nil, nil)
+	result, err := c.TimedRequest(context.Background(), http.MethodGet, server.URL, nil, nil)
 	require.NoError(t, err)
 	defer result.Response.Body.Close()
 
@@ -236,7 +240,7 @@ func TestClient_402Response(t *testing.T) {
 	defer server.Close()
 
 	c := New()
-	resp, err := c.Get(server.URL)
+	resp, err := c.Get(context.Background(), server.URL)
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
@@ -259,14 +263,84 @@ func TestClient_WithPaymentHeader(t *testing.T) {
 	c := New()
 
 	// First request without payment header
-	resp1, err := c.Get(server.URL)
+	resp1, err := c.Get(context.Background(), server.URL)
 	require.NoError(t, err)
 	resp1.Body.Close()
 	assert.Equal(t, http.StatusPaymentRequired, resp1.StatusCode)
 
 	// Second request with payment header
-	resp2, err := c.GetWithHeader(server.URL, "Payment-Signature", "signed-payload")
+	resp2, err := c.GetWithHeader(context.Background(), server.URL, "Payment-Signature", "signed-payload")
 	require.NoError(t, err)
 	defer resp2.Body.Close()
 	assert.Equal(t, http.StatusOK, resp2.StatusCode)
 }
+
+type recorderFunc func(*Exchange)
+
+func (f recorderFunc) Record(e *Exchange) { f(e) }
+
+func TestClient_WithRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte("echo:"), body...))
+	}))
+	defer server.Close()
+
+	var recorded []*Exchange
+	c := New(WithRecorder(recorderFunc(func(e *Exchange) {
+		recorded = append(recorded, e)
+	})))
+
+	resp, err := c.Request(context.Background(), http.MethodPost, server.URL, map[string]string{"X-Test": "1"}, []byte("hello"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Caller still sees the full body
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "echo:hello", string(body))
+
+	require.Len(t, recorded, 1)
+	e := recorded[0]
+	assert.Equal(t, "1", e.Request.Header.Get("X-Test"))
+	assert.Equal(t, "hello", string(e.RequestBody))
+	assert.Equal(t, http.StatusCreated, e.Response.StatusCode)
+	assert.Equal(t, "echo:hello", string(e.ResponseBody))
+	assert.NoError(t, e.Err)
+	assert.False(t, e.Started.IsZero())
+}
+
+func TestClient_WithRecorder_Error(t *testing.T) {
+	var recorded []*Exchange
+	c := New(WithTimeout(100*time.Millisecond), WithRecorder(recorderFunc(func(e *Exchange) {
+		recorded = append(recorded, e)
+	})))
+
+	_, err := c.Get(context.Background(), "http://localhost:99999/nonexistent")
+	require.Error(t, err)
+
+	require.Len(t, recorded, 1)
+	assert.Nil(t, recorded[0].Response)
+	assert.Error(t, recorded[0].Err)
+}
+
+func TestRequest_ContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	c := New()
+	start := time.Now()
+	_, err := c.Request(ctx, http.MethodGet, server.URL, nil, nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
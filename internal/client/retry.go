@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// HeaderIdempotencyKey marks a request as safe to repeat. Paid requests are
+// only retried when they carry it.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// Default retry delays.
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+)
+
+// RetryPolicy retries requests that fail with a connection error or a
+// transient status (408, 429, 500, 502, 503, 504).
+//
+// Delays grow exponentially from BaseDelay with random jitter. A Retry-After
+// header replaces the computed delay; if it asks for longer than MaxDelay the
+// response is returned instead of waiting.
+//
+// Requests carrying a payment header (Payment-Signature or X-Payment) are
+// only retried if they also carry an Idempotency-Key header. A retry resends
+// the identical payment header and body, never a new signature, so the
+// server sees the same authorization again rather than a second payment.
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // First delay, doubled on each retry (default 500ms)
+	MaxDelay   time.Duration // Cap on any delay, including Retry-After (default 30s)
+}
+
+// WithRetry retries failed requests according to p.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		if p.BaseDelay <= 0 {
+			p.BaseDelay = DefaultRetryBaseDelay
+		}
+		if p.MaxDelay <= 0 {
+			p.MaxDelay = DefaultRetryMaxDelay
+		}
+		c.retry = p
+	}
+}
+
+// CanRetry reports whether the policy allows retrying req: its body must be
+// replayable, and a paid request must carry an Idempotency-Key.
+func CanRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if req.Header.Get(x402.HeaderPaymentSignature) != "" || req.Header.Get(x402.HeaderXPayment) != "" {
+		return req.Header.Get(HeaderIdempotencyKey) != ""
+	}
+	return true
+}
+
+// attempt describes the last attempt of a possibly retried request.
+type attempt struct {
+	number  int       // 1-based
+	started time.Time // When the last attempt was sent
+	trace   *tracer   // Phase timings of the last attempt
+}
+
+// try sends req once as attempt number n, tracing its timings.
+func (c *Client) try(req *http.Request, n int) (*http.Response, attempt, error) {
+	traced, tr := withTrace(req)
+	last := attempt{number: n, started: time.Now(), trace: tr}
+	resp, err := c.send(traced)
+	return resp, last, err
+}
+
+// doWithRetry sends req, retrying per the client's policy. It returns the
+// final response and which attempt produced it.
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, attempt, error) {
+	if c.retry.MaxRetries <= 0 || !CanRetry(req) {
+		return c.try(req, 1)
+	}
+
+	ctx := req.Context()
+	for n := 1; ; n++ {
+		resp, last, err := c.try(req, n)
+		if n > c.retry.MaxRetries || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, last, err
+		}
+
+		delay := c.retry.delay(n)
+		if resp != nil {
+			if retryAfter := ParseRetryAfter(resp); retryAfter > 0 {
+				if retryAfter > c.retry.MaxDelay {
+					return resp, last, nil
+				}
+				delay = retryAfter
+			}
+		}
+
+		if !sleep(ctx, delay) {
+			if resp != nil {
+				return resp, last, nil
+			}
+			return nil, last, ctx.Err()
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return nil, last, rewindErr
+		}
+		req = next
+	}
+}
+
+// retryable reports whether a request that ended with resp or err is worth
+// repeating.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns the backoff before retry number attempt (1-based):
+// BaseDelay doubled per attempt, capped at MaxDelay, with the upper half
+// randomized so parallel clients spread out.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// rewind returns a copy of req with a fresh body for another attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// sleep waits for d, returning false if ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// fastRetry retries quickly enough for tests.
+func fastRetry(n int) Option {
+	return WithRetry(RetryPolicy{MaxRetries: n, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+}
+
+// flakyServer fails the first failures requests with status, then returns 200.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRetry_TransientStatus(t *testing.T) {
+	for _, status := range []int{408, 429, 500, 502, 503, 504} {
+		server, calls := flakyServer(t, 2, status, nil)
+		c := New(fastRetry(3))
+
+		result, err := c.TimedGet(context.Background(), server.URL)
+		require.NoError(t, err)
+		defer result.Response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, result.Response.StatusCode, "status %d", status)
+		assert.Equal(t, 3, result.Attempts)
+		assert.Equal(t, int32(3), calls.Load())
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	server, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	c := New(fastRetry(2))
+
+	result, err := c.TimedGet(context.Background(), server.URL)
+	require.NoError(t, err)
+	defer result.Response.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, result.Response.StatusCode)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetry_NotRetried(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		opts   []Option
+	}{
+		{"402 is not transient", http.StatusPaymentRequired, []Option{fastRetry(3)}},
+		{"404 is not transient", http.StatusNotFound, []Option{fastRetry(3)}},
+		{"retries disabled", http.StatusServiceUnavailable, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flakyServer(t, 1, tt.status, nil)
+			c := New(tt.opts...)
+
+			result, err := c.TimedGet(context.Background(), server.URL)
+			require.NoError(t, err)
+			defer result.Response.Body.Close()
+
+			assert.Equal(t, tt.status, result.Response.StatusCode)
+			assert.Equal(t, 1, result.Attempts)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func TestRetry_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	c := New(fastRetry(2))
+	_, err := c.TimedGet(context.Background(), url)
+	assert.Error(t, err)
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	server, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	c := New(fastRetry(1))
+
+	start := time.Now()
+	result, err := c.TimedGet(context.Background(), server.URL)
+	require.NoError(t, err)
+	defer result.Response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, result.Response.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Less(t, result.Latency, time.Second, "latency covers only the final attempt")
+}
+
+func TestRetry_RetryAfterBeyondMaxDelay(t *testing.T) {
+	server, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"120"}})
+	c := New(fastRetry(3))
+
+	result, err := c.TimedGet(context.Background(), server.URL)
+	require.NoError(t, err)
+	defer result.Response.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, result.Response.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetry_ReplaysBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(fastRetry(1))
+	result, err := c.TimedRequest(context.Background(), "POST", server.URL, nil, []byte(`{"q": "test"}`))
+	require.NoError(t, err)
+	defer result.Response.Body.Close()
+
+	assert.Equal(t, []string{`{"q": "test"}`, `{"q": "test"}`}, bodies)
+}
+
+func TestRetry_PaidRequest(t *testing.T) {
+	t.Run("without idempotency key", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		c := New(fastRetry(3))
+
+		headers := map[string]string{x402.HeaderPaymentSignature: "signed"}
+		result, err := c.TimedRequest(context.Background(), "GET", server.URL, headers, nil)
+		require.NoError(t, err)
+		defer result.Response.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, result.Response.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("with idempotency key", func(t *testing.T) {
+		var seen []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = append(seen, r.Header.Get(x402.HeaderXPayment)+"|"+r.Header.Get(HeaderIdempotencyKey))
+			if len(seen) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		c := New(fastRetry(3))
+		headers := map[string]string{x402.HeaderXPayment: "signed", HeaderIdempotencyKey: "order-42"}
+		result, err := c.TimedRequest(context.Background(), "GET", server.URL, headers, nil)
+		require.NoError(t, err)
+		defer result.Response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, result.Response.StatusCode)
+		assert.Equal(t, []string{"signed|order-42", "signed|order-42"}, seen)
+	})
+}
+
+func TestRetry_CancelledDuringBackoff(t *testing.T) {
+	server, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	c := New(WithRetry(RetryPolicy{MaxRetries: 3, BaseDelay: 10 * time.Second}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := c.TimedGet(ctx, server.URL)
+	require.NoError(t, err)
+	defer result.Response.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, result.Response.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for i := 0; i < 20; i++ {
+		d := p.delay(1)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 100*time.Millisecond)
+
+		d = p.delay(3)
+		assert.GreaterOrEqual(t, d, 200*time.Millisecond)
+		assert.LessOrEqual(t, d, 400*time.Millisecond)
+
+		d = p.delay(10)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+}
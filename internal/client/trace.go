@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks a request's latency into phases. Phases that did not happen,
+// such as DNS, Connect and TLS on a reused connection, are zero. When a
+// request is redirected, the timings describe the final hop.
+type Timings struct {
+	DNS      time.Duration // Resolving the host name
+	Connect  time.Duration // Opening the TCP connection
+	TLS      time.Duration // TLS handshake
+	Server   time.Duration // From the request being written to the first response byte
+	TTFB     time.Duration // From asking for a connection to the first response byte
+	Transfer time.Duration // From the first response byte until the body was read to the end or closed
+	Reused   bool          // The request went over a reused connection
+}
+
+// tracer collects Timings for one attempt through httptrace hooks. Dial hooks
+// may run concurrently (e.g. racing IPv4 and IPv6), so fields are locked.
+type tracer struct {
+	mu sync.Mutex
+	at marks
+	t  Timings
+}
+
+// marks are the instants the phases started.
+type marks struct {
+	start     time.Time
+	dns       time.Time
+	connect   time.Time
+	tls       time.Time
+	wrote     time.Time
+	firstByte time.Time
+}
+
+type tracerKey struct{}
+
+// withTrace returns req with a fresh tracer attached.
+func withTrace(req *http.Request) (*http.Request, *tracer) {
+	tr := &tracer{}
+	ctx := context.WithValue(req.Context(), tracerKey{}, tr)
+	ctx = httptrace.WithClientTrace(ctx, tr.clientTrace())
+	return req.WithContext(ctx), tr
+}
+
+func (tr *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			// A redirect starts over; report the final hop
+			tr.at = marks{start: time.Now()}
+			tr.t = Timings{}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.t.Reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.at.dns = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.t.DNS = time.Since(tr.at.dns)
+		},
+		ConnectStart: func(string, string) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			if tr.at.connect.IsZero() {
+				tr.at.connect = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			if err == nil && tr.t.Connect == 0 {
+				tr.t.Connect = time.Since(tr.at.connect)
+			}
+		},
+		TLSHandshakeStart: func() {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.at.tls = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.t.TLS = time.Since(tr.at.tls)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.at.wrote = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.at.firstByte = time.Now()
+			tr.t.TTFB = tr.at.firstByte.Sub(tr.at.start)
+			if !tr.at.wrote.IsZero() {
+				tr.t.Server = tr.at.firstByte.Sub(tr.at.wrote)
+			}
+		},
+	}
+}
+
+// bodyDone records the end of the response body transfer, once.
+func (tr *tracer) bodyDone() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.t.Transfer == 0 && !tr.at.firstByte.IsZero() {
+		tr.t.Transfer = time.Since(tr.at.firstByte)
+	}
+}
+
+// timings returns a snapshot of the collected timings.
+func (tr *tracer) timings() Timings {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.t
+}
+
+// traceBody wraps resp.Body so the transfer ends when the traced request's
+// body is read to the end or closed.
+func traceBody(req *http.Request, resp *http.Response) {
+	tr, ok := req.Context().Value(tracerKey{}).(*tracer)
+	if !ok {
+		return
+	}
+	resp.Body = &tracedBody{ReadCloser: resp.Body, tr: tr}
+}
+
+type tracedBody struct {
+	io.ReadCloser
+	tr *tracer
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.tr.bodyDone()
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	b.tr.bodyDone()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimedRequest_Timings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	c := New(WithTransport(server.Client().Transport))
+
+	result, err := c.TimedGet(context.Background(), server.URL)
+	require.NoError(t, err)
+	_, _ = io.ReadAll(result.Response.Body)
+	result.Response.Body.Close()
+
+	timings := result.Timings()
+	assert.Zero(t, timings.DNS, "the test server URL is an IP address")
+	assert.Positive(t, timings.Connect)
+	assert.Positive(t, timings.TLS)
+	assert.GreaterOrEqual(t, timings.Server, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, timings.TTFB, timings.Server+timings.TLS)
+	assert.GreaterOrEqual(t, timings.Transfer, 20*time.Millisecond)
+	assert.False(t, timings.Reused)
+
+	result, err = c.TimedGet(context.Background(), server.URL)
+	require.NoError(t, err)
+	result.Response.Body.Close()
+
+	timings = result.Timings()
+	assert.True(t, timings.Reused)
+	assert.Zero(t, timings.Connect)
+	assert.Zero(t, timings.TLS)
+}
+
+func TestTimedRequest_TimingsWithRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	c := New(WithRecorder(recorderFunc(func(*Exchange) {})))
+
+	result, err := c.TimedGet(context.Background(), server.URL)
+	require.NoError(t, err)
+	result.Response.Body.Close()
+
+	// The recorder reads the body off the network, which ends the transfer
+	assert.GreaterOrEqual(t, result.Timings().Transfer, 20*time.Millisecond)
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TransportConfig describes how to reach servers: through a proxy, with a
+// private CA or client certificate, or at overridden addresses.
+type TransportConfig struct {
+	Proxy    string   // Proxy URL (http, https or socks5); empty uses HTTP_PROXY/HTTPS_PROXY
+	CACert   string   // PEM file of CA certificates trusted in addition to the system pool
+	Cert     string   // PEM client certificate for mutual TLS
+	Key      string   // PEM private key for Cert; empty if Cert also holds the key
+	Insecure bool     // Skip server certificate verification
+	Resolve  []string // "host:port:addr" entries that connect host:port to addr instead
+
+	// IdleConnsPerHost is how many keep-alive connections to keep per host;
+	// 0 keeps the default of 2. Raise it when sending many requests in
+	// parallel to the same hosts.
+	IdleConnsPerHost int
+}
+
+// IsZero reports whether cfg changes nothing from the default transport.
+func (cfg TransportConfig) IsZero() bool {
+	return cfg.Proxy == "" && cfg.CACert == "" && cfg.Cert == "" && cfg.Key == "" &&
+		!cfg.Insecure && len(cfg.Resolve) == 0 && cfg.IdleConnsPerHost == 0
+}
+
+// NewTransport returns a copy of http.DefaultTransport configured by cfg.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.IdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.IdleConnsPerHost
+		transport.MaxIdleConns = max(transport.MaxIdleConns, cfg.IdleConnsPerHost)
+	}
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.Proxy)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if len(cfg.Resolve) > 0 {
+		overrides, err := parseResolve(cfg.Resolve)
+		if err != nil {
+			return nil, err
+		}
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if override, ok := overrides[strings.ToLower(addr)]; ok {
+				addr = override
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(cfg TransportConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.Insecure}
+
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.Key != "" && cfg.Cert == "" {
+		return nil, fmt.Errorf("a client key requires a client certificate")
+	}
+	if cfg.Cert != "" {
+		keyFile := cfg.Key
+		if keyFile == "" {
+			keyFile = cfg.Cert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.Cert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// parseResolve maps each "host:port" to the "addr:port" it should dial.
+// IPv6 addresses may be bracketed, e.g. "api.example.com:443:[::1]".
+func parseResolve(entries []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		host, rest, ok := strings.Cut(entry, ":")
+		port, addr, ok2 := strings.Cut(rest, ":")
+		addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+		if !ok || !ok2 || host == "" || port == "" || net.ParseIP(addr) == nil {
+			return nil, fmt.Errorf("invalid resolve entry %q (expected host:port:address)", entry)
+		}
+		overrides[strings.ToLower(net.JoinHostPort(host, port))] = net.JoinHostPort(addr, port)
+	}
+	return overrides, nil
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes blocks of the given type to a temp file and returns its path.
+func writePEM(t *testing.T, blockType string, blocks ...[]byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file.pem")
+	var data []byte
+	for _, b := range blocks {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b})...)
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func getWith(t *testing.T, cfg TransportConfig, url string) (*http.Response, error) {
+	t.Helper()
+	transport, err := NewTransport(cfg)
+	require.NoError(t, err)
+	return New(WithTransport(transport)).Get(context.Background(), url)
+}
+
+func TestNewTransport_CACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := getWith(t, TransportConfig{}, server.URL)
+	assert.Error(t, err, "the test CA is not trusted by default")
+
+	caFile := writePEM(t, "CERTIFICATE", server.Certificate().Raw)
+	resp, err := getWith(t, TransportConfig{CACert: caFile}, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestNewTransport_Insecure(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := getWith(t, TransportConfig{Insecure: true}, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestNewTransport_ClientCert(t *testing.T) {
+	var peerCerts int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peerCerts = len(r.TLS.PeerCertificates)
+	}))
+	server.StartTLS()
+	defer server.Close()
+
+	// Reuse the test server's own key pair as the client certificate
+	serverCert := server.TLS.Certificates[0]
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	require.NoError(t, err)
+	certFile := writePEM(t, "CERTIFICATE", serverCert.Certificate...)
+	keyFile := writePEM(t, "PRIVATE KEY", keyDER)
+
+	server.TLS.ClientAuth = tls.RequireAnyClientCert
+	resp, err := getWith(t, TransportConfig{Insecure: true, Cert: certFile, Key: keyFile}, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, peerCerts)
+}
+
+func TestNewTransport_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer proxy.Close()
+
+	resp, err := getWith(t, TransportConfig{Proxy: proxy.URL}, "http://api.example.test/paid")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+	assert.Equal(t, "http://api.example.test/paid", proxied)
+}
+
+func TestNewTransport_Resolve(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	cfg := TransportConfig{Resolve: []string{"api.example.test:" + port + ":127.0.0.1"}}
+	resp, err := getWith(t, cfg, "http://API.example.test:"+port+"/")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "API.example.test:"+port, host)
+}
+
+func TestNewTransport_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  TransportConfig
+		want string
+	}{
+		{"proxy scheme", TransportConfig{Proxy: "ftp://proxy:21"}, "unsupported proxy scheme"},
+		{"proxy URL", TransportConfig{Proxy: "proxy"}, "invalid proxy URL"},
+		{"missing CA file", TransportConfig{CACert: "missing.pem"}, "failed to read CA certificate"},
+		{"key without cert", TransportConfig{Key: "key.pem"}, "requires a client certificate"},
+		{"resolve fields", TransportConfig{Resolve: []string{"api.example.com:443"}}, "invalid resolve entry"},
+		{"resolve address", TransportConfig{Resolve: []string{"api.example.com:443:localhost"}}, "invalid resolve entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTransport(tt.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err := NewTransport(TransportConfig{CACert: notPEM})
+	assert.ErrorContains(t, err, "no PEM certificates")
+}
+
+func TestParseResolve_IPv6(t *testing.T) {
+	overrides, err := parseResolve([]string{"api.example.com:443:[::1]"})
+	require.NoError(t, err)
+	assert.Equal(t, "[::1]:443", overrides["api.example.com:443"])
+}
@@ -7,12 +7,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
 	"github.com/port402/x402-cli/internal/output"
 )
 
 var (
 	agentCardURL string
 	agentTimeout int
+	agentRecord  string
+	agentReplay  string
+	agentNetwork client.TransportConfig
 )
 
 var agentCmd = &cobra.Command{
@@ -28,7 +33,9 @@ Tries these well-known paths in order:
 Examples:
   x402 agent https://api.example.com
   x402 agent https://api.example.com --json
-  x402 agent https://api.example.com --card-url /custom/agent.json`,
+  x402 agent https://api.example.com --card-url /custom/agent.json
+  x402 agent https://api.example.com --replay testdata/cassette
+  x402 agent https://api.example.com --cacert corp-ca.pem`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAgent,
 }
@@ -36,6 +43,8 @@ Examples:
 func init() {
 	agentCmd.Flags().StringVar(&agentCardURL, "card-url", "", "Custom agent card path (overrides discovery)")
 	agentCmd.Flags().IntVar(&agentTimeout, "timeout", 5, "Request timeout in seconds")
+	addCassetteFlags(agentCmd, &agentRecord, &agentReplay)
+	addNetworkFlags(agentCmd, &agentNetwork)
 	rootCmd.AddCommand(agentCmd)
 }
 
@@ -43,20 +52,31 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	url := args[0]
 	timeout := time.Duration(agentTimeout) * time.Second
 
-	result := a2a.Discover(cmd.Context(), url, agentCardURL, timeout)
+	network, err := networkTransport(agentNetwork)
+	if err != nil {
+		return err
+	}
+	transport, err := cassetteTransport(agentRecord, agentReplay, network)
+	if err != nil {
+		return err
+	}
+
+	result := a2a.Discover(cmd.Context(), url, agentCardURL, timeout, a2a.WithTransport(transport))
 
 	if GetJSONOutput() {
-		return output.PrintJSON(result)
+		if err := output.PrintJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printAgentResult(result)
 	}
 
-	printAgentResult(result)
-
 	if result.ExitCode != 0 {
 		cmd.SilenceUsage = true
 		if result.Error != "" {
-			return fmt.Errorf("agent card discovery failed: %s", result.Error)
+			return exitcode.Errorf(exitcode.Code(result.ExitCode), "agent card discovery failed: %s", result.Error)
 		}
-		return fmt.Errorf("agent card discovery failed")
+		return exitcode.Errorf(exitcode.Code(result.ExitCode), "agent card discovery failed")
 	}
 
 	return nil
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// checkpoint records finished checks of a batch-health run in a file, one
+// output.StreamRecord per line, so an interrupted run can resume where it
+// left off.
+type checkpoint struct {
+	path string
+	file *os.File
+	done map[int]*output.HealthResult
+}
+
+// openCheckpoint loads the records in path, if it exists, and opens the file
+// to append new records. A truncated last line, left by a crash mid-write,
+// is dropped.
+func openCheckpoint(path string) (*checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	c := &checkpoint{path: path, file: file, done: make(map[int]*output.HealthResult)}
+
+	reader := bufio.NewReader(file)
+	var valid int64 // Bytes up to the end of the last complete record
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			file.Close()
+			return nil, fmt.Errorf("failed to read checkpoint: %w", readErr)
+		}
+		if readErr == io.EOF {
+			break // Empty or partial last line
+		}
+
+		if len(bytes.TrimSpace(data)) > 0 {
+			var rec output.StreamRecord
+			if err := json.Unmarshal(data, &rec); err != nil || rec.HealthResult == nil {
+				file.Close()
+				return nil, fmt.Errorf("checkpoint %s line %d: not a batch-health record", path, line)
+			}
+			c.done[rec.Index] = rec.HealthResult
+		}
+		valid += int64(len(data))
+	}
+
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair checkpoint: %w", err)
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	return c, nil
+}
+
+// lookup returns the recorded result of entry idx, or nil if it has not been
+// checked, and forgets it. It fails if the recorded check was for another
+// endpoint, i.e. the input has changed.
+func (c *checkpoint) lookup(idx int, entry BatchEntry) (*output.HealthResult, error) {
+	done, ok := c.done[idx]
+	if !ok {
+		return nil, nil
+	}
+	url := entry.URL
+	if normalized, err := normalizeURL(url); err == nil {
+		url = normalized
+	}
+	if done.URL != url || (done.Method != "" && done.Method != entry.Method) {
+		return nil, fmt.Errorf("checkpoint %s does not match the input at entry %d (%s %s); remove it to start over",
+			c.path, idx+1, entry.Method, entry.URL)
+	}
+	delete(c.done, idx)
+	return done, nil
+}
+
+// record appends a finished check. Checks aborted by cancellation are not
+// recorded, so a resumed run repeats them.
+func (c *checkpoint) record(idx int, result *output.HealthResult) error {
+	if result.ExitCode == int(exitcode.Cancelled) {
+		return nil
+	}
+	data, err := json.Marshal(output.StreamRecord{Index: idx, HealthResult: result})
+	if err != nil {
+		return err
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close closes the checkpoint file.
+func (c *checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// remove deletes the checkpoint after a complete run.
+func (c *checkpoint) remove() error {
+	c.file.Close()
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
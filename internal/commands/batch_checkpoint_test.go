@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	entries := []BatchEntry{
+		{URL: server.URL + "/a", Method: "GET"},
+		{URL: server.URL + "/b", Method: "GET"},
+		{URL: server.URL + "/c", Method: "POST"},
+	}
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+
+	// First run is interrupted after two entries
+	cp, err := openCheckpoint(path)
+	require.NoError(t, err)
+	for idx, r := range runBatchChecks(context.Background(), entries[:2], batchConfig{parallel: 2}, healthOptions{}) {
+		require.NoError(t, cp.record(idx, r))
+	}
+	require.NoError(t, cp.record(2, &output.HealthResult{URL: entries[2].URL, Error: "cancelled", ExitCode: int(exitcode.Cancelled)}))
+	require.NoError(t, cp.Close())
+	calls.Store(0)
+
+	// The resumed run only checks the remaining entry
+	cp, err = openCheckpoint(path)
+	require.NoError(t, err)
+	defer cp.Close()
+
+	var resumed, reported []int
+	skip := func(idx int, entry BatchEntry) (bool, error) {
+		done, err := cp.lookup(idx, entry)
+		if done != nil {
+			resumed = append(resumed, idx)
+		}
+		return done != nil, err
+	}
+	read, err := streamBatchChecks(context.Background(), &sliceReader{entries: entries}, batchConfig{skip: skip}, healthOptions{},
+		func(idx int, r *output.HealthResult) {
+			reported = append(reported, idx)
+		})
+	require.NoError(t, err)
+	assert.Equal(t, 3, read)
+	assert.Equal(t, []int{0, 1}, resumed)
+	assert.Equal(t, []int{2}, reported)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// Cancellation is recognized by its exit code, not by the error text, which
+// may come from the server.
+func TestCheckpoint_RecordsByExitCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	entry := BatchEntry{URL: "https://example.com/a", Method: "GET"}
+
+	cp, err := openCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, cp.record(0, &output.HealthResult{URL: entry.URL, Error: "cancelled", ExitCode: int(exitcode.Network)}))
+	require.NoError(t, cp.record(1, &output.HealthResult{URL: entry.URL, Error: "context canceled", ExitCode: int(exitcode.Cancelled)}))
+	require.NoError(t, cp.Close())
+
+	cp, err = openCheckpoint(path)
+	require.NoError(t, err)
+	defer cp.Close()
+
+	done, err := cp.lookup(0, entry)
+	require.NoError(t, err)
+	require.NotNil(t, done)
+	assert.Equal(t, int(exitcode.Network), done.ExitCode)
+
+	done, err = cp.lookup(1, entry)
+	require.NoError(t, err)
+	assert.Nil(t, done)
+}
+
+func TestCheckpoint_InputChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	cp, err := openCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, cp.record(0, &output.HealthResult{URL: "https://a.example", Method: "GET"}))
+	require.NoError(t, cp.Close())
+
+	cp, err = openCheckpoint(path)
+	require.NoError(t, err)
+	defer cp.Close()
+
+	_, err = cp.lookup(0, BatchEntry{URL: "https://b.example", Method: "GET"})
+	assert.ErrorContains(t, err, "does not match the input at entry 1")
+
+	_, err = cp.lookup(0, BatchEntry{URL: "https://a.example", Method: "POST"})
+	assert.Error(t, err)
+
+	done, err := cp.lookup(0, BatchEntry{URL: "a.example", Method: "GET"})
+	require.NoError(t, err)
+	require.NotNil(t, done)
+	assert.Equal(t, "https://a.example", done.URL)
+
+	done, err = cp.lookup(1, BatchEntry{URL: "https://b.example", Method: "GET"})
+	require.NoError(t, err)
+	assert.Nil(t, done)
+}
+
+func TestCheckpoint_TruncatedLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	complete := `{"index":0,"url":"https://a.example","method":"GET","exitCode":0}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(complete+`{"index":1,"url":"https://b.ex`), 0o644))
+
+	cp, err := openCheckpoint(path)
+	require.NoError(t, err)
+	assert.Len(t, cp.done, 1)
+
+	require.NoError(t, cp.record(1, &output.HealthResult{URL: "https://b.example", Method: "GET"}))
+	require.NoError(t, cp.Close())
+
+	cp, err = openCheckpoint(path)
+	require.NoError(t, err)
+	defer cp.Close()
+	assert.Len(t, cp.done, 2)
+}
+
+func TestCheckpoint_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	require.NoError(t, os.WriteFile(path, []byte("not json\n{\"index\":1,\"url\":\"https://b.example\"}\n"), 0o644))
+
+	_, err := openCheckpoint(path)
+	assert.ErrorContains(t, err, "line 1: not a batch-health record")
+}
+
+func TestCheckpoint_Remove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+	cp, err := openCheckpoint(path)
+	require.NoError(t, err)
+
+	require.NoError(t, cp.remove())
+	assert.NoFileExists(t, path)
+}
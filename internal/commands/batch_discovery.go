@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/port402/x402-cli/internal/baseline"
+	"github.com/port402/x402-cli/internal/bazaar"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// loadDiscoveryEntries reads a facilitator discovery list from a URL or a
+// local JSON file and converts its HTTP resources to batch entries that carry
+// the advertised payment options for cross-checking.
+func loadDiscoveryEntries(ctx context.Context, source string, timeout time.Duration, transport http.RoundTripper) ([]BatchEntry, error) {
+	var list *bazaar.List
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: timeout, Transport: transport}
+		fetched, err := bazaar.Fetch(ctx, client, source)
+		if err != nil {
+			return nil, err
+		}
+		list = fetched
+	} else {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		parsed, err := bazaar.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		list = parsed
+	}
+
+	var entries []BatchEntry
+	for _, item := range list.Items {
+		if !item.IsHTTP() || item.Resource == "" {
+			continue
+		}
+
+		listed := make([]baseline.Option, len(item.Accepts))
+		for i, a := range item.Accepts {
+			listed[i] = baseline.Option{
+				Scheme:  a.Scheme,
+				Network: a.Network,
+				Asset:   a.Asset,
+				Amount:  a.GetAmount(),
+				PayTo:   a.PayTo,
+			}
+		}
+
+		entries = append(entries, BatchEntry{URL: item.Resource, Method: item.Method(), Listed: listed})
+	}
+
+	return entries, nil
+}
+
+// checkListing compares the live payment options with the ones a discovery
+// list advertises for the endpoint.
+func checkListing(listed []baseline.Option, result *output.HealthResult) (output.Check, []baseline.Drift) {
+	const name = "Matches listing"
+
+	drift := baseline.Compare(listed, baselineOptions(result.PaymentOptions))
+	if len(drift) > 0 {
+		return output.Check{
+			Name:    name,
+			Status:  output.StatusFail,
+			Message: fmt.Sprintf("%d difference(s) from the listing, first: %s", len(drift), drift[0]),
+		}, drift
+	}
+
+	return output.Check{
+		Name:    name,
+		Status:  output.StatusPass,
+		Message: fmt.Sprintf("%d option(s) match the listing", len(listed)),
+	}, nil
+}
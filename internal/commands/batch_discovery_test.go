@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestLoadDiscoveryEntries(t *testing.T) {
+	entries, err := loadDiscoveryEntries(context.Background(), "../bazaar/testdata/resources.json", 5*time.Second, nil)
+	require.NoError(t, err)
+
+	// The mcp resource is skipped
+	require.Len(t, entries, 2)
+	assert.Equal(t, "https://api.example.com/weather", entries[0].URL)
+	assert.Equal(t, "POST", entries[0].Method)
+	require.Len(t, entries[0].Listed, 1)
+	assert.Equal(t, "10000", entries[0].Listed[0].Amount)
+	assert.Equal(t, "base-sepolia", entries[0].Listed[0].Network)
+	assert.Equal(t, "GET", entries[1].Method)
+}
+
+func TestCheckBatchEntry_Listing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paymentReq := &x402.PaymentRequired{
+			X402Version: 2,
+			Accepts: []x402.PaymentRequirement{{
+				Scheme:  "exact",
+				Network: "eip155:84532",
+				Amount:  "10000",
+				Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+				PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+			}},
+		}
+		jsonBytes, _ := json.Marshal(paymentReq)
+		w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(jsonBytes))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	listing := `{
+  "x402Version": 2,
+  "items": [
+    {"resource": "URL/match", "type": "http", "accepts": [{"scheme": "exact", "network": "eip155:84532", "amount": "10000",
+      "asset": "0x036CbD53842c5426634e7929541eC2318f3dCF7e", "payTo": "0x64c2310bd1151266aa2ad2410447e133b7f84e29"}]},
+    {"resource": "URL/cheaper", "type": "http", "accepts": [{"scheme": "exact", "network": "eip155:84532", "amount": "5000",
+      "asset": "0x036CbD53842c5426634e7929541eC2318f3dCF7e", "payTo": "0x64c2310BD1151266AA2Ad2410447E133b7F84e29"}]}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "resources.json")
+	require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(listing, "URL", server.URL)), 0o644))
+
+	entries, err := loadDiscoveryEntries(context.Background(), path, 5*time.Second, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 5 * time.Second, parallel: 1}, healthOptions{})
+
+	match := results[0]
+	assert.Equal(t, 0, match.ExitCode)
+	assert.Empty(t, match.ListingDrift)
+
+	cheaper := results[1]
+	assert.Equal(t, int(exitcode.ExpectationFailed), cheaper.ExitCode)
+	require.Len(t, cheaper.ListingDrift, 1)
+	assert.Equal(t, "amount", cheaper.ListingDrift[0].Field)
+	assert.Equal(t, "5000", cheaper.ListingDrift[0].Pinned)
+	assert.Equal(t, "10000", cheaper.ListingDrift[0].Current)
+
+	var check *output.Check
+	for i := range cheaper.Checks {
+		if cheaper.Checks[i].Name == "Matches listing" {
+			check = &cheaper.Checks[i]
+		}
+	}
+	require.NotNil(t, check)
+	assert.Equal(t, output.StatusFail, check.Status)
+	assert.Contains(t, check.Message, "1 difference(s) from the listing")
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/tokens"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// Expectations are per-entry assertions evaluated after the health check.
+type Expectations struct {
+	Protocol  string   `json:"protocol,omitempty" yaml:"protocol,omitempty"`   // "v1" or "v2"
+	Networks  []string `json:"networks,omitempty" yaml:"networks,omitempty"`   // Each must be offered
+	MaxPrice  string   `json:"maxPrice,omitempty" yaml:"maxPrice,omitempty"`   // Token units, e.g. "0.05" or "0.05 USDC"
+	Asset     string   `json:"asset,omitempty" yaml:"asset,omitempty"`         // Symbol or address every option must use
+	AgentCard *bool    `json:"agentCard,omitempty" yaml:"agentCard,omitempty"` // Whether an A2A agent card must (not) exist
+}
+
+// validate checks the expectations that can be checked without a response.
+func (e *Expectations) validate() error {
+	if e.Protocol != "" {
+		if _, err := x402.ParseProtocolVersion(e.Protocol); err != nil {
+			return fmt.Errorf("expect.protocol: %w", err)
+		}
+	}
+	if e.MaxPrice != "" {
+		amount, _ := splitPrice(e.MaxPrice)
+		if _, err := tokens.ParseHumanAmount(amount, 18); err != nil {
+			return fmt.Errorf("expect.maxPrice: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkBatchEntry runs a health check with the entry's own request settings,
+// then evaluates the entry's expectations as extra checks.
+func checkBatchEntry(ctx context.Context, entry BatchEntry, timeout time.Duration, opts healthOptions) *output.HealthResult {
+	if entry.Timeout > 0 {
+		timeout = time.Duration(entry.Timeout)
+	}
+	opts.headers = entry.Headers
+	if entry.Body != "" {
+		opts.body = []byte(entry.Body)
+	}
+
+	result := checkHealthForBatch(ctx, entry.URL, entry.Method, timeout, opts)
+
+	expect := Expectations{}
+	if entry.Expect != nil {
+		expect = *entry.Expect
+	}
+	if expect.Protocol == "" {
+		expect.Protocol = entry.Protocol
+	}
+
+	if expect.AgentCard != nil && result.Status != 0 {
+		result.AgentCard = a2a.Discover(ctx, result.URL, "", timeout, a2a.WithTransport(opts.transport))
+	}
+
+	var checks []output.Check
+	if expect.Protocol != "" {
+		checks = append(checks, checkExpectedProtocol(expect.Protocol, result))
+	}
+	if entry.Price != "" {
+		checks = append(checks, checkExpectedPrice(entry.Price, result))
+	}
+	if len(expect.Networks) > 0 {
+		checks = append(checks, checkExpectedNetworks(expect.Networks, result))
+	}
+	if expect.MaxPrice != "" {
+		checks = append(checks, checkMaxPrice(expect.MaxPrice, result))
+	}
+	if expect.Asset != "" {
+		checks = append(checks, checkExpectedAsset(expect.Asset, result))
+	}
+	if expect.AgentCard != nil {
+		checks = append(checks, checkExpectedAgentCard(*expect.AgentCard, result))
+	}
+	if entry.Listed != nil {
+		check, drift := checkListing(entry.Listed, result)
+		checks = append(checks, check)
+		result.ListingDrift = drift
+	}
+
+	// A failed expectation does not replace the health check's own code
+	for _, check := range checks {
+		result.Checks = append(result.Checks, check)
+		if check.Status == output.StatusFail && result.ExitCode == 0 {
+			result.ExitCode = int(exitcode.ExpectationFailed)
+		}
+	}
+
+	return result
+}
+
+// checkExpectedProtocol compares the detected protocol with the entry's.
+func checkExpectedProtocol(expected string, result *output.HealthResult) output.Check {
+	const name = "Expected protocol"
+
+	version, _ := x402.ParseProtocolVersion(expected) // Validated when parsing the file
+	protocol, _ := x402.LookupProtocol(version)
+	if result.Protocol == protocol.String() {
+		return output.Check{Name: name, Status: output.StatusPass, Message: fmt.Sprintf("Uses %s", protocol)}
+	}
+
+	got := result.Protocol
+	if got == "" || got == "none" {
+		got = "no x402 protocol"
+	}
+	return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Expected %s, got %s", protocol, got)}
+}
+
+// checkExpectedPrice passes if any payment option with a known token charges
+// exactly the expected amount, e.g. "0.01" or "0.01 USDC".
+func checkExpectedPrice(expected string, result *output.HealthResult) output.Check {
+	const name = "Expected price"
+
+	amount, symbol := splitPrice(expected)
+
+	if len(result.PaymentOptions) == 0 {
+		return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Expected %s, but no payment options found", expected)}
+	}
+
+	for _, opt := range result.PaymentOptions {
+		info := tokens.GetTokenInfo(opt.Network, opt.Asset)
+		if info == nil || (symbol != "" && !strings.EqualFold(symbol, info.Symbol)) {
+			continue
+		}
+		raw, err := tokens.ParseHumanAmount(amount, info.Decimals)
+		if err != nil {
+			return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Invalid expected price %q: %v", expected, err)}
+		}
+		if tokens.CompareAmounts(opt.Amount, raw) == 0 {
+			return output.Check{
+				Name:    name,
+				Status:  output.StatusPass,
+				Message: fmt.Sprintf("%s on %s", opt.AmountHuman, tokens.GetNetworkName(opt.Network)),
+			}
+		}
+	}
+
+	return output.Check{
+		Name:    name,
+		Status:  output.StatusFail,
+		Message: fmt.Sprintf("Expected %s, got %s", expected, result.PaymentOptions[0].AmountHuman),
+	}
+}
+
+// checkExpectedNetworks passes if every expected network is offered, in
+// either CAIP-2 or v1 form.
+func checkExpectedNetworks(expected []string, result *output.HealthResult) output.Check {
+	const name = "Expected networks"
+
+	var missing []string
+	for _, network := range expected {
+		found := false
+		for _, opt := range result.PaymentOptions {
+			found = found || tokens.SameNetwork(opt.Network, network)
+		}
+		if !found {
+			missing = append(missing, network)
+		}
+	}
+
+	if len(missing) > 0 {
+		return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Not offered: %s", strings.Join(missing, ", "))}
+	}
+	return output.Check{Name: name, Status: output.StatusPass, Message: fmt.Sprintf("All %d network(s) offered", len(expected))}
+}
+
+// checkMaxPrice passes if no option charges more than the maximum. Options
+// in unknown tokens, or in a different token than the one named, cannot be
+// compared and fail the check.
+func checkMaxPrice(maxPrice string, result *output.HealthResult) output.Check {
+	const name = "Max price"
+
+	amount, symbol := splitPrice(maxPrice)
+
+	if len(result.PaymentOptions) == 0 {
+		return output.Check{Name: name, Status: output.StatusFail, Message: "No payment options found"}
+	}
+
+	for _, opt := range result.PaymentOptions {
+		network := tokens.GetNetworkName(opt.Network)
+		info := tokens.GetTokenInfo(opt.Network, opt.Asset)
+		if info == nil {
+			return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Cannot compare %s on %s with %s", opt.AmountHuman, network, maxPrice)}
+		}
+		if symbol != "" && !strings.EqualFold(symbol, info.Symbol) {
+			return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Option on %s charges in %s, not %s", network, info.Symbol, symbol)}
+		}
+
+		raw, err := tokens.ParseHumanAmount(amount, info.Decimals)
+		if err != nil {
+			return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Invalid max price %q: %v", maxPrice, err)}
+		}
+		if tokens.CompareAmounts(opt.Amount, raw) > 0 {
+			return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("%s on %s exceeds %s", opt.AmountHuman, network, maxPrice)}
+		}
+	}
+
+	return output.Check{Name: name, Status: output.StatusPass, Message: fmt.Sprintf("All options at most %s", maxPrice)}
+}
+
+// checkExpectedAsset passes if every option pays in the expected asset,
+// given as a token symbol or an asset address.
+func checkExpectedAsset(expected string, result *output.HealthResult) output.Check {
+	const name = "Expected asset"
+
+	if len(result.PaymentOptions) == 0 {
+		return output.Check{Name: name, Status: output.StatusFail, Message: "No payment options found"}
+	}
+
+	for _, opt := range result.PaymentOptions {
+		if !strings.EqualFold(opt.AssetSymbol, expected) && !strings.EqualFold(opt.Asset, expected) {
+			got := opt.AssetSymbol
+			if got == "" || got == "UNKNOWN" {
+				got = opt.Asset
+			}
+			return output.Check{
+				Name:    name,
+				Status:  output.StatusFail,
+				Message: fmt.Sprintf("Option on %s pays in %s, not %s", tokens.GetNetworkName(opt.Network), got, expected),
+			}
+		}
+	}
+
+	return output.Check{Name: name, Status: output.StatusPass, Message: fmt.Sprintf("All options pay in %s", expected)}
+}
+
+// checkExpectedAgentCard compares agent card discovery with the expectation.
+func checkExpectedAgentCard(expected bool, result *output.HealthResult) output.Check {
+	const name = "Agent card"
+
+	found := result.AgentCard != nil && result.AgentCard.Found
+	if found == expected {
+		msg := "No agent card, as expected"
+		if found {
+			msg = fmt.Sprintf("Found at %s", result.AgentCard.DiscoveryPath)
+		}
+		return output.Check{Name: name, Status: output.StatusPass, Message: msg}
+	}
+
+	if expected {
+		return output.Check{Name: name, Status: output.StatusFail, Message: "Expected an A2A agent card, none found"}
+	}
+	return output.Check{Name: name, Status: output.StatusFail, Message: fmt.Sprintf("Expected no agent card, found one at %s", result.AgentCard.DiscoveryPath)}
+}
+
+// splitPrice splits "0.05 USDC" into amount and optional symbol.
+func splitPrice(price string) (amount, symbol string) {
+	amount, symbol, _ = strings.Cut(strings.TrimSpace(price), " ")
+	return amount, strings.TrimSpace(symbol)
+}
@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestLoadBatchFile_Expect(t *testing.T) {
+	input := `
+- url: https://api.example.com/a
+  expect:
+    protocol: v2
+    networks: [eip155:8453]
+    maxPrice: "0.05"
+    asset: USDC
+    agentCard: true
+`
+	entries, err := loadBatchFile("endpoints.yaml", []byte(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	expect := entries[0].Expect
+	require.NotNil(t, expect)
+	assert.Equal(t, "v2", expect.Protocol)
+	assert.Equal(t, []string{"eip155:8453"}, expect.Networks)
+	assert.Equal(t, "0.05", expect.MaxPrice)
+	assert.Equal(t, "USDC", expect.Asset)
+	require.NotNil(t, expect.AgentCard)
+	assert.True(t, *expect.AgentCard)
+
+	entries, err = loadBatchFile("endpoints.json", []byte(`[{"url": "https://api.example.com/a", "expect": {"agentCard": false}}]`))
+	require.NoError(t, err)
+	require.NotNil(t, entries[0].Expect.AgentCard)
+	assert.False(t, *entries[0].Expect.AgentCard)
+}
+
+func TestLoadBatchFile_InvalidExpect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"protocol", `[{"url": "https://a.example.com", "expect": {"protocol": "v9"}}]`, "entry 1: expect.protocol"},
+		{"max price", `[{"url": "https://a.example.com", "expect": {"maxPrice": "cheap"}}]`, "entry 1: expect.maxPrice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadBatchFile("endpoints.json", []byte(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCheckBatchEntry_Expect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/agent.json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name": "Test Agent", "description": "test", "version": "1.0.0"}`))
+			return
+		}
+		if r.URL.Path != "/paid" {
+			http.NotFound(w, r)
+			return
+		}
+
+		paymentReq := &x402.PaymentRequired{
+			X402Version: 2,
+			Accepts: []x402.PaymentRequirement{{
+				Scheme:  "exact",
+				Network: "eip155:84532",
+				Amount:  "10000",
+				Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+				PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+			}},
+		}
+		jsonBytes, _ := json.Marshal(paymentReq)
+		w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(jsonBytes))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	yes, no := true, false
+
+	tests := []struct {
+		name    string
+		expect  Expectations
+		check   string
+		status  output.CheckStatus
+		message string
+		code    exitcode.Code
+	}{
+		{"network offered", Expectations{Networks: []string{"eip155:84532"}}, "Expected networks", output.StatusPass, "All 1 network(s) offered", exitcode.OK},
+		{"network missing", Expectations{Networks: []string{"eip155:84532", "eip155:8453"}}, "Expected networks", output.StatusFail, "Not offered: eip155:8453", exitcode.ExpectationFailed},
+		{"network by v1 name", Expectations{Networks: []string{"base-sepolia"}}, "Expected networks", output.StatusPass, "All 1 network(s) offered", exitcode.OK},
+		{"other network by v1 name", Expectations{Networks: []string{"base"}}, "Expected networks", output.StatusFail, "Not offered: base", exitcode.ExpectationFailed},
+		{"under max price", Expectations{MaxPrice: "0.05"}, "Max price", output.StatusPass, "All options at most 0.05", exitcode.OK},
+		{"at max price", Expectations{MaxPrice: "0.01 USDC"}, "Max price", output.StatusPass, "All options at most 0.01 USDC", exitcode.OK},
+		{"over max price", Expectations{MaxPrice: "0.005"}, "Max price", output.StatusFail, "0.01 USDC on Base Sepolia exceeds 0.005", exitcode.ExpectationFailed},
+		{"max price in other token", Expectations{MaxPrice: "1 EURC"}, "Max price", output.StatusFail, "Option on Base Sepolia charges in USDC, not EURC", exitcode.ExpectationFailed},
+		{"asset by symbol", Expectations{Asset: "usdc"}, "Expected asset", output.StatusPass, "All options pay in usdc", exitcode.OK},
+		{"asset by address", Expectations{Asset: "0x036CbD53842c5426634e7929541eC2318f3dCF7e"}, "Expected asset", output.StatusPass, "All options pay in 0x036CbD53842c5426634e7929541eC2318f3dCF7e", exitcode.OK},
+		{"wrong asset", Expectations{Asset: "DAI"}, "Expected asset", output.StatusFail, "Option on Base Sepolia pays in USDC, not DAI", exitcode.ExpectationFailed},
+		{"agent card found", Expectations{AgentCard: &yes}, "Agent card", output.StatusPass, "Found at /.well-known/agent.json", exitcode.OK},
+		{"agent card unexpected", Expectations{AgentCard: &no}, "Agent card", output.StatusFail, "Expected no agent card, found one at /.well-known/agent.json", exitcode.ExpectationFailed},
+		{"protocol", Expectations{Protocol: "v2"}, "Expected protocol", output.StatusPass, "Uses v2", exitcode.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect := tt.expect
+			entry := BatchEntry{URL: server.URL + "/paid", Method: "GET", Expect: &expect}
+			result := checkBatchEntry(context.Background(), entry, 5*time.Second, healthOptions{})
+
+			var check *output.Check
+			for i := range result.Checks {
+				if result.Checks[i].Name == tt.check {
+					check = &result.Checks[i]
+				}
+			}
+			require.NotNil(t, check, "missing %q check", tt.check)
+			assert.Equal(t, tt.status, check.Status)
+			assert.Equal(t, tt.message, check.Message)
+
+			assert.Equal(t, int(tt.code), result.ExitCode)
+		})
+	}
+}
+
+func TestCheckExpectedNetworks_MixedForms(t *testing.T) {
+	tests := []struct {
+		name     string
+		offered  string
+		expected string
+		status   output.CheckStatus
+	}{
+		{"v1 name against CAIP-2", "eip155:8453", "base", output.StatusPass},
+		{"CAIP-2 against v1 name", "base", "eip155:8453", output.StatusPass},
+		{"v1 aliases", "base_sepolia", "Base-Sepolia", output.StatusPass},
+		{"Solana name against CAIP-2", x402.SolanaDevnet, "solana-devnet", output.StatusPass},
+		{"testnet is not mainnet", "eip155:84532", "base", output.StatusFail},
+		{"unknown networks compare exactly", "eip155:999", "eip155:998", output.StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &output.HealthResult{PaymentOptions: []output.PaymentOptionDisplay{{Network: tt.offered}}}
+			assert.Equal(t, tt.status, checkExpectedNetworks([]string{tt.expected}, result).Status)
+		})
+	}
+}
@@ -1,220 +1,583 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
 	"github.com/port402/x402-cli/internal/output"
 )
 
-// BatchEntry represents an endpoint to check with optional method.
-type BatchEntry struct {
-	URL    string `json:"url"`
-	Method string `json:"method,omitempty"`
-}
-
 // Batch health command flags
 var (
-	batchParallel int
-	batchDelay    int
-	batchFailFast bool
-	batchTimeout  int
+	batchParallel   int
+	batchDelay      int
+	batchFailFast   bool
+	batchTimeout    int
+	batchRecord     string
+	batchReplay     string
+	batchNetwork    client.TransportConfig
+	batchFormat     string
+	batchTags       []string
+	batchRetries    int
+	batchPerHost    int
+	batchRate       float64
+	batchCheckpoint string
+
+	batchFromDiscovery string
 )
 
 var batchHealthCmd = &cobra.Command{
-	Use:   "batch-health <file>",
-	Short: "Check multiple endpoints from a JSON file",
+	Use:   "batch-health [file]",
+	Short: "Check multiple endpoints from a file",
 	Long: `Batch health check for multiple x402-enabled endpoints.
 
-The input file can be either:
-
-  1. Simple array of URLs (uses GET):
-     ["https://api1.example.com/endpoint", "https://api2.example.com/endpoint"]
-
-  2. Array of objects with URL and method:
-     [
-       {"url": "https://api.example.com/get-endpoint"},
-       {"url": "https://api.example.com/post-endpoint", "method": "POST"}
-     ]
+The input format is chosen from the file extension:
+
+  .json  Array of URLs, or of objects with url and optional settings:
+         ["https://api1.example.com/endpoint", "https://api2.example.com/endpoint"]
+         [{"url": "https://api.example.com/post-endpoint", "method": "POST"}]
+
+  .yaml  The same list in YAML, optionally under an "endpoints" key:
+         - url: https://api.example.com/search
+           method: POST
+           headers: {Content-Type: application/json}
+           body: '{"q": "test"}'
+           timeout: 10s
+           protocol: v2
+           price: 0.01 USDC
+           tags: [prod, search]
+           expect:
+             networks: [eip155:8453]
+             maxPrice: 0.05 USDC
+             asset: USDC
+             agentCard: true
+
+  .csv   A header row naming any of: url, method, headers, body, timeout,
+         protocol, price, tags. Headers ("Name: value") and tags are
+         separated by ';'.
+
+  .txt   One URL per line, optionally preceded by a method. Lines starting
+         with # are comments.
+
+  .ndjson, .jsonl
+         One URL string or entry object per line.
+
+Input is read as the checks run, so large files need not fit in memory
+(YAML excepted).
+
+Entries can set their own headers, body and timeout. "protocol" and "price"
+are checked against the 402 response and fail the entry if they differ.
+
+An "expect" object (JSON or YAML) adds further assertions, each reported as
+a check that fails the entry:
+  protocol   Protocol version the 402 must use ("v1" or "v2")
+  networks   Networks that must all be offered
+  maxPrice   Highest acceptable price of any option, e.g. "0.05" or "0.05 USDC"
+  asset      Symbol or address every option must pay in
+  agentCard  Whether an A2A agent card must (true) or must not (false) exist
+
+Use --tag to only check entries carrying at least one of the given tags.
+
+Use --from-discovery instead of a file to check every HTTP resource in a
+facilitator's discovery ("bazaar") list, given as a URL or a saved JSON
+file. Each resource's live 402 must match the payment options the listing
+advertises (network, asset, amount and payTo); differences fail the entry.
+
+Use --format junit|tap|sarif|markdown to report each endpoint as a test case
+with one assertion per check, e.g. for CI test reporting.
+
+Use --format ndjson to print each result as one JSON line as soon as its
+check finishes, tagged with the entry's 0-based "index" in the input.
+
+Use --checkpoint to record results in a file as they finish. If the run is
+interrupted, rerunning with the same file and input skips the entries
+already checked and reports their recorded results. The file is removed
+once every entry has been checked.
+
+Use --proxy, --cacert, --cert/--key, --insecure and --resolve to reach
+endpoints behind a proxy, with a private CA or mutual TLS, or at another
+address.
+
+All checks share one keep-alive connection pool. Use --parallel to run
+several checks at once, --per-host to cap how many hit the same host, and
+--rate to cap how many start per second. Entries for hosts at their cap
+wait while other hosts' entries go ahead. --fail-fast aborts in-flight
+checks on the first failure and reports only the checks that finished.
+
+Use --retries to retry connection errors and 408, 429 and 5xx responses
+with exponential backoff, honoring Retry-After.
 
 Examples:
   x402 batch-health urls.json
   x402 batch-health urls.json --parallel 5
+  x402 batch-health urls.json --parallel 20 --per-host 4 --rate 10
   x402 batch-health urls.json --json
-  x402 batch-health urls.json --fail-fast`,
-	Args: cobra.ExactArgs(1),
+  x402 batch-health urls.json --fail-fast
+  x402 batch-health urls.json --retries 2
+  x402 batch-health endpoints.yaml --tag prod
+  x402 batch-health urls.txt
+  x402 batch-health urls.json --format junit > x402.xml
+  x402 batch-health urls.ndjson --format ndjson --checkpoint run.ckpt
+  x402 batch-health --from-discovery https://facilitator.example.com/discovery/resources
+  x402 batch-health urls.json --record testdata/cassette
+  x402 batch-health urls.json --replay testdata/cassette
+  x402 batch-health urls.json --proxy http://proxy.corp:3128 --cacert corp-ca.pem`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runBatchHealth,
 }
 
 func init() {
 	batchHealthCmd.Flags().IntVar(&batchParallel, "parallel", 1, "Number of parallel checks")
 	batchHealthCmd.Flags().IntVar(&batchDelay, "delay", 0, "Delay between requests in milliseconds")
+	batchHealthCmd.Flags().IntVar(&batchPerHost, "per-host", 0, "Maximum parallel checks per host (0 for no limit)")
+	batchHealthCmd.Flags().Float64Var(&batchRate, "rate", 0, "Maximum checks started per second (0 for no limit)")
 	batchHealthCmd.Flags().BoolVar(&batchFailFast, "fail-fast", false, "Stop on first failure")
 	batchHealthCmd.Flags().IntVar(&batchTimeout, "timeout", 30, "Request timeout in seconds")
+	batchHealthCmd.Flags().IntVar(&batchRetries, "retries", 0, "Retry connection errors, 408, 429 and 5xx responses this many times")
+	batchHealthCmd.Flags().StringSliceVar(&batchTags, "tag", nil, "Only check entries with this tag (repeatable)")
+	batchHealthCmd.Flags().StringVar(&batchFormat, "format", "", "Output format: text, json, junit, tap, sarif, markdown, ndjson")
+	batchHealthCmd.Flags().StringVar(&batchCheckpoint, "checkpoint", "", "Record results in this file and resume from it after an interruption")
+	batchHealthCmd.Flags().StringVar(&batchFromDiscovery, "from-discovery", "", "Check the resources in a facilitator discovery list (URL or JSON file)")
+	addCassetteFlags(batchHealthCmd, &batchRecord, &batchReplay)
+	addNetworkFlags(batchHealthCmd, &batchNetwork)
 
 	rootCmd.AddCommand(batchHealthCmd)
 }
 
 func runBatchHealth(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
+	ctx := cmd.Context()
 	timeout := time.Duration(batchTimeout) * time.Second
 
-	// Read and parse input file
-	data, err := os.ReadFile(filePath)
+	if (len(args) == 0) == (batchFromDiscovery == "") {
+		return exitcode.Errorf(exitcode.Input, "provide either an input file or --from-discovery")
+	}
+
+	format, err := resolveFormat(batchFormat)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return err
 	}
 
-	entries, err := parseBatchInput(data)
+	// One keep-alive pool for every check
+	batchNetwork.IdleConnsPerHost = max(batchParallel, 2)
+	network, err := networkTransport(batchNetwork)
+	if err != nil {
+		return err
+	}
+	transport, err := cassetteTransport(batchRecord, batchReplay, network)
 	if err != nil {
 		return err
 	}
 
-	if len(entries) == 0 {
-		return fmt.Errorf("no URLs in file")
+	var source batchReader
+	if batchFromDiscovery != "" {
+		entries, err := loadDiscoveryEntries(ctx, batchFromDiscovery, timeout, transport)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return exitcode.Errorf(exitcode.Input, "no HTTP resources in discovery list")
+		}
+		source = &sliceReader{entries: entries}
+	} else {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return exitcode.Errorf(exitcode.Input, "failed to read file: %w", err)
+		}
+		defer file.Close()
+
+		source, err = openBatchReader(args[0], file)
+		if err != nil {
+			return exitcode.New(exitcode.Input, err)
+		}
+	}
+	tagged := &tagReader{next: source, tags: batchTags}
+
+	// Results by entry index; streamed as they finish for NDJSON
+	collected := &batchCollector{}
+	if format == output.ReportNDJSON {
+		collected.stream = json.NewEncoder(os.Stdout)
+	}
+	report := collected.add
+
+	cfg := batchConfig{
+		timeout:  timeout,
+		parallel: batchParallel,
+		perHost:  batchPerHost,
+		rate:     batchRate,
+		delay:    time.Duration(batchDelay) * time.Millisecond,
+		failFast: batchFailFast,
+	}
+
+	var cp *checkpoint
+	if batchCheckpoint != "" {
+		cp, err = openCheckpoint(batchCheckpoint)
+		if err != nil {
+			return exitcode.New(exitcode.Input, err)
+		}
+		defer cp.Close()
+		// Entries checked by an earlier run report their recorded result
+		cfg.skip = func(idx int, entry BatchEntry) (bool, error) {
+			done, err := cp.lookup(idx, entry)
+			if done != nil {
+				report(output.StreamRecord{Index: idx, HealthResult: done})
+			}
+			return done != nil, exitcode.New(exitcode.Input, err)
+		}
 	}
 
 	// Run checks
 	startTime := time.Now()
-	results := runBatchChecks(entries, timeout, batchParallel, batchDelay, batchFailFast)
+	read, runErr := streamBatchChecks(ctx, tagged, cfg, healthOptions{
+		transport: transport,
+		retry:     client.RetryPolicy{MaxRetries: batchRetries},
+	}, func(idx int, r *output.HealthResult) {
+		if cp != nil {
+			if err := cp.record(idx, r); err != nil {
+				output.PrintWarning(err.Error())
+			}
+		}
+		report(output.StreamRecord{Index: idx, HealthResult: r})
+	})
 	duration := time.Since(startTime)
 
-	// Count results
-	passed := 0
-	passedWarn := 0
-	failed := 0
-	for _, r := range results {
-		if r.ExitCode != 0 {
-			failed++
-		} else {
-			hasWarn := false
-			for _, c := range r.Checks {
-				if c.Status == output.StatusWarn {
-					hasWarn = true
-					break
-				}
-			}
-			if hasWarn {
-				passedWarn++
-			} else {
-				passed++
-			}
+	if read == 0 && runErr == nil {
+		switch {
+		case batchFromDiscovery != "":
+			return exitcode.Errorf(exitcode.Input, "no HTTP resources in discovery list")
+		case tagged.read == 0:
+			return exitcode.Errorf(exitcode.Input, "no URLs in file")
+		default:
+			return exitcode.Errorf(exitcode.Input, "no entries tagged %s", strings.Join(batchTags, " or "))
 		}
 	}
 
-	batchResult := &output.BatchHealthResult{
-		TotalURLs:  len(entries),
-		Passed:     passed,
-		PassedWarn: passedWarn,
-		Failed:     failed,
-		Results:    results,
-		Duration:   duration.Milliseconds(),
+	// Input that fails to parse partway, or a stale checkpoint
+	runErr = exitcode.New(exitcode.Input, runErr)
+	if runErr != nil && collected.count == 0 {
+		return runErr
 	}
 
-	output.PrintBatchHealthResult(batchResult, GetJSONOutput())
+	batchResult := collected.result(duration.Milliseconds())
+	batchResult.TotalURLs = read
+
+	switch format {
+	case output.ReportNDJSON:
+		// Already streamed
+	case output.ReportText, output.ReportJSON:
+		output.PrintBatchHealthResult(batchResult, format == output.ReportJSON)
+	default:
+		if err := output.WriteReport(os.Stdout, format, batchResult, output.WithToolVersion(Version)); err != nil {
+			return err
+		}
+	}
+
+	if cp != nil && runErr == nil && ctx.Err() == nil && collected.count == read {
+		// Every entry has a result; the next run starts over
+		if err := cp.remove(); err != nil {
+			output.PrintWarning(err.Error())
+		}
+	}
 
-	if failed > 0 {
-		return fmt.Errorf("%d endpoint(s) failed", failed)
+	if runErr != nil {
+		return runErr
+	}
+
+	if ctx.Err() != nil {
+		msg := fmt.Sprintf("cancelled after checking %d endpoint(s)", collected.count)
+		if cp != nil {
+			msg += fmt.Sprintf("; rerun with --checkpoint %s to resume", batchCheckpoint)
+		}
+		output.PrintWarning(msg)
+		return errCancelReported
+	}
+
+	if batchResult.Failed > 0 {
+		return exitcode.Errorf(collected.code, "%d endpoint(s) failed", batchResult.Failed)
 	}
 
 	return nil
 }
 
-// parseBatchInput parses JSON input supporting both simple URL arrays and object arrays.
-func parseBatchInput(data []byte) ([]BatchEntry, error) {
-	// Try parsing as array of objects first
-	var entries []BatchEntry
-	if err := json.Unmarshal(data, &entries); err == nil {
-		// Validate and normalize entries
-		for i := range entries {
-			if entries[i].URL == "" {
-				return nil, fmt.Errorf("entry %d: missing URL", i+1)
-			}
-			// Default to GET if method not specified
-			if entries[i].Method == "" {
-				entries[i].Method = "GET"
-			} else {
-				entries[i].Method = strings.ToUpper(entries[i].Method)
-			}
-		}
-		return entries, nil
+// batchCollector gathers batch results as they finish. When streaming, each
+// result is written out and only counted, so memory stays bounded however
+// large the input.
+type batchCollector struct {
+	stream  *json.Encoder
+	results []output.StreamRecord // Unless streaming
+	summary output.BatchHealthResult
+	count   int
+	code    exitcode.Code // Shared by every failed result so far
+}
+
+func (c *batchCollector) add(rec output.StreamRecord) {
+	c.count++
+	c.summary.Count(rec.HealthResult)
+	c.code = mergeExitCode(c.code, rec.ExitCode)
+	if c.stream != nil {
+		_ = c.stream.Encode(rec)
+		return
 	}
+	c.results = append(c.results, rec)
+}
 
-	// Try parsing as simple string array (backward compatible)
-	var urls []string
-	if err := json.Unmarshal(data, &urls); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: expected array of URLs or array of {url, method} objects")
+// result returns the batch result: the collected results in input order, or
+// just the counts when they were streamed.
+func (c *batchCollector) result(durationMs int64) *output.BatchHealthResult {
+	if c.stream != nil {
+		summary := c.summary
+		summary.Duration = durationMs
+		return &summary
+	}
+	sort.Slice(c.results, func(i, j int) bool { return c.results[i].Index < c.results[j].Index })
+	checked := make([]output.HealthResult, len(c.results))
+	for i, rec := range c.results {
+		checked[i] = *rec.HealthResult
 	}
+	return output.NewBatchHealthResult(checked, durationMs)
+}
 
-	// Convert to BatchEntry with default GET method
-	entries = make([]BatchEntry, len(urls))
-	for i, url := range urls {
-		entries[i] = BatchEntry{URL: url, Method: "GET"}
+// mergeExitCode folds the exit code of one more result into code: the code
+// shared by every failed result, or exitcode.General if they differ.
+func mergeExitCode(code exitcode.Code, exitCode int) exitcode.Code {
+	switch {
+	case exitCode == 0:
+		return code
+	case code == exitcode.OK:
+		return exitcode.Code(exitCode)
+	case code != exitcode.Code(exitCode):
+		return exitcode.General
 	}
-	return entries, nil
+	return code
+}
+
+// batchConfig controls how runBatchChecks schedules checks.
+type batchConfig struct {
+	timeout  time.Duration // Per-request timeout, unless an entry sets its own
+	parallel int           // Checks in flight at once
+	perHost  int           // Checks in flight per host; 0 for no limit
+	rate     float64       // Checks started per second; 0 for no limit
+	delay    time.Duration // Pause before starting each check after the first
+	failFast bool          // Abort in-flight checks and stop after the first failure
+
+	// skip, if set, reports whether an entry needs no check, e.g. because a
+	// checkpoint already holds its result. An error stops the run like a
+	// read error.
+	skip func(idx int, entry BatchEntry) (bool, error)
+}
+
+// batchWindow is how many unstarted entries streamBatchChecks reads ahead
+// to find work for hosts below their per-host limit.
+const batchWindow = 256
+
+// runBatchChecks checks entries with up to cfg.parallel checks in flight,
+// sharing one keep-alive connection pool. The result for entry i is at index
+// i, or nil if the entry was not checked. See streamBatchChecks.
+func runBatchChecks(ctx context.Context, entries []BatchEntry, cfg batchConfig, opts healthOptions) []*output.HealthResult {
+	results := make([]*output.HealthResult, len(entries))
+	_, _ = streamBatchChecks(ctx, &sliceReader{entries: entries}, cfg, opts, func(idx int, r *output.HealthResult) {
+		results[idx] = r
+	})
+	return results
 }
 
-func runBatchChecks(entries []BatchEntry, timeout time.Duration, parallel int, delayMs int, failFast bool) []output.HealthResult {
-	results := make([]output.HealthResult, len(entries))
+// streamBatchChecks checks the entries read from source with up to
+// cfg.parallel checks in flight, sharing one keep-alive connection pool.
+// report is called on the calling goroutine as each check finishes, with the
+// entry's position in source. Only a window of upcoming entries and the
+// in-flight checks are held in memory.
+//
+// When a host is at its cfg.perHost limit, later entries for other hosts go
+// first rather than waiting behind it. When ctx is cancelled no further
+// checks start and in-flight ones are aborted and reported as cancelled. With
+// cfg.failFast the first failure cancels the remaining checks the same way,
+// but those aborted by it are not reported. An error reading source stops
+// the run like a failure and is returned with the number of entries read.
+func streamBatchChecks(ctx context.Context, source batchReader, cfg batchConfig, opts healthOptions, report func(idx int, r *output.HealthResult)) (int, error) {
+	parallel := max(cfg.parallel, 1)
+
+	if opts.transport == nil {
+		transport, err := client.NewTransport(client.TransportConfig{IdleConnsPerHost: parallel})
+		if err == nil {
+			defer transport.CloseIdleConnections()
+			opts.transport = transport
+		}
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	var limiter *tokenBucket
+	if cfg.rate > 0 {
+		limiter = newTokenBucket(cfg.rate, 1)
+	}
+
+	type queued struct {
+		idx   int
+		entry BatchEntry
+		host  string
+	}
+	type finished struct {
+		idx    int
+		host   string
+		result *output.HealthResult
+	}
 
-	if parallel <= 1 {
-		// Sequential execution
-		for i, entry := range entries {
-			results[i] = *CheckHealthForBatchWithMethod(entry.URL, entry.Method, timeout)
-			if failFast && results[i].ExitCode != 0 {
-				break
+	var pending []queued
+	var readErr error
+	read, sourceDone := 0, false
+	fill := func() {
+		for !sourceDone && len(pending) < batchWindow {
+			entry, err := source.Next()
+			if err != nil {
+				sourceDone = true
+				if err != io.EOF {
+					readErr = err
+					stop()
+				}
+				return
 			}
-			if delayMs > 0 && i < len(entries)-1 {
-				time.Sleep(time.Duration(delayMs) * time.Millisecond)
+			idx := read
+			read++
+			if cfg.skip != nil {
+				skip, err := cfg.skip(idx, entry)
+				if err != nil {
+					sourceDone = true
+					readErr = err
+					stop()
+					return
+				}
+				if skip {
+					continue
+				}
 			}
+			pending = append(pending, queued{idx: idx, entry: entry, host: entryHost(entry)})
 		}
-		return results
 	}
 
-	// Parallel execution
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	semaphore := make(chan struct{}, parallel)
-	stopChan := make(chan struct{})
-	stopped := false
+	perHost := make(map[string]int)
+	inFlight, started := 0, 0
+	done := make(chan finished, parallel)
 
-	for i, entry := range entries {
-		// Check if we should stop (fail-fast)
-		select {
-		case <-stopChan:
-			return results[:i]
-		default:
+	finish := func(f finished) {
+		inFlight--
+		if perHost[f.host]--; perHost[f.host] == 0 {
+			delete(perHost, f.host)
 		}
+		if f.result.ExitCode == int(exitcode.Cancelled) && ctx.Err() == nil {
+			return // Aborted by fail-fast or a read error
+		}
+		report(f.idx, f.result)
+		if cfg.failFast && f.result.ExitCode != 0 {
+			stop()
+		}
+	}
 
-		wg.Add(1)
-		semaphore <- struct{}{} // Acquire semaphore
+	for runCtx.Err() == nil {
+		fill()
+		if len(pending) == 0 {
+			break
+		}
 
-		go func(idx int, e BatchEntry) {
-			defer wg.Done()
-			defer func() { <-semaphore }() // Release semaphore
+		next := -1
+		if inFlight < parallel {
+			for k, q := range pending {
+				if cfg.perHost <= 0 || perHost[q.host] < cfg.perHost {
+					next = k
+					break
+				}
+			}
+		}
+		if next < 0 {
+			select {
+			case f := <-done:
+				finish(f)
+			case <-runCtx.Done():
+			}
+			continue
+		}
+
+		if started > 0 && cfg.delay > 0 {
+			sleepContext(runCtx, cfg.delay)
+		}
+		if limiter != nil {
+			limiter.wait(runCtx)
+		}
+		if runCtx.Err() != nil {
+			break
+		}
 
-			result := CheckHealthForBatchWithMethod(e.URL, e.Method, timeout)
+		q := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		inFlight++
+		perHost[q.host]++
+		started++
 
-			mu.Lock()
-			results[idx] = *result
-			if failFast && result.ExitCode != 0 && !stopped {
-				stopped = true
-				close(stopChan)
-			}
-			mu.Unlock()
-		}(i, entry)
+		go func() {
+			done <- finished{idx: q.idx, host: q.host, result: checkBatchEntry(runCtx, q.entry, cfg.timeout, opts)}
+		}()
+	}
 
-		if delayMs > 0 {
-			time.Sleep(time.Duration(delayMs) * time.Millisecond)
+	for inFlight > 0 {
+		finish(<-done)
+	}
+	return read, readErr
+}
+
+// entryHost returns the host an entry's checks connect to.
+func entryHost(e BatchEntry) string {
+	if endpoint, err := normalizeURL(e.URL); err == nil {
+		if u, err := url.Parse(endpoint); err == nil {
+			return strings.ToLower(u.Host)
 		}
 	}
+	return e.URL
+}
 
-	wg.Wait()
-	return results
+// tokenBucket limits how often checks start: it holds up to burst tokens,
+// refilled at rate per second, and each start takes one.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes a token, sleeping until one is available or ctx is cancelled.
+func (b *tokenBucket) wait(ctx context.Context) {
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		sleepContext(ctx, time.Duration((1-b.tokens)/b.rate*float64(time.Second)))
+		now = time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens--
+}
+
+// sleepContext sleeps for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
 }
@@ -1,19 +1,39 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/port402/x402-cli/internal/exitcode"
 	"github.com/port402/x402-cli/internal/output"
 	"github.com/port402/x402-cli/internal/x402"
 )
 
+// checkedResults returns the results of the entries that were checked, in
+// entry order.
+func checkedResults(results []*output.HealthResult) []output.HealthResult {
+	checked := make([]output.HealthResult, 0, len(results))
+	for _, r := range results {
+		if r != nil {
+			checked = append(checked, *r)
+		}
+	}
+	return checked
+}
+
 func TestRunBatchChecks_MultipleURLs(t *testing.T) {
 	paymentReq := &x402.PaymentRequired{
 		X402Version: 2,
@@ -38,7 +58,7 @@ func TestRunBatchChecks_MultipleURLs(t *testing.T) {
 	}
 	timeout := 30 * time.Second
 
-	results := runBatchChecks(entries, timeout, 2, 0, false)
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: timeout, parallel: 2}, healthOptions{})
 
 	assert.Len(t, results, 3)
 	for _, r := range results {
@@ -73,7 +93,7 @@ func TestRunBatchChecks_SomeFail(t *testing.T) {
 		{URL: failServer.URL, Method: "GET"},
 		{URL: successServer.URL, Method: "GET"},
 	}
-	results := runBatchChecks(entries, 30*time.Second, 1, 0, false)
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 1}, healthOptions{})
 
 	assert.Len(t, results, 3)
 
@@ -104,7 +124,7 @@ func TestRunBatchChecks_FailFast(t *testing.T) {
 		{URL: failServer.URL, Method: "GET"},
 		{URL: failServer.URL, Method: "GET"},
 	}
-	results := runBatchChecks(entries, 30*time.Second, 1, 0, true)
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 1, failFast: true}, healthOptions{})
 
 	// With sequential execution and fail-fast, should stop at first failure
 	assert.GreaterOrEqual(t, len(results), 1)
@@ -142,7 +162,7 @@ func TestRunBatchChecks_Parallel(t *testing.T) {
 	}
 
 	start := time.Now()
-	results := runBatchChecks(entries, 30*time.Second, 4, 0, false) // 4 parallel
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 4}, healthOptions{}) // 4 parallel
 	duration := time.Since(start)
 
 	assert.Len(t, results, 4)
@@ -173,7 +193,7 @@ func TestRunBatchChecks_CountResults(t *testing.T) {
 		{URL: server.URL, Method: "GET"},
 		{URL: server.URL, Method: "GET"},
 	}
-	results := runBatchChecks(entries, 30*time.Second, 1, 0, false)
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 1}, healthOptions{})
 
 	// Simulate counting logic from runBatchHealth
 	passed := 0
@@ -255,3 +275,216 @@ func TestParseBatchInput_InvalidJSON(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestRunBatchChecks_Cancelled(t *testing.T) {
+	// The first request is answered; later ones hang until the client gives up
+	release := make(chan struct{})
+	var mu sync.Mutex
+	served := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		served++
+		first := served == 1
+		mu.Unlock()
+		if !first {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	entries := []BatchEntry{
+		{URL: server.URL, Method: "GET"},
+		{URL: server.URL, Method: "GET"},
+		{URL: server.URL, Method: "GET"},
+		{URL: server.URL, Method: "GET"},
+	}
+
+	for _, parallel := range []int{1, 2} {
+		mu.Lock()
+		served = 0
+		mu.Unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		start := time.Now()
+		results := checkedResults(runBatchChecks(ctx, entries, batchConfig{timeout: 30 * time.Second, parallel: parallel}, healthOptions{}))
+		cancel()
+
+		assert.Less(t, time.Since(start), 5*time.Second, "parallel=%d", parallel)
+		assert.Less(t, len(results), len(entries), "parallel=%d", parallel)
+
+		var ok, cancelled int
+		for _, r := range results {
+			switch {
+			case r.Status == 200:
+				ok++
+			case r.Error == "cancelled":
+				assert.Equal(t, int(exitcode.Cancelled), r.ExitCode)
+				cancelled++
+			}
+		}
+		assert.Equal(t, 1, ok, "parallel=%d", parallel)
+		assert.Equal(t, len(results)-1, cancelled, "parallel=%d", parallel)
+	}
+}
+
+func TestRunBatchChecks_FailFastAbortsInFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		<-r.Context().Done() // Hang until the client gives up
+	}))
+	defer server.Close()
+
+	entries := []BatchEntry{
+		{URL: server.URL + "/slow", Method: "GET"},
+		{URL: server.URL + "/slow", Method: "GET"},
+		{URL: server.URL + "/fail", Method: "GET"},
+		{URL: server.URL + "/slow", Method: "GET"},
+		{URL: server.URL + "/slow", Method: "GET"},
+	}
+
+	start := time.Now()
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 3, failFast: true}, healthOptions{})
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	require.NotNil(t, results[2])
+	assert.Equal(t, http.StatusInternalServerError, results[2].Status)
+	for _, i := range []int{0, 1, 3, 4} {
+		assert.Nil(t, results[i], "entry %d was aborted or never started", i)
+	}
+	assert.Len(t, checkedResults(results), 1)
+}
+
+func TestRunBatchChecks_PerHost(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	var otherAt atomic.Int64
+	start := time.Now()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAt.Store(int64(time.Since(start)))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer other.Close()
+
+	entries := []BatchEntry{
+		{URL: slow.URL, Method: "GET"},
+		{URL: slow.URL, Method: "GET"},
+		{URL: slow.URL, Method: "GET"},
+		{URL: other.URL, Method: "GET"},
+	}
+
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 2, perHost: 1}, healthOptions{})
+
+	assert.Len(t, checkedResults(results), 4)
+	assert.Equal(t, 1, maxInFlight)
+	// The other host doesn't queue behind the busy one
+	assert.Less(t, time.Duration(otherAt.Load()), 100*time.Millisecond)
+}
+
+func TestRunBatchChecks_Rate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	entries := make([]BatchEntry, 5)
+	for i := range entries {
+		entries[i] = BatchEntry{URL: server.URL, Method: "GET"}
+	}
+
+	start := time.Now()
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 5, rate: 20}, healthOptions{})
+
+	assert.Len(t, checkedResults(results), 5)
+	// One start is immediate, then one every 50ms
+	assert.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+}
+
+func TestRunBatchChecks_ReusesConnections(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		_, _ = w.Write([]byte(`{"error": "payment required"}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	entries := make([]BatchEntry, 20)
+	for i := range entries {
+		entries[i] = BatchEntry{URL: server.URL, Method: "GET"}
+	}
+
+	results := runBatchChecks(context.Background(), entries, batchConfig{timeout: 30 * time.Second, parallel: 4}, healthOptions{})
+
+	assert.Len(t, checkedResults(results), 20)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.LessOrEqual(t, conns, 4)
+}
+
+func TestBatchCollector_StreamingKeepsNoResults(t *testing.T) {
+	var buf bytes.Buffer
+	collected := &batchCollector{stream: json.NewEncoder(&buf)}
+	for idx, code := range []int{0, int(exitcode.Network), int(exitcode.Network)} {
+		collected.add(output.StreamRecord{Index: idx, HealthResult: &output.HealthResult{URL: "https://example.com", ExitCode: code}})
+	}
+
+	assert.Nil(t, collected.results)
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	batch := collected.result(42)
+	assert.Empty(t, batch.Results)
+	assert.Equal(t, 1, batch.Passed)
+	assert.Equal(t, 2, batch.Failed)
+	assert.Equal(t, int64(42), batch.Duration)
+	assert.Equal(t, exitcode.Network, collected.code)
+}
+
+func TestBatchCollector_ExitCode(t *testing.T) {
+	collect := func(codes ...int) *batchCollector {
+		c := &batchCollector{}
+		for i := len(codes) - 1; i >= 0; i-- {
+			c.add(output.StreamRecord{Index: i, HealthResult: &output.HealthResult{ExitCode: codes[i]}})
+		}
+		return c
+	}
+
+	assert.Equal(t, exitcode.OK, collect(0, 0).code)
+	assert.Equal(t, exitcode.Network, collect(0, 3, 3).code)
+	assert.Equal(t, exitcode.General, collect(3, 0, 4).code)
+
+	// Buffered results come back in input order
+	batch := collect(0, 3, 4).result(0)
+	require.Len(t, batch.Results, 3)
+	assert.Equal(t, []int{0, 3, 4}, []int{batch.Results[0].ExitCode, batch.Results[1].ExitCode, batch.Results[2].ExitCode})
+}
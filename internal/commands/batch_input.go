@@ -0,0 +1,525 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/port402/x402-cli/internal/baseline"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// BatchEntry represents an endpoint to check with optional per-entry settings.
+type BatchEntry struct {
+	URL     string            `json:"url" yaml:"url"`
+	Method  string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty"`
+	Timeout Duration          `json:"timeout,omitempty" yaml:"timeout,omitempty"` // Overrides --timeout
+	Tags    []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Protocol is the expected protocol version ("v1" or "v2").
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	// Price is the expected price in token units, e.g. "0.01" or "0.01 USDC".
+	Price string `json:"price,omitempty" yaml:"price,omitempty"`
+	// Expect holds further assertions on the response.
+	Expect *Expectations `json:"expect,omitempty" yaml:"expect,omitempty"`
+
+	// Listed holds the payment options a discovery list advertises for the
+	// entry (--from-discovery); the live 402 must match them.
+	Listed []baseline.Option `json:"-" yaml:"-"`
+}
+
+// Duration is a timeout given either as a Go duration string ("10s", "1m")
+// or as a number of seconds.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	return d.set(s)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.set(node.Value)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: use seconds or a duration like 10s", s)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// HasAnyTag reports whether the entry carries at least one of tags.
+// An empty tag list matches every entry.
+func (e BatchEntry) HasAnyTag(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, want := range tags {
+		for _, have := range e.Tags {
+			if strings.EqualFold(want, have) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadBatchFile parses a batch input file, choosing the format from its
+// extension: .yaml/.yml, .csv, .txt, .ndjson/.jsonl, or JSON. Files with
+// other extensions are read as JSON if they start with '[' and as plain text
+// otherwise.
+func loadBatchFile(path string, data []byte) ([]BatchEntry, error) {
+	reader, err := openBatchReader(path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return readAllEntries(reader)
+}
+
+// parseBatchInput parses JSON input supporting both simple URL arrays and object arrays.
+func parseBatchInput(data []byte) ([]BatchEntry, error) {
+	return readAllEntries(&normalizingReader{next: newJSONReader(bytes.NewReader(data))})
+}
+
+// batchReader yields batch entries one at a time, so large inputs need not
+// be held in memory.
+type batchReader interface {
+	// Next returns the next entry, or io.EOF after the last one.
+	Next() (BatchEntry, error)
+}
+
+// openBatchReader reads batch input from r in the format loadBatchFile
+// would choose. Entries are validated and given defaults as they are read.
+// YAML is parsed whole; the other formats are streamed.
+func openBatchReader(path string, r io.Reader) (batchReader, error) {
+	br := bufio.NewReader(r)
+
+	var next batchReader
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		entries, err := parseBatchYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		return &sliceReader{entries: entries}, nil
+	case ".csv":
+		csvReader, err := newCSVReader(br)
+		if err != nil {
+			return nil, err
+		}
+		next = csvReader
+	case ".txt":
+		next = newTextReader(br)
+	case ".json":
+		next = newJSONReader(br)
+	case ".ndjson", ".jsonl":
+		next = newNDJSONReader(br)
+	default:
+		if startsWith(br, '[') {
+			next = newJSONReader(br)
+		} else {
+			next = newTextReader(br)
+		}
+	}
+	return &normalizingReader{next: next}, nil
+}
+
+// readAllEntries drains reader.
+func readAllEntries(reader batchReader) ([]BatchEntry, error) {
+	var entries []BatchEntry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// startsWith reports whether the first non-space byte in br is c, without
+// consuming it.
+func startsWith(br *bufio.Reader, c byte) bool {
+	for n := 1; ; n++ {
+		peek, err := br.Peek(n)
+		if len(peek) < n {
+			return false
+		}
+		b := peek[n-1]
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			if err != nil {
+				return false
+			}
+			continue
+		}
+		return b == c
+	}
+}
+
+// sliceReader yields entries already in memory.
+type sliceReader struct {
+	entries []BatchEntry
+	next    int
+}
+
+func (r *sliceReader) Next() (BatchEntry, error) {
+	if r.next >= len(r.entries) {
+		return BatchEntry{}, io.EOF
+	}
+	r.next++
+	return r.entries[r.next-1], nil
+}
+
+// normalizingReader validates entries and fills in defaults as they pass.
+type normalizingReader struct {
+	next batchReader
+	n    int
+}
+
+func (r *normalizingReader) Next() (BatchEntry, error) {
+	entry, err := r.next.Next()
+	if err != nil {
+		return BatchEntry{}, err
+	}
+	r.n++
+	if err := normalizeBatchEntry(r.n, &entry); err != nil {
+		return BatchEntry{}, err
+	}
+	return entry, nil
+}
+
+// tagReader skips entries that carry none of tags.
+type tagReader struct {
+	next batchReader
+	tags []string
+	read int // Entries read, including skipped ones
+}
+
+func (r *tagReader) Next() (BatchEntry, error) {
+	for {
+		entry, err := r.next.Next()
+		if err != nil {
+			return BatchEntry{}, err
+		}
+		r.read++
+		if entry.HasAnyTag(r.tags) {
+			return entry, nil
+		}
+	}
+}
+
+var errBatchJSON = errors.New("failed to parse JSON: expected array of URLs or array of {url, method} objects")
+
+// jsonReader streams the elements of a JSON array of URLs or entry objects.
+type jsonReader struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+}
+
+func newJSONReader(r io.Reader) *jsonReader {
+	return &jsonReader{dec: json.NewDecoder(r)}
+}
+
+func (r *jsonReader) Next() (BatchEntry, error) {
+	if r.done {
+		return BatchEntry{}, io.EOF
+	}
+	if !r.started {
+		if tok, err := r.dec.Token(); err != nil || tok != json.Delim('[') {
+			return BatchEntry{}, errBatchJSON
+		}
+		r.started = true
+	}
+	if !r.dec.More() {
+		if _, err := r.dec.Token(); err != nil {
+			return BatchEntry{}, errBatchJSON
+		}
+		r.done = true
+		return BatchEntry{}, io.EOF
+	}
+	return decodeJSONEntry(r.dec)
+}
+
+// ndjsonReader streams newline-delimited JSON: one URL string or entry
+// object per line.
+type ndjsonReader struct {
+	dec *json.Decoder
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	return &ndjsonReader{dec: json.NewDecoder(r)}
+}
+
+func (r *ndjsonReader) Next() (BatchEntry, error) {
+	if !r.dec.More() {
+		return BatchEntry{}, io.EOF
+	}
+	return decodeJSONEntry(r.dec)
+}
+
+// decodeJSONEntry decodes the next value, a URL string or an entry object.
+func decodeJSONEntry(dec *json.Decoder) (BatchEntry, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return BatchEntry{}, errBatchJSON
+	}
+
+	var entry BatchEntry
+	if len(raw) > 0 && raw[0] == '"' {
+		err := json.Unmarshal(raw, &entry.URL)
+		return entry, err
+	}
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			return BatchEntry{}, errBatchJSON
+		}
+		return BatchEntry{}, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return entry, nil
+}
+
+// parseBatchYAML parses a YAML list of URLs or entry objects. The list may
+// also be nested under a top-level "endpoints" key.
+func parseBatchYAML(data []byte) ([]BatchEntry, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+
+	list := root.Content[0]
+	if list.Kind == yaml.MappingNode {
+		list = nil
+		for i := 0; i+1 < len(root.Content[0].Content); i += 2 {
+			if root.Content[0].Content[i].Value == "endpoints" {
+				list = root.Content[0].Content[i+1]
+			}
+		}
+		if list == nil {
+			return nil, fmt.Errorf("failed to parse YAML: expected a list or an \"endpoints\" key")
+		}
+	}
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("failed to parse YAML: expected a list of URLs or {url, method} objects")
+	}
+
+	entries := make([]BatchEntry, len(list.Content))
+	for i, item := range list.Content {
+		if item.Kind == yaml.ScalarNode {
+			entries[i] = BatchEntry{URL: item.Value}
+			continue
+		}
+		if err := item.Decode(&entries[i]); err != nil {
+			return nil, fmt.Errorf("entry %d (line %d): %w", i+1, item.Line, err)
+		}
+	}
+
+	return normalizeBatchEntries(entries)
+}
+
+// csvColumns are the columns understood in CSV input. Only url is required.
+var csvColumns = []string{"url", "method", "headers", "body", "timeout", "protocol", "price", "tags"}
+
+// csvReader streams CSV with a header row. Headers are "Name: value"
+// pairs and tags are names, both separated by ';'.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	n       int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: missing header row")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, c := range csvColumns {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("failed to parse CSV: unknown column %q (supported: %s)", name, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, fmt.Errorf("failed to parse CSV: missing url column")
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Next() (BatchEntry, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return BatchEntry{}, io.EOF
+	}
+	if err != nil {
+		return BatchEntry{}, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	r.n++
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	entry := BatchEntry{
+		URL:      field("url"),
+		Method:   field("method"),
+		Body:     field("body"),
+		Protocol: field("protocol"),
+		Price:    field("price"),
+		Tags:     splitList(field("tags")),
+	}
+	if err := entry.Timeout.set(field("timeout")); err != nil {
+		return BatchEntry{}, fmt.Errorf("entry %d: %w", r.n, err)
+	}
+	for _, h := range splitList(field("headers")) {
+		key, value, found := strings.Cut(h, ":")
+		if !found {
+			return BatchEntry{}, fmt.Errorf("entry %d: malformed header (missing ':'): %s", r.n, h)
+		}
+		if entry.Headers == nil {
+			entry.Headers = make(map[string]string)
+		}
+		entry.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return entry, nil
+}
+
+// textReader streams one URL per line, optionally preceded by a method
+// ("POST https://..."). Blank lines and lines starting with '#' are skipped.
+type textReader struct {
+	scanner *bufio.Scanner
+	n       int
+}
+
+func newTextReader(r io.Reader) *textReader {
+	return &textReader{scanner: bufio.NewScanner(r)}
+}
+
+func (r *textReader) Next() (BatchEntry, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r.n++
+
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 1:
+			return BatchEntry{URL: fields[0]}, nil
+		case 2:
+			return BatchEntry{Method: fields[0], URL: fields[1]}, nil
+		default:
+			return BatchEntry{}, fmt.Errorf("entry %d: expected \"[METHOD] URL\", got %q", r.n, line)
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return BatchEntry{}, fmt.Errorf("failed to read input: %w", err)
+	}
+	return BatchEntry{}, io.EOF
+}
+
+// normalizeBatchEntries validates entries and fills in defaults.
+func normalizeBatchEntries(entries []BatchEntry) ([]BatchEntry, error) {
+	for i := range entries {
+		if err := normalizeBatchEntry(i+1, &entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// normalizeBatchEntry validates entry number n and fills in defaults.
+func normalizeBatchEntry(n int, entry *BatchEntry) error {
+	if entry.URL == "" {
+		return fmt.Errorf("entry %d: missing URL", n)
+	}
+	// Default to GET if method not specified
+	if entry.Method == "" {
+		entry.Method = "GET"
+	} else {
+		entry.Method = strings.ToUpper(entry.Method)
+	}
+	if p := entry.Protocol; p != "" {
+		if _, err := x402.ParseProtocolVersion(p); err != nil {
+			return fmt.Errorf("entry %d: %w", n, err)
+		}
+	}
+	if entry.Expect != nil {
+		if err := entry.Expect.validate(); err != nil {
+			return fmt.Errorf("entry %d: %w", n, err)
+		}
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
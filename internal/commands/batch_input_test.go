@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestLoadBatchFile_YAML(t *testing.T) {
+	input := `
+endpoints:
+  - https://api1.example.com
+  - url: https://api2.example.com/search
+    method: post
+    headers:
+      Content-Type: application/json
+    body: '{"q": "test"}'
+    timeout: 10s
+    protocol: v2
+    price: 0.01 USDC
+    tags: [prod, search]
+  - url: https://api3.example.com
+    timeout: 5
+`
+	entries, err := loadBatchFile("endpoints.yaml", []byte(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, BatchEntry{URL: "https://api1.example.com", Method: "GET"}, entries[0])
+	assert.Equal(t, BatchEntry{
+		URL:      "https://api2.example.com/search",
+		Method:   "POST",
+		Headers:  map[string]string{"Content-Type": "application/json"},
+		Body:     `{"q": "test"}`,
+		Timeout:  Duration(10 * time.Second),
+		Protocol: "v2",
+		Price:    "0.01 USDC",
+		Tags:     []string{"prod", "search"},
+	}, entries[1])
+	assert.Equal(t, Duration(5*time.Second), entries[2].Timeout)
+}
+
+func TestLoadBatchFile_YAMLErrors(t *testing.T) {
+	_, err := loadBatchFile("x.yml", []byte("- url: https://a.example\n  timeout: soon\n"))
+	assert.ErrorContains(t, err, `invalid timeout "soon"`)
+
+	_, err = loadBatchFile("x.yml", []byte("- url: https://a.example\n  protocol: v9\n"))
+	assert.ErrorContains(t, err, "entry 1: unsupported protocol version 9")
+
+	_, err = loadBatchFile("x.yml", []byte("urls: []\n"))
+	assert.ErrorContains(t, err, `"endpoints" key`)
+}
+
+func TestLoadBatchFile_CSV(t *testing.T) {
+	input := `url,method,headers,body,timeout,tags
+# inventory export
+https://api1.example.com,,,,,prod
+https://api2.example.com,POST,"Content-Type: application/json; X-Key: abc","{""q"": 1}",2.5,prod;eu
+`
+	entries, err := loadBatchFile("endpoints.csv", []byte(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "GET", entries[0].Method)
+	assert.Equal(t, []string{"prod"}, entries[0].Tags)
+	assert.Equal(t, "POST", entries[1].Method)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Key": "abc"}, entries[1].Headers)
+	assert.Equal(t, `{"q": 1}`, entries[1].Body)
+	assert.Equal(t, Duration(2500*time.Millisecond), entries[1].Timeout)
+	assert.Equal(t, []string{"prod", "eu"}, entries[1].Tags)
+
+	_, err = loadBatchFile("x.csv", []byte("address\nhttps://a.example\n"))
+	assert.ErrorContains(t, err, `unknown column "address"`)
+}
+
+func TestLoadBatchFile_Text(t *testing.T) {
+	input := `# production endpoints
+https://api1.example.com
+
+POST https://api2.example.com
+`
+	entries, err := loadBatchFile("urls.txt", []byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, []BatchEntry{
+		{URL: "https://api1.example.com", Method: "GET"},
+		{URL: "https://api2.example.com", Method: "POST"},
+	}, entries)
+
+	// Unknown extensions are sniffed
+	entries, err = loadBatchFile("urls", []byte(`["https://a.example"]`))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	entries, err = loadBatchFile("urls", []byte("https://a.example\nhttps://b.example\n"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestParseBatchInput_EntrySettings(t *testing.T) {
+	input := `[{"url": "https://a.example", "timeout": "3s", "tags": ["prod"]}, {"url": "https://b.example", "timeout": 7}]`
+
+	entries, err := parseBatchInput([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, Duration(3*time.Second), entries[0].Timeout)
+	assert.Equal(t, Duration(7*time.Second), entries[1].Timeout)
+
+	_, err = parseBatchInput([]byte(`[{"url": "https://a.example", "timeout": "later"}]`))
+	assert.ErrorContains(t, err, `invalid timeout "later"`)
+}
+
+func TestTagReader(t *testing.T) {
+	entries := []BatchEntry{
+		{URL: "a", Tags: []string{"prod", "eu"}},
+		{URL: "b", Tags: []string{"staging"}},
+		{URL: "c"},
+	}
+	filter := func(tags ...string) []BatchEntry {
+		r := &tagReader{next: &sliceReader{entries: entries}, tags: tags}
+		got, err := readAllEntries(r)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.read)
+		return got
+	}
+
+	assert.Equal(t, entries, filter())
+	assert.Equal(t, []BatchEntry{entries[0]}, filter("PROD"))
+	assert.Len(t, filter("eu", "staging"), 2)
+	assert.Empty(t, filter("us"))
+}
+
+func TestOpenBatchReader_Streams(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		input string
+	}{
+		{"ndjson", "urls.ndjson", "\"https://a.example\"\n\n{\"url\": \"b.example\", \"method\": \"post\"}\n"},
+		{"jsonl", "urls.jsonl", "\"https://a.example\"\n{\"url\": \"b.example\", \"method\": \"POST\"}"},
+		{"json", "urls.json", `["https://a.example", {"url": "b.example", "method": "POST"}]`},
+		{"sniffed json", "urls", ` ["https://a.example", {"url": "b.example", "method": "POST"}]`},
+		{"csv", "urls.csv", "url,method\nhttps://a.example,\nb.example,POST\n"},
+		{"text", "urls.txt", "https://a.example\nPOST b.example\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := openBatchReader(tt.path, strings.NewReader(tt.input))
+			require.NoError(t, err)
+
+			entries, err := readAllEntries(r)
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			assert.Equal(t, "https://a.example", entries[0].URL)
+			assert.Equal(t, "GET", entries[0].Method)
+			assert.Equal(t, "b.example", entries[1].URL)
+			assert.Equal(t, "POST", entries[1].Method)
+		})
+	}
+}
+
+func TestOpenBatchReader_ErrorsAfterValidEntries(t *testing.T) {
+	r, err := openBatchReader("urls.ndjson", strings.NewReader("\"https://a.example\"\n{\"method\": \"GET\"}\n"))
+	require.NoError(t, err)
+
+	entry, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "https://a.example", entry.URL)
+
+	_, err = r.Next()
+	assert.ErrorContains(t, err, "entry 2")
+}
+
+func TestCheckBatchEntry(t *testing.T) {
+	var gotBody, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotHeader = r.Header.Get("X-Key")
+
+		paymentReq := &x402.PaymentRequired{
+			X402Version: 2,
+			Accepts: []x402.PaymentRequirement{{
+				Scheme:  "exact",
+				Network: "eip155:84532",
+				Amount:  "10000",
+				Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+				PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+			}},
+		}
+		jsonBytes, _ := json.Marshal(paymentReq)
+		w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(jsonBytes))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	findCheck := func(r *output.HealthResult, name string) *output.Check {
+		for i := range r.Checks {
+			if r.Checks[i].Name == name {
+				return &r.Checks[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("request settings and matching expectations", func(t *testing.T) {
+		entry := BatchEntry{
+			URL:      server.URL,
+			Method:   "POST",
+			Headers:  map[string]string{"X-Key": "abc"},
+			Body:     `{"q": 1}`,
+			Protocol: "v2",
+			Price:    "0.01 USDC",
+		}
+		result := checkBatchEntry(context.Background(), entry, 5*time.Second, healthOptions{})
+
+		assert.Equal(t, `{"q": 1}`, gotBody)
+		assert.Equal(t, "abc", gotHeader)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, output.StatusPass, findCheck(result, "Expected protocol").Status)
+		check := findCheck(result, "Expected price")
+		assert.Equal(t, output.StatusPass, check.Status)
+		assert.Equal(t, "0.01 USDC on Base Sepolia", check.Message)
+	})
+
+	t.Run("mismatched expectations fail", func(t *testing.T) {
+		entry := BatchEntry{URL: server.URL, Method: "GET", Protocol: "v1", Price: "0.05"}
+		result := checkBatchEntry(context.Background(), entry, 5*time.Second, healthOptions{})
+
+		// Failed expectations share one exit code
+		assert.Equal(t, int(exitcode.ExpectationFailed), result.ExitCode)
+		assert.Equal(t, "Expected v1, got v2", findCheck(result, "Expected protocol").Message)
+		assert.Equal(t, "Expected 0.05, got 0.01 USDC", findCheck(result, "Expected price").Message)
+	})
+}
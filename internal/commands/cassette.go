@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/cassette"
+	"github.com/port402/x402-cli/internal/exitcode"
+)
+
+// addCassetteFlags registers the mutually exclusive --record and --replay flags.
+func addCassetteFlags(cmd *cobra.Command, recordDir, replayDir *string) {
+	cmd.Flags().StringVar(recordDir, "record", "", "Record HTTP exchanges to a cassette directory")
+	cmd.Flags().StringVar(replayDir, "replay", "", "Replay HTTP exchanges from a cassette directory (no network)")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
+}
+
+// cassetteTransport returns the transport for --record or --replay, or next
+// for live traffic. Recording sends requests through next.
+func cassetteTransport(recordDir, replayDir string, next http.RoundTripper) (http.RoundTripper, error) {
+	if recordDir != "" {
+		recorder, err := cassette.NewRecorder(recordDir, next)
+		if err != nil {
+			return nil, exitcode.New(exitcode.Input, err)
+		}
+		return recorder, nil
+	}
+	if replayDir != "" {
+		replayer, err := cassette.NewReplayer(replayDir)
+		if err != nil {
+			return nil, exitcode.New(exitcode.Input, err)
+		}
+		return replayer, nil
+	}
+	return next, nil
+}
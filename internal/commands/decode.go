@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// Document types understood by the decode command.
+const (
+	docPaymentRequired = "payment-required"
+	docPaymentPayload  = "payment-payload"
+	docPaymentResponse = "payment-response"
+)
+
+var (
+	decodeType     string
+	decodeStrict   bool
+	decodeProtocol string
+)
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode <value|->",
+	Short: "Decode and validate an x402 header or payload",
+	Long: `Decode an x402 header value or JSON document and pretty-print it.
+
+Accepts the base64 value of a PAYMENT-REQUIRED, PAYMENT-SIGNATURE / X-PAYMENT
+or PAYMENT-RESPONSE / X-PAYMENT-RESPONSE header, or the plain JSON of a v1
+402 response body. Use "-" to read the value from stdin.
+
+The document type is detected from its fields; override it with --type.
+
+Use --strict to validate payment requirements and payment responses against
+the JSON schema for their protocol version. The version is taken from the
+document's x402Version field unless --protocol is given.
+
+Examples:
+  x402 decode eyJ4NDAyVmVyc2lvbiI6Mi...
+  x402 decode - --strict < body.json
+  x402 decode eyJzdWNjZXNzIjp0cnVl... --type payment-response --strict --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDecode,
+}
+
+func init() {
+	decodeCmd.Flags().StringVar(&decodeType, "type", "", "Document type: payment-required, payment-payload, payment-response (default: detect)")
+	decodeCmd.Flags().BoolVar(&decodeStrict, "strict", false, "Validate against the protocol JSON schema")
+	decodeCmd.Flags().StringVar(&decodeProtocol, "protocol", "", "Protocol version for --strict: v1 or v2 (default: from x402Version)")
+	rootCmd.AddCommand(decodeCmd)
+}
+
+// decodeResult is the --json output of the decode command.
+type decodeResult struct {
+	Type             string           `json:"type"`
+	X402Version      int              `json:"x402Version,omitempty"`
+	Document         json.RawMessage  `json:"document"`
+	SchemaViolations []x402.Violation `json:"schemaViolations,omitempty"`
+}
+
+func runDecode(cmd *cobra.Command, args []string) error {
+	input := args[0]
+	if input == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		input = string(data)
+	}
+
+	forcedProtocol, err := x402.ParseProtocolVersion(decodeProtocol)
+	if err != nil {
+		return exitcode.New(exitcode.Input, err)
+	}
+
+	raw, err := decodeX402Value(input)
+	if err != nil {
+		return exitcode.New(exitcode.Input, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return exitcode.Errorf(exitcode.Input, "not a JSON object: %w", err)
+	}
+
+	result := decodeResult{Type: decodeType, Document: raw}
+	if result.Type == "" {
+		result.Type = detectDocumentType(fields)
+		if result.Type == "" {
+			return exitcode.Errorf(exitcode.Input, "cannot detect document type (use --type)")
+		}
+	}
+	switch result.Type {
+	case docPaymentRequired, docPaymentPayload, docPaymentResponse:
+	default:
+		return exitcode.Errorf(exitcode.Input, "unknown document type %q (expected %s, %s or %s)",
+			result.Type, docPaymentRequired, docPaymentPayload, docPaymentResponse)
+	}
+
+	if v, ok := fields["x402Version"]; ok {
+		json.Unmarshal(v, &result.X402Version)
+	}
+
+	if decodeStrict {
+		protocolVersion := forcedProtocol
+		if protocolVersion == 0 {
+			protocolVersion = result.X402Version
+		}
+		if protocolVersion == 0 {
+			// Payment responses carry no version field; default to the current protocol
+			protocolVersion = x402.ProtocolV2
+		}
+
+		var violations []x402.Violation
+		switch result.Type {
+		case docPaymentRequired:
+			violations, err = x402.ValidatePaymentRequired(raw, protocolVersion)
+		case docPaymentResponse:
+			violations, err = x402.ValidatePaymentResponse(raw, protocolVersion)
+		default:
+			err = exitcode.Errorf(exitcode.Input, "--strict supports %s and %s documents", docPaymentRequired, docPaymentResponse)
+		}
+		if err != nil {
+			return err
+		}
+		result.SchemaViolations = violations
+	}
+
+	if GetJSONOutput() {
+		if err := output.PrintJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printDecodeResult(result)
+	}
+
+	if len(result.SchemaViolations) > 0 {
+		return exitcode.Errorf(exitcode.Protocol, "%d schema violation(s)", len(result.SchemaViolations))
+	}
+
+	return nil
+}
+
+// decodeX402Value returns the JSON document in an x402 header value. Plain JSON
+// is returned as-is; otherwise the value is base64-decoded (standard or URL-safe).
+func decodeX402Value(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("empty value")
+	}
+
+	if strings.HasPrefix(value, "{") {
+		return []byte(value), nil
+	}
+
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := enc.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+
+	return nil, fmt.Errorf("value is neither JSON nor base64")
+}
+
+// detectDocumentType guesses the x402 document type from its top-level fields.
+func detectDocumentType(fields map[string]json.RawMessage) string {
+	if _, ok := fields["accepts"]; ok {
+		return docPaymentRequired
+	}
+	if _, ok := fields["payload"]; ok {
+		return docPaymentPayload
+	}
+	if _, ok := fields["success"]; ok {
+		return docPaymentResponse
+	}
+	return ""
+}
+
+func printDecodeResult(result decodeResult) {
+	if result.X402Version > 0 {
+		fmt.Printf("Type: %s (v%d)\n", result.Type, result.X402Version)
+	} else {
+		fmt.Printf("Type: %s\n", result.Type)
+	}
+	fmt.Println()
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, result.Document, "", "  "); err != nil {
+		fmt.Println(string(result.Document))
+	} else {
+		fmt.Println(pretty.String())
+	}
+
+	if !decodeStrict {
+		return
+	}
+
+	fmt.Println()
+	if len(result.SchemaViolations) == 0 {
+		fmt.Println("✓ Schema valid")
+		return
+	}
+	fmt.Printf("✗ %d schema violation(s):\n", len(result.SchemaViolations))
+	for _, v := range result.SchemaViolations {
+		fmt.Printf("  %s\n", v)
+	}
+}
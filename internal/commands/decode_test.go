@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeX402Value(t *testing.T) {
+	doc := `{"x402Version":2,"accepts":[]}`
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"plain JSON", doc},
+		{"plain JSON with whitespace", "  " + doc + "\n"},
+		{"standard base64", base64.StdEncoding.EncodeToString([]byte(doc))},
+		{"unpadded base64", base64.RawStdEncoding.EncodeToString([]byte(doc))},
+		{"url-safe base64", base64.URLEncoding.EncodeToString([]byte(doc))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeX402Value(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, doc, string(decoded))
+		})
+	}
+
+	_, err := decodeX402Value("")
+	assert.Error(t, err)
+
+	_, err = decodeX402Value("not base64!")
+	assert.ErrorContains(t, err, "neither JSON nor base64")
+}
+
+func TestDetectDocumentType(t *testing.T) {
+	tests := []struct {
+		doc      string
+		expected string
+	}{
+		{`{"x402Version":2,"accepts":[]}`, docPaymentRequired},
+		{`{"x402Version":1,"scheme":"exact","network":"base","payload":{}}`, docPaymentPayload},
+		{`{"success":true,"transaction":"0x1","network":"base"}`, docPaymentResponse},
+		{`{"foo":"bar"}`, ""},
+	}
+
+	for _, tt := range tests {
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal([]byte(tt.doc), &fields))
+		assert.Equal(t, tt.expected, detectDocumentType(fields), tt.doc)
+	}
+}
@@ -0,0 +1,288 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/openapi"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/tokens"
+)
+
+// Discover command flags
+var (
+	discoverSpec     string
+	discoverBase     string
+	discoverMethods  []string
+	discoverOutput   string
+	discoverParallel int
+	discoverTimeout  int
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Find x402-gated operations in an OpenAPI spec",
+	Long: `Enumerate the operations in an OpenAPI 3 document (YAML or JSON) and
+probe each one with an unpaid request to find out which are x402-gated and
+what they cost.
+
+Path, required query and required header parameters are filled from the
+spec's examples: the parameter's example, its first named example, or its
+schema's example, default or first enum value. Request bodies use the
+example of the first JSON media type. Operations whose required values have
+no example are reported as skipped.
+
+The base URL defaults to the first absolute URL in the spec's servers list.
+
+Only GET and POST operations are probed by default, because probes reach
+free routes too. Use --methods to choose others.
+
+Use --output to write the gated operations as a batch-health input file
+(YAML for .yaml/.yml, JSON otherwise).
+
+Examples:
+  x402 discover --openapi spec.yaml
+  x402 discover --openapi spec.yaml --base https://api.example.com
+  x402 discover --openapi spec.json --output endpoints.yaml
+  x402 discover --openapi spec.yaml --methods GET,POST,PUT --parallel 5`,
+	Args: cobra.NoArgs,
+	RunE: runDiscover,
+}
+
+func init() {
+	discoverCmd.Flags().StringVar(&discoverSpec, "openapi", "", "OpenAPI 3 document (YAML or JSON)")
+	discoverCmd.Flags().StringVar(&discoverBase, "base", "", "Base URL (default: first server URL in the spec)")
+	discoverCmd.Flags().StringSliceVar(&discoverMethods, "methods", []string{"GET", "POST"}, "HTTP methods to probe")
+	discoverCmd.Flags().StringVarP(&discoverOutput, "output", "o", "", "Write gated operations as a batch-health input file")
+	discoverCmd.Flags().IntVar(&discoverParallel, "parallel", 1, "Number of parallel probes")
+	discoverCmd.Flags().IntVar(&discoverTimeout, "timeout", 30, "Request timeout in seconds")
+	_ = discoverCmd.MarkFlagRequired("openapi")
+
+	rootCmd.AddCommand(discoverCmd)
+}
+
+// DiscoveredOperation is an OpenAPI operation with its probe result.
+type DiscoveredOperation struct {
+	openapi.Request
+	Status int      `json:"status,omitempty"`
+	Gated  bool     `json:"gated"`
+	Prices []string `json:"prices,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// DiscoveryResult summarizes an OpenAPI discovery run.
+type DiscoveryResult struct {
+	Spec       string                `json:"spec"`
+	Base       string                `json:"base"`
+	Gated      int                   `json:"gated"`
+	Free       int                   `json:"free"`
+	Failed     int                   `json:"failed"`
+	Skipped    int                   `json:"skipped"`
+	Operations []DiscoveredOperation `json:"operations"`
+}
+
+func runDiscover(cmd *cobra.Command, args []string) error {
+	for _, m := range discoverMethods {
+		if !containsMethod(openapi.Methods, m) {
+			return exitcode.Errorf(exitcode.Input, "unsupported method %q (supported: %s)", m, strings.Join(openapi.Methods, ", "))
+		}
+	}
+
+	data, err := os.ReadFile(discoverSpec)
+	if err != nil {
+		return exitcode.Errorf(exitcode.Input, "failed to read file: %w", err)
+	}
+
+	doc, err := openapi.Parse(data)
+	if err != nil {
+		return exitcode.New(exitcode.Input, err)
+	}
+
+	base := discoverBase
+	if base == "" {
+		base = doc.ServerURL()
+		if base == "" {
+			return exitcode.Errorf(exitcode.Input, "the spec has no absolute server URL; use --base")
+		}
+	}
+
+	requests := doc.Requests(base, discoverMethods)
+	if len(requests) == 0 {
+		return exitcode.Errorf(exitcode.Input, "no %s operations in %s", strings.Join(discoverMethods, "/"), discoverSpec)
+	}
+
+	cmd.SilenceUsage = true
+
+	ctx := cmd.Context()
+	result := discoverOperations(ctx, requests, time.Duration(discoverTimeout)*time.Second, discoverParallel)
+	result.Spec = discoverSpec
+	result.Base = base
+
+	if discoverOutput != "" {
+		if err := writeDiscoveredEntries(discoverOutput, result.Operations); err != nil {
+			return err
+		}
+	}
+
+	if GetJSONOutput() {
+		if err := output.PrintJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printDiscoveryResult(result)
+		if discoverOutput != "" {
+			fmt.Printf("\nWrote %d gated operation(s) to %s\n", result.Gated, discoverOutput)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return errCancelled
+	}
+	return nil
+}
+
+// discoverOperations probes every request that could be built and classifies
+// each operation as gated, free, failed or skipped.
+func discoverOperations(ctx context.Context, requests []openapi.Request, timeout time.Duration, parallel int) *DiscoveryResult {
+	var entries []BatchEntry
+	var probed []int
+	for i, req := range requests {
+		if req.Skipped != "" {
+			continue
+		}
+		entries = append(entries, BatchEntry{URL: req.URL, Method: req.Method, Headers: req.Headers, Body: req.Body})
+		probed = append(probed, i)
+	}
+
+	results := runBatchChecks(ctx, entries, batchConfig{timeout: timeout, parallel: parallel}, healthOptions{})
+
+	ops := make([]DiscoveredOperation, len(requests))
+	for i, req := range requests {
+		ops[i] = DiscoveredOperation{Request: req}
+	}
+	for j, i := range probed {
+		if results[j] == nil {
+			ops[i].Error = "not checked (cancelled)"
+			continue
+		}
+		classifyOperation(&ops[i], results[j])
+	}
+
+	result := &DiscoveryResult{Operations: ops}
+	for _, op := range ops {
+		switch {
+		case op.Skipped != "":
+			result.Skipped++
+		case op.Gated:
+			result.Gated++
+		case op.Error != "":
+			result.Failed++
+		default:
+			result.Free++
+		}
+	}
+	return result
+}
+
+func classifyOperation(op *DiscoveredOperation, r *output.HealthResult) {
+	op.Status = r.Status
+
+	switch {
+	case r.Status == 402 && len(r.PaymentOptions) > 0:
+		op.Gated = true
+		for _, opt := range r.PaymentOptions {
+			op.Prices = append(op.Prices, fmt.Sprintf("%s on %s", opt.AmountHuman, tokens.GetNetworkName(opt.Network)))
+		}
+	case r.Status >= 200 && r.Status < 400:
+		// Free route
+	case r.Error != "":
+		op.Error = r.Error
+	case r.Status == 402:
+		op.Error = "402 without payment options"
+	default:
+		op.Error = fmt.Sprintf("HTTP %d", r.Status)
+	}
+}
+
+// writeDiscoveredEntries writes the gated operations as batch entries, in
+// YAML or JSON depending on the file extension.
+func writeDiscoveredEntries(path string, ops []DiscoveredOperation) error {
+	entries := []BatchEntry{}
+	for _, op := range ops {
+		if op.Gated {
+			entries = append(entries, BatchEntry{
+				URL:     op.URL,
+				Method:  op.Method,
+				Headers: op.Headers,
+				Body:    op.Body,
+				Tags:    op.Tags,
+			})
+		}
+	}
+
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(entries)
+	default:
+		data, err = json.MarshalIndent(entries, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("encoding batch file: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing batch file: %w", err)
+	}
+	return nil
+}
+
+func printDiscoveryResult(result *DiscoveryResult) {
+	fmt.Println()
+	fmt.Println("x402 OpenAPI Discovery")
+	fmt.Println("──────────────────────")
+	fmt.Printf("Spec:     %s\n", result.Spec)
+	fmt.Printf("Base:     %s\n", result.Base)
+	fmt.Printf("Gated:    %d\n", result.Gated)
+	fmt.Printf("Free:     %d\n", result.Free)
+	if result.Failed > 0 {
+		fmt.Printf("Failed:   %d\n", result.Failed)
+	}
+	if result.Skipped > 0 {
+		fmt.Printf("Skipped:  %d\n", result.Skipped)
+	}
+
+	fmt.Println()
+	for _, op := range result.Operations {
+		switch {
+		case op.Skipped != "":
+			fmt.Printf("  - %s %s  skipped: %s\n", op.Method, op.Path, op.Skipped)
+		case op.Gated:
+			fmt.Printf("  $ %s %s  %s\n", op.Method, op.Path, strings.Join(op.Prices, ", "))
+		case op.Error != "":
+			fmt.Printf("  ✗ %s %s  %s\n", op.Method, op.Path, op.Error)
+		default:
+			fmt.Printf("  ✓ %s %s  free (%d)\n", op.Method, op.Path, op.Status)
+		}
+	}
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
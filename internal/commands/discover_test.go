@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/openapi"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestDiscoverOperations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/paid":
+			paymentReq := &x402.PaymentRequired{
+				X402Version: 2,
+				Accepts: []x402.PaymentRequirement{{
+					Scheme:  "exact",
+					Network: "eip155:84532",
+					Amount:  "10000",
+					Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+					PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+				}},
+			}
+			jsonBytes, _ := json.Marshal(paymentReq)
+			w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(jsonBytes))
+			w.WriteHeader(http.StatusPaymentRequired)
+		case "/free":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	spec := `
+openapi: 3.1.0
+paths:
+  /paid:
+    post:
+      tags: [search]
+      requestBody:
+        content:
+          application/json:
+            example: {q: test}
+  /free:
+    get: {}
+  /broken:
+    get: {}
+  /items/{id}:
+    get: {}
+`
+	doc, err := openapi.Parse([]byte(spec))
+	require.NoError(t, err)
+
+	result := discoverOperations(context.Background(), doc.Requests(server.URL, nil), 5*time.Second, 2)
+
+	assert.Equal(t, 1, result.Gated)
+	assert.Equal(t, 1, result.Free)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+
+	ops := make(map[string]DiscoveredOperation)
+	for _, op := range result.Operations {
+		ops[op.Path] = op
+	}
+	assert.True(t, ops["/paid"].Gated)
+	assert.Equal(t, []string{"0.01 USDC on Base Sepolia"}, ops["/paid"].Prices)
+	assert.Equal(t, 200, ops["/free"].Status)
+	assert.Equal(t, "HTTP 500", ops["/broken"].Error)
+	assert.NotEmpty(t, ops["/items/{id}"].Skipped)
+
+	t.Run("output is batch input", func(t *testing.T) {
+		for _, name := range []string{"endpoints.yaml", "endpoints.json"} {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, writeDiscoveredEntries(path, result.Operations))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			entries, err := loadBatchFile(path, data)
+			require.NoError(t, err)
+
+			require.Len(t, entries, 1, name)
+			assert.Equal(t, server.URL+"/paid", entries[0].URL)
+			assert.Equal(t, "POST", entries[0].Method)
+			assert.Equal(t, `{"q":"test"}`, entries[0].Body)
+			assert.Equal(t, "application/json", entries[0].Headers["Content-Type"])
+			assert.Equal(t, []string{"search"}, entries[0].Tags)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+var exitCodesCmd = &cobra.Command{
+	Use:   "exit-codes",
+	Short: "List process exit codes",
+	Long: `List the exit codes every command uses, and whether running the
+command again may succeed. Codes are stable across releases.
+
+Examples:
+  x402 exit-codes
+  x402 exit-codes --json`,
+	Args: cobra.NoArgs,
+	RunE: runExitCodes,
+}
+
+func init() {
+	rootCmd.AddCommand(exitCodesCmd)
+}
+
+func runExitCodes(cmd *cobra.Command, args []string) error {
+	if GetJSONOutput() {
+		return output.PrintJSON(exitcode.All)
+	}
+
+	fmt.Println("Exit Codes")
+	fmt.Println()
+	for _, info := range exitcode.All {
+		retry := ""
+		if info.Retryable {
+			retry = "  (retryable)"
+		}
+		fmt.Printf("  %-4d %-20s %s%s\n", info.Code, info.Name, info.Description, retry)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+)
+
+func TestClassifyUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "root", SilenceErrors: true, SilenceUsage: true}
+	sub := &cobra.Command{Use: "sub", Args: cobra.ExactArgs(1), RunE: func(*cobra.Command, []string) error { return nil }}
+	sub.Flags().Int("count", 0, "")
+	root.AddCommand(sub)
+	classifyUsageErrors(root)
+
+	root.SetArgs([]string{"sub"})
+	assert.Equal(t, exitcode.Input, exitcode.Of(root.Execute()))
+
+	root.SetArgs([]string{"sub", "x", "--count", "many"})
+	assert.Equal(t, exitcode.Input, exitcode.Of(root.Execute()))
+
+	root.SetArgs([]string{"sub", "x"})
+	assert.NoError(t, root.Execute())
+}
+
+func TestPrintError(t *testing.T) {
+	var buf bytes.Buffer
+	printError(&buf, errCancelled)
+	assert.Equal(t, "cancelled\n", buf.String())
+
+	// A cancellation the command already reported is not printed again,
+	// but still exits 130
+	buf.Reset()
+	printError(&buf, fmt.Errorf("payment: %w", errCancelReported))
+	assert.Empty(t, buf.String())
+	assert.Equal(t, exitcode.Cancelled, exitcode.Of(errCancelReported))
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/exporter"
+)
+
+// Exporter command flags
+var (
+	exporterConfig   string
+	exporterListen   string
+	exporterInterval time.Duration
+	exporterTimeout  int
+	exporterParallel int
+	exporterAgent    bool
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Expose endpoint health as Prometheus metrics",
+	Long: `Periodically health check the endpoints in a config file and serve the
+results as Prometheus metrics on /metrics.
+
+The config file uses any format batch-health accepts (JSON, YAML, CSV, text).
+
+Metrics (labelled with url and method):
+  x402_endpoint_up                            Endpoint was reachable
+  x402_endpoint_returns_402                   Endpoint returned 402
+  x402_endpoint_healthy                       All health checks passed
+  x402_endpoint_latency_seconds               Latency histogram
+  x402_endpoint_payment_options               Number of payment options
+  x402_endpoint_price                         Price in token units (per network, asset, token)
+  x402_endpoint_agent_card                    A2A agent card discovered
+  x402_endpoint_last_check_timestamp_seconds  Time of the last check
+
+Examples:
+  x402 exporter --config endpoints.json
+  x402 exporter --config endpoints.json --listen :9402 --interval 30s
+  x402 exporter --config endpoints.json --agent=false`,
+	Args: cobra.NoArgs,
+	RunE: runExporter,
+}
+
+func init() {
+	exporterCmd.Flags().StringVar(&exporterConfig, "config", "", "JSON file listing endpoints to check (required)")
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", ":9402", "Address to serve metrics on")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", 60*time.Second, "Time between check rounds")
+	exporterCmd.Flags().IntVar(&exporterTimeout, "timeout", 30, "Request timeout in seconds")
+	exporterCmd.Flags().IntVar(&exporterParallel, "parallel", 1, "Number of parallel checks")
+	exporterCmd.Flags().BoolVar(&exporterAgent, "agent", true, "Also discover A2A agent cards")
+	exporterCmd.MarkFlagRequired("config")
+
+	rootCmd.AddCommand(exporterCmd)
+}
+
+func runExporter(cmd *cobra.Command, args []string) error {
+	if exporterInterval <= 0 {
+		return exitcode.Errorf(exitcode.Input, "--interval must be positive")
+	}
+
+	data, err := os.ReadFile(exporterConfig)
+	if err != nil {
+		return exitcode.Errorf(exitcode.Input, "failed to read config: %w", err)
+	}
+
+	entries, err := loadBatchFile(exporterConfig, data)
+	if err != nil {
+		return exitcode.New(exitcode.Input, err)
+	}
+	if len(entries) == 0 {
+		return exitcode.Errorf(exitcode.Input, "no URLs in config")
+	}
+
+	listener, err := net.Listen("tcp", exporterListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", exporterListen, err)
+	}
+
+	exp := exporter.New()
+	server := &http.Server{
+		Handler:           exp.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := exporterProbeConfig{
+		interval: exporterInterval,
+		timeout:  time.Duration(exporterTimeout) * time.Second,
+		parallel: exporterParallel,
+		agent:    exporterAgent,
+	}
+	go runExporterProbes(ctx, entries, cfg, exp, 0)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Exporter checking %d endpoint(s) every %s, metrics on http://%s/metrics (Ctrl+C to stop)\n",
+		len(entries), cfg.interval, listener.Addr())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("exporter server: %w", err)
+	}
+	return nil
+}
+
+// exporterProbeConfig holds the settings for the exporter's check rounds.
+type exporterProbeConfig struct {
+	interval time.Duration
+	timeout  time.Duration
+	parallel int
+	agent    bool
+}
+
+// runExporterProbes checks every entry, feeds the results to exp and repeats
+// until ctx is cancelled or, if rounds > 0, that many rounds have completed.
+func runExporterProbes(ctx context.Context, entries []BatchEntry, cfg exporterProbeConfig, exp *exporter.Exporter, rounds int) {
+	for round := 1; ; round++ {
+		results := checkRound(ctx, entries, cfg.timeout, cfg.parallel)
+		if ctx.Err() != nil {
+			return // Don't report interrupted checks as failures
+		}
+
+		for i := range results {
+			if cfg.agent && results[i].Status != 0 {
+				results[i].AgentCard = a2a.Discover(ctx, results[i].URL, "", cfg.timeout)
+			}
+			exp.Observe(&results[i], time.Now())
+		}
+
+		if rounds > 0 && round >= rounds {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.interval):
+		}
+	}
+}
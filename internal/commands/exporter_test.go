@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/exporter"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestRunExporterProbes(t *testing.T) {
+	paymentReq := &x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:  "exact",
+			Network: "eip155:84532",
+			Amount:  "1000000",
+			Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+	paid := createMock402Server(t, x402.ProtocolV2, paymentReq)
+	defer paid.Close()
+
+	free := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer free.Close()
+
+	exp := exporter.New()
+	entries := []BatchEntry{{URL: paid.URL, Method: "GET"}, {URL: free.URL, Method: "GET"}}
+	cfg := exporterProbeConfig{interval: time.Millisecond, timeout: 5 * time.Second, parallel: 2, agent: true}
+	runExporterProbes(context.Background(), entries, cfg, exp, 1)
+
+	metrics := httptest.NewServer(exp.Handler())
+	defer metrics.Close()
+
+	resp, err := http.Get(metrics.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	text := string(body)
+
+	paidLabels := `url="` + paid.URL + `",method="GET"`
+	freeLabels := `url="` + free.URL + `",method="GET"`
+
+	assert.Contains(t, text, "x402_endpoint_returns_402{"+paidLabels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_returns_402{"+freeLabels+"} 0\n")
+	assert.Contains(t, text, "x402_endpoint_up{"+freeLabels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_payment_options{"+paidLabels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_price{"+paidLabels+`,network="eip155:84532",asset="0x036cbd53842c5426634e7929541ec2318f3dcf7e",token="USDC"} 1`+"\n")
+	assert.Contains(t, text, "x402_endpoint_agent_card{"+paidLabels+"} 0\n")
+	assert.Contains(t, text, "x402_endpoint_latency_seconds_count{"+paidLabels+"} 1\n")
+}
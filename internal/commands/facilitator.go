@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/facilitator"
+)
+
+var facilitatorListen string
+
+var facilitatorCmd = &cobra.Command{
+	Use:   "facilitator",
+	Short: "Run a local x402 facilitator for offline testing",
+	Long: `Local stand-in for an x402 facilitator.
+
+Point a resource server under development at the local facilitator and
+exercise it with "x402 test" completely offline.`,
+}
+
+var facilitatorServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the facilitator API locally",
+	Long: `Serve the standard facilitator API on a local address.
+
+Endpoints:
+  POST /verify     Verify a payment offline (EIP-712 recovery for EVM,
+                   transaction and signature checks for Solana)
+  POST /settle     Verify, then simulate settlement with a fake tx hash
+  GET  /supported  List supported scheme/network pairs
+
+Nothing is broadcast on-chain: balances are not checked and no funds move.
+Each authorization nonce (EVM) or transaction (Solana) settles only once.
+
+Examples:
+  x402 facilitator serve
+  x402 facilitator serve --listen :4020 --verbose`,
+	Args: cobra.NoArgs,
+	RunE: runFacilitatorServe,
+}
+
+func init() {
+	facilitatorServeCmd.Flags().StringVar(&facilitatorListen, "listen", "127.0.0.1:4020", "Address to listen on")
+	facilitatorCmd.AddCommand(facilitatorServeCmd)
+	rootCmd.AddCommand(facilitatorCmd)
+}
+
+func runFacilitatorServe(cmd *cobra.Command, args []string) error {
+	var opts []facilitator.Option
+	if GetVerbose() {
+		opts = append(opts, facilitator.WithLog(os.Stderr))
+	}
+
+	listener, err := net.Listen("tcp", facilitatorListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", facilitatorListen, err)
+	}
+
+	server := &http.Server{
+		Handler:           facilitator.New(opts...).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Execute cancels the command's context on Ctrl+C or SIGTERM
+	go func() {
+		<-cmd.Context().Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Facilitator listening on http://%s (Ctrl+C to stop)\n", listener.Addr())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("facilitator server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/port402/x402-cli/internal/har"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// newHARRecorder returns a HAR recorder for --har, or nil when no file was requested.
+func newHARRecorder(path string, redact bool) *har.Recorder {
+	if path == "" {
+		return nil
+	}
+	opts := []har.Option{har.WithCreator("x402-cli", Version)}
+	if redact {
+		opts = append(opts, har.WithRedaction())
+	}
+	return har.NewRecorder(opts...)
+}
+
+// writeHARFile writes the recorded exchanges. Failures are reported as
+// warnings so they never mask the command's own result.
+func writeHARFile(rec *har.Recorder, path string) {
+	if rec == nil {
+		return
+	}
+	if err := rec.WriteFile(path); err != nil {
+		output.PrintWarning(err.Error())
+		return
+	}
+	if GetVerbose() && !GetJSONOutput() {
+		fmt.Fprintf(os.Stderr, "• HAR written to %s\n", path)
+	}
+}
@@ -1,26 +1,60 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/baseline"
 	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
 	"github.com/port402/x402-cli/internal/output"
 	"github.com/port402/x402-cli/internal/tokens"
 	"github.com/port402/x402-cli/internal/x402"
 )
 
 var (
-	healthTimeout int
-	healthMethod  string
-	healthAgent   bool
+	healthTimeout  int
+	healthMethod   string
+	healthAgent    bool
+	healthCompat   bool
+	healthStrict   bool
+	healthHAR      string
+	healthRedact   bool
+	healthRecord   string
+	healthReplay   string
+	healthNetwork  client.TransportConfig
+	healthBaseline string
+	healthFormat   string
+	healthRetries  int
 )
 
+// maxDrainBytes caps how much of an unread response body is read to time
+// its transfer.
+const maxDrainBytes = 1 << 20
+
+// healthOptions holds optional checks enabled for a single health run.
+type healthOptions struct {
+	compat bool // Check that both v1 and v2 clients can read the 402
+	strict bool // Validate the 402 payload against the protocol JSON schema
+
+	baseline *baseline.File // Pinned payment options to detect drift against (--baseline)
+
+	headers map[string]string // Extra request headers (batch entries)
+	body    []byte            // Request body (batch entries)
+
+	recorder  client.Recorder    // Captures the exchange (--har)
+	transport http.RoundTripper  // Records or replays traffic (--record/--replay)
+	retry     client.RetryPolicy // Retries transient failures (--retries)
+}
+
 var healthCmd = &cobra.Command{
 	Use:   "health <url>",
 	Short: "Check if an endpoint is x402-enabled",
@@ -35,12 +69,52 @@ Validates:
 
 Use --agent to also discover A2A agent cards from the endpoint.
 
+Use --compat to check that both v1 clients (JSON body) and v2 clients
+(PAYMENT-REQUIRED header) can read the payment requirements, e.g. while
+migrating a server from v1 to v2.
+
+Use --strict to validate the payment requirements against the JSON schema
+for the detected protocol version. Missing fields, wrongly-typed values
+and unknown fields are reported with JSON pointer paths.
+
+Use --har to save the request and response in HAR 1.2 format, with payment
+headers decoded into comments. Add --redact to strip signatures.
+
+Use --record <dir> to save every exchange to a cassette directory, and
+--replay <dir> to serve them back later without network access.
+
+Use --format junit|tap|sarif|markdown to report the endpoint as a test case
+with one assertion per check, e.g. for CI test reporting.
+
+Use --baseline <file> to compare each payment option's network, asset,
+amount and payTo against options pinned with "x402 pin". Any drift fails
+the check with exit code 6.
+
+Use --verbose to break the latency down into DNS, connect, TLS, server,
+time to first byte and transfer times (always included in JSON output).
+
+Use --proxy, --cacert, --cert/--key, --insecure and --resolve to reach
+endpoints behind a proxy, with a private CA or mutual TLS, or at another
+address.
+
+Use --retries to retry connection errors and 408, 429 and 5xx responses
+with exponential backoff, honoring Retry-After.
+
 Examples:
   x402 health https://api.example.com/endpoint
   x402 health https://api.example.com/endpoint --json
   x402 health https://api.example.com/endpoint --verbose
   x402 health https://api.example.com/endpoint --method POST
-  x402 health https://api.example.com/endpoint --agent`,
+  x402 health https://api.example.com/endpoint --agent
+  x402 health https://api.example.com/endpoint --compat
+  x402 health https://api.example.com/endpoint --strict
+  x402 health https://api.example.com/endpoint --har exchange.har --redact
+  x402 health https://api.example.com/endpoint --record testdata/cassette
+  x402 health https://api.example.com/endpoint --replay testdata/cassette
+  x402 health https://api.example.com/endpoint --baseline pinned.json
+  x402 health https://api.example.com/endpoint --retries 3
+  x402 health https://api.example.com/endpoint --format junit > x402.xml
+  x402 health https://api.example.com/endpoint --proxy http://proxy.corp:3128 --cacert corp-ca.pem`,
 	Args: cobra.ExactArgs(1),
 	RunE: runHealth,
 }
@@ -49,40 +123,106 @@ func init() {
 	healthCmd.Flags().IntVar(&healthTimeout, "timeout", 30, "Request timeout in seconds")
 	healthCmd.Flags().StringVarP(&healthMethod, "method", "X", "GET", "HTTP method")
 	healthCmd.Flags().BoolVar(&healthAgent, "agent", false, "Also discover A2A agent card")
+	healthCmd.Flags().BoolVar(&healthCompat, "compat", false, "Check that both v1 and v2 clients can read the 402 response")
+	healthCmd.Flags().BoolVar(&healthStrict, "strict", false, "Validate the 402 payload against the protocol JSON schema")
+	healthCmd.Flags().StringVar(&healthHAR, "har", "", "Write the HTTP exchange to a HAR file")
+	healthCmd.Flags().BoolVar(&healthRedact, "redact", false, "Redact signatures in the HAR file")
+	healthCmd.Flags().StringVar(&healthFormat, "format", "", "Output format: text, json, junit, tap, sarif, markdown")
+	healthCmd.Flags().StringVar(&healthBaseline, "baseline", "", "Fail if payment options drift from this pinned baseline file")
+	healthCmd.Flags().IntVar(&healthRetries, "retries", 0, "Retry connection errors, 408, 429 and 5xx responses this many times")
+	addCassetteFlags(healthCmd, &healthRecord, &healthReplay)
+	addNetworkFlags(healthCmd, &healthNetwork)
 	rootCmd.AddCommand(healthCmd)
 }
 
 func runHealth(cmd *cobra.Command, args []string) error {
 	endpoint, err := normalizeURL(args[0])
 	if err != nil {
-		return fmt.Errorf("normalizing endpoint URL: %w", err)
+		return err
 	}
 	timeout := time.Duration(healthTimeout) * time.Second
 
-	result := checkHealth(endpoint, timeout, healthMethod)
+	format, err := resolveFormat(healthFormat)
+	if err != nil {
+		return err
+	}
+
+	network, err := networkTransport(healthNetwork)
+	if err != nil {
+		return err
+	}
+	transport, err := cassetteTransport(healthRecord, healthReplay, network)
+	if err != nil {
+		return err
+	}
+
+	opts := healthOptions{
+		compat:    healthCompat,
+		strict:    healthStrict,
+		transport: transport,
+		retry:     client.RetryPolicy{MaxRetries: healthRetries},
+	}
+	if healthBaseline != "" {
+		if opts.baseline, err = baseline.Load(healthBaseline); err != nil {
+			return exitcode.New(exitcode.Input, err)
+		}
+	}
+	recorder := newHARRecorder(healthHAR, healthRedact)
+	if recorder != nil {
+		opts.recorder = recorder
+	}
+
+	result := checkHealth(cmd.Context(), endpoint, timeout, healthMethod, opts)
+	writeHARFile(recorder, healthHAR)
 
 	// Optionally discover agent card
 	var agentResult *a2a.Result
 	if healthAgent {
-		agentResult = a2a.Discover(cmd.Context(), endpoint, "", timeout)
+		agentResult = a2a.Discover(cmd.Context(), endpoint, "", timeout, a2a.WithTransport(transport))
 		result.AgentCard = agentResult
 	}
 
-	if GetJSONOutput() {
-		return output.PrintJSON(result)
+	if err := printHealthReport(result, format); err != nil {
+		return err
 	}
 
-	output.PrintHealthResult(result, GetVerbose())
-
+	// Exit with the result's code in every output format
 	if result.ExitCode != 0 {
 		cmd.SilenceUsage = true
-		return fmt.Errorf("health check failed")
+		if result.ExitCode == int(exitcode.BaselineDrift) {
+			return exitcode.Errorf(exitcode.BaselineDrift, "payment options drifted from baseline")
+		}
+		return exitcode.Errorf(exitcode.Code(result.ExitCode), "health check failed")
 	}
 
 	return nil
 }
 
-func checkHealth(url string, timeout time.Duration, method string) *output.HealthResult {
+// printHealthReport prints a single health result in the requested format.
+// Report formats treat it as a batch of one.
+func printHealthReport(result *output.HealthResult, format output.ReportFormat) error {
+	switch format {
+	case output.ReportText:
+		output.PrintHealthResult(result, GetVerbose())
+		return nil
+	case output.ReportJSON:
+		return output.PrintJSON(result)
+	default:
+		batch := output.NewBatchHealthResult([]output.HealthResult{*result}, result.LatencyMs)
+		return output.WriteReport(os.Stdout, format, batch, output.WithToolVersion(Version))
+	}
+}
+
+// statusExitCode classifies an unexpected status: timeouts, rate limits and
+// server errors are transient, so they share the retryable network code.
+func statusExitCode(status int) exitcode.Code {
+	if status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500 {
+		return exitcode.Network
+	}
+	return exitcode.General
+}
+
+func checkHealth(ctx context.Context, url string, timeout time.Duration, method string, opts healthOptions) *output.HealthResult {
 	result := &output.HealthResult{
 		URL:      url,
 		Method:   method,
@@ -91,10 +231,25 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 	}
 
 	// Create HTTP client
-	httpClient := client.New(client.WithTimeout(timeout))
+	httpClient := client.New(
+		client.WithTimeout(timeout),
+		client.WithTransport(opts.transport),
+		client.WithRecorder(opts.recorder),
+		client.WithRetry(opts.retry),
+	)
 
 	// Make request and measure latency
-	reqResult, err := httpClient.TimedRequest(method, url, nil, nil)
+	reqResult, err := httpClient.TimedRequest(ctx, method, url, opts.headers, opts.body)
+	if err != nil && ctx.Err() != nil {
+		result.Checks = append(result.Checks, output.Check{
+			Name:    "Endpoint reachable",
+			Status:  output.StatusFail,
+			Message: "Cancelled before a response arrived",
+		})
+		result.Error = "cancelled"
+		result.ExitCode = int(exitcode.Cancelled)
+		return result
+	}
 	if err != nil {
 		result.Checks = append(result.Checks, output.Check{
 			Name:    "Endpoint reachable",
@@ -102,10 +257,15 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 			Message: fmt.Sprintf("Connection failed: %v", err),
 		})
 		result.Error = err.Error()
-		result.ExitCode = 3 // Network error
+		result.ExitCode = int(exitcode.Network)
 		return result
 	}
-	defer reqResult.Response.Body.Close()
+	defer func() {
+		// Finish reading the body so the transfer phase is timed
+		_, _ = io.Copy(io.Discard, io.LimitReader(reqResult.Response.Body, maxDrainBytes))
+		reqResult.Response.Body.Close()
+		result.Timing = newTiming(reqResult.Timings())
+	}()
 
 	result.Latency = reqResult.LatencyMs
 	result.LatencyMs = reqResult.LatencyMs
@@ -113,10 +273,15 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 	result.StatusText = reqResult.Response.Status
 
 	// Check 1: Endpoint reachable
+	connected := fmt.Sprintf("Connected in %dms", result.LatencyMs)
+	if reqResult.Attempts > 1 {
+		result.Attempts = reqResult.Attempts
+		connected += fmt.Sprintf(" after %d attempts", reqResult.Attempts)
+	}
 	result.Checks = append(result.Checks, output.Check{
 		Name:    "Endpoint reachable",
 		Status:  output.StatusPass,
-		Message: fmt.Sprintf("Connected in %dms", result.LatencyMs),
+		Message: connected,
 	})
 
 	// Check 2: Returns 402
@@ -133,6 +298,8 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 			Message: fmt.Sprintf("Got %d - endpoint may not require payment", reqResult.Response.StatusCode),
 		})
 		result.Protocol = "none"
+		// A pinned endpoint that stopped charging has drifted to no options
+		applyBaseline(result, opts.baseline, method, url)
 		return result
 	} else if reqResult.Response.StatusCode == http.StatusTooManyRequests {
 		retryAfter := client.ParseRetryAfter(reqResult.Response)
@@ -145,7 +312,7 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 			Status:  output.StatusFail,
 			Message: msg,
 		})
-		result.ExitCode = 1
+		result.ExitCode = int(exitcode.Network)
 		return result
 	} else {
 		result.Checks = append(result.Checks, output.Check{
@@ -153,7 +320,7 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 			Status:  output.StatusFail,
 			Message: fmt.Sprintf("Got %d instead of 402", reqResult.Response.StatusCode),
 		})
-		result.ExitCode = 1
+		result.ExitCode = int(statusExitCode(reqResult.Response.StatusCode))
 		return result
 	}
 
@@ -161,7 +328,7 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 	parseResult, err := x402.ParsePaymentRequired(reqResult.Response)
 	if err != nil {
 		checkName := "Valid payment header"
-		if reqResult.Response.Header.Get(x402.HeaderPaymentRequired) == "" {
+		if x402.DetectProtocol(reqResult.Response.Header).RequirementsHeader == "" {
 			checkName = "Valid payment body"
 		}
 		result.Checks = append(result.Checks, output.Check{
@@ -169,20 +336,20 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 			Status:  output.StatusFail,
 			Message: err.Error(),
 		})
-		result.ExitCode = 4 // Protocol error
+		result.ExitCode = int(exitcode.Protocol)
 		return result
 	}
 
 	// Set protocol version
-	if parseResult.ProtocolVersion == x402.ProtocolV2 {
-		result.Protocol = "v2"
+	protocol, _ := x402.LookupProtocol(parseResult.ProtocolVersion)
+	result.Protocol = protocol.String()
+	if protocol.RequirementsHeader != "" {
 		result.Checks = append(result.Checks, output.Check{
 			Name:    "Valid payment header",
 			Status:  output.StatusPass,
-			Message: "PAYMENT-REQUIRED header decoded successfully",
+			Message: fmt.Sprintf("%s header decoded successfully", strings.ToUpper(protocol.RequirementsHeader)),
 		})
 	} else {
-		result.Protocol = "v1"
 		result.Checks = append(result.Checks, output.Check{
 			Name:    "Valid payment body",
 			Status:  output.StatusPass,
@@ -190,6 +357,34 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 		})
 	}
 
+	// Declared x402Version should agree with how the requirements were delivered
+	if parseResult.VersionMismatch() {
+		result.Checks = append(result.Checks, output.Check{
+			Name:   "Version consistent",
+			Status: output.StatusWarn,
+			Message: fmt.Sprintf("Delivered as %s but declares x402Version %d",
+				protocol, parseResult.DeclaredVersion),
+		})
+	}
+
+	// Optional: v1/v2 client compatibility
+	compatFailed := false
+	if opts.compat {
+		checks, supported := checkProtocolCompat(reqResult.Response.Header, parseResult.RawBody)
+		result.Checks = append(result.Checks, checks...)
+		result.SupportedProtocols = supported
+		compatFailed = len(supported) < len(checks)
+	}
+
+	// Optional: strict schema validation
+	strictFailed := false
+	if opts.strict {
+		check, violations := checkStrictSchema(parseResult)
+		result.Checks = append(result.Checks, check)
+		result.SchemaViolations = violations
+		strictFailed = check.Status == output.StatusFail
+	}
+
 	// Check 4: Has payment options
 	result.Checks = append(result.Checks, output.Check{
 		Name:    "Has payment options",
@@ -276,9 +471,159 @@ func checkHealth(url string, timeout time.Duration, method string) *output.Healt
 		})
 	}
 
+	if compatFailed || strictFailed {
+		result.ExitCode = int(exitcode.Protocol)
+	}
+
+	// Optional: drift from pinned baseline
+	applyBaseline(result, opts.baseline, method, url)
+
 	return result
 }
 
+// applyBaseline compares result's payment options with the pinned baseline,
+// if any. An earlier failure, e.g. a protocol error, keeps its exit code;
+// the baseline check is still reported.
+func applyBaseline(result *output.HealthResult, pinned *baseline.File, method, url string) {
+	if pinned == nil {
+		return
+	}
+	check, drift, exitCode := checkBaseline(pinned, method, url, result.PaymentOptions)
+	result.Checks = append(result.Checks, check)
+	result.BaselineDrift = drift
+	if result.ExitCode == 0 {
+		result.ExitCode = exitCode
+	}
+}
+
+// checkProtocolCompat reports whether clients of each supported protocol
+// version can read the payment requirements from the same 402 response.
+// v1 clients read the JSON body and the maxAmountRequired field; v2 clients
+// decode the PAYMENT-REQUIRED header and read the amount field.
+func checkProtocolCompat(header http.Header, body []byte) ([]output.Check, []string) {
+	var checks []output.Check
+	var supported []string
+
+	for _, protocol := range x402.Protocols() {
+		name := fmt.Sprintf("%s client compatible", protocol)
+
+		pr, err := x402.ParsePaymentRequiredAs(header, body, protocol.Version)
+		if err != nil {
+			checks = append(checks, output.Check{
+				Name:    name,
+				Status:  output.StatusFail,
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		missing := 0
+		for i := range pr.Accepts {
+			if protocol.Amount(&pr.Accepts[i]) == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			checks = append(checks, output.Check{
+				Name:    name,
+				Status:  output.StatusFail,
+				Message: fmt.Sprintf("%d payment option(s) missing %q", missing, protocol.AmountField),
+			})
+			continue
+		}
+
+		source := "JSON body"
+		if protocol.RequirementsHeader != "" {
+			source = strings.ToUpper(protocol.RequirementsHeader) + " header"
+		}
+		checks = append(checks, output.Check{
+			Name:    name,
+			Status:  output.StatusPass,
+			Message: fmt.Sprintf("%d payment option(s) readable from %s", len(pr.Accepts), source),
+		})
+		supported = append(supported, protocol.String())
+	}
+
+	return checks, supported
+}
+
+// checkStrictSchema validates the raw payment requirements against the
+// embedded JSON schema for the detected protocol version.
+func checkStrictSchema(parseResult *x402.ParseResult) (output.Check, []x402.Violation) {
+	const name = "Strict schema"
+
+	raw, err := parseResult.RawJSON()
+	if err != nil {
+		return output.Check{Name: name, Status: output.StatusFail, Message: err.Error()}, nil
+	}
+
+	violations, err := x402.ValidatePaymentRequired(raw, parseResult.ProtocolVersion)
+	if err != nil {
+		return output.Check{Name: name, Status: output.StatusFail, Message: err.Error()}, nil
+	}
+
+	if len(violations) > 0 {
+		return output.Check{
+			Name:    name,
+			Status:  output.StatusFail,
+			Message: fmt.Sprintf("%d schema violation(s), first: %s", len(violations), violations[0]),
+		}, violations
+	}
+
+	return output.Check{
+		Name:    name,
+		Status:  output.StatusPass,
+		Message: fmt.Sprintf("Matches x402 v%d PaymentRequired schema", parseResult.ProtocolVersion),
+	}, nil
+}
+
+// checkBaseline compares the advertised payment options with the ones pinned
+// for this endpoint and returns the exit code for the result: Input if the
+// endpoint is not pinned, BaselineDrift if the options differ. applyBaseline
+// only uses the code if no earlier check failed.
+func checkBaseline(pinned *baseline.File, method, url string, options []output.PaymentOptionDisplay) (output.Check, []baseline.Drift, int) {
+	const name = "Matches baseline"
+
+	endpoint := pinned.Lookup(method, url)
+	if endpoint == nil {
+		return output.Check{
+			Name:    name,
+			Status:  output.StatusFail,
+			Message: fmt.Sprintf("No baseline pinned for %s %s", strings.ToUpper(method), url),
+		}, nil, int(exitcode.Input)
+	}
+
+	drift := baseline.Compare(endpoint.Options, baselineOptions(options))
+	if len(drift) > 0 {
+		return output.Check{
+			Name:    name,
+			Status:  output.StatusFail,
+			Message: fmt.Sprintf("%d difference(s), first: %s", len(drift), drift[0]),
+		}, drift, int(exitcode.BaselineDrift)
+	}
+
+	return output.Check{
+		Name:    name,
+		Status:  output.StatusPass,
+		Message: fmt.Sprintf("%d option(s) match baseline pinned %s", len(endpoint.Options), endpoint.PinnedAt.Format("2006-01-02")),
+	}, nil, 0
+}
+
+// baselineOptions converts displayed payment options to pinnable options.
+func baselineOptions(options []output.PaymentOptionDisplay) []baseline.Option {
+	pinned := make([]baseline.Option, len(options))
+	for i, opt := range options {
+		pinned[i] = baseline.Option{
+			Scheme:  opt.Scheme,
+			Network: opt.Network,
+			Asset:   opt.Asset,
+			Amount:  opt.Amount,
+			PayTo:   opt.PayTo,
+		}
+	}
+	return pinned
+}
+
 // CheckHealthForBatch is exported for use by batch-health command.
 // Always uses GET method for batch operations (backward compatible).
 func CheckHealthForBatch(url string, timeout time.Duration) *output.HealthResult {
@@ -288,11 +633,16 @@ func CheckHealthForBatch(url string, timeout time.Duration) *output.HealthResult
 // CheckHealthForBatchWithMethod is exported for use by batch-health command.
 // Allows specifying the HTTP method.
 func CheckHealthForBatchWithMethod(rawURL string, method string, timeout time.Duration) *output.HealthResult {
+	return checkHealthForBatch(context.Background(), rawURL, method, timeout, healthOptions{})
+}
+
+// checkHealthForBatch normalizes a batch entry and runs checkHealth with opts.
+func checkHealthForBatch(ctx context.Context, rawURL string, method string, timeout time.Duration, opts healthOptions) *output.HealthResult {
 	normalized, err := normalizeURL(rawURL)
 	if err != nil {
 		return &output.HealthResult{
 			URL:      rawURL,
-			ExitCode: 3,
+			ExitCode: int(exitcode.Input),
 			Error:    err.Error(),
 			Checks: []output.Check{{
 				Name:    "Endpoint reachable",
@@ -304,5 +654,18 @@ func CheckHealthForBatchWithMethod(rawURL string, method string, timeout time.Du
 	if method == "" {
 		method = "GET"
 	}
-	return checkHealth(normalized, timeout, strings.ToUpper(method))
+	return checkHealth(ctx, normalized, timeout, strings.ToUpper(method), opts)
+}
+
+// newTiming converts client phase timings for output.
+func newTiming(t client.Timings) *output.Timing {
+	return &output.Timing{
+		DNSMs:      t.DNS.Milliseconds(),
+		ConnectMs:  t.Connect.Milliseconds(),
+		TLSMs:      t.TLS.Milliseconds(),
+		ServerMs:   t.Server.Milliseconds(),
+		TTFBMs:     t.TTFB.Milliseconds(),
+		TransferMs: t.Transfer.Milliseconds(),
+		Reused:     t.Reused,
+	}
 }
@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,6 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/baseline"
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
 	"github.com/port402/x402-cli/internal/output"
 	"github.com/port402/x402-cli/internal/x402"
 )
@@ -124,6 +129,19 @@ func TestCheckHealthForBatch_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, result.Status)
 }
 
+func TestStatusExitCode(t *testing.T) {
+	for status, want := range map[int]exitcode.Code{
+		http.StatusRequestTimeout:      exitcode.Network,
+		http.StatusTooManyRequests:     exitcode.Network,
+		http.StatusInternalServerError: exitcode.Network,
+		http.StatusServiceUnavailable:  exitcode.Network,
+		http.StatusNotFound:            exitcode.General,
+		http.StatusForbidden:           exitcode.General,
+	} {
+		assert.Equal(t, want, statusExitCode(status), "status %d", status)
+	}
+}
+
 func TestCheckHealthForBatch_RateLimited(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Retry-After", "60")
@@ -133,7 +151,7 @@ func TestCheckHealthForBatch_RateLimited(t *testing.T) {
 
 	result := CheckHealthForBatch(server.URL, 30*time.Second)
 
-	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, int(exitcode.Network), result.ExitCode, "rate limits are retryable")
 	assert.Equal(t, http.StatusTooManyRequests, result.Status)
 
 	// Should mention retry-after in check message
@@ -366,3 +384,427 @@ func TestHealthResult_AgentCardField(t *testing.T) {
 		assert.Nil(t, result.AgentCard)
 	})
 }
+
+func TestCheckHealth_Compat(t *testing.T) {
+	v2Req := &x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:  "exact",
+			Network: "eip155:84532",
+			Amount:  "1000",
+			Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+	v1Req := &x402.PaymentRequired{
+		X402Version: 1,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:            "exact",
+			Network:           "base-sepolia",
+			MaxAmountRequired: "1000",
+			Asset:             "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:             "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+
+	checkStatus := func(result *output.HealthResult, name string) output.CheckStatus {
+		for _, c := range result.Checks {
+			if c.Name == name {
+				return c.Status
+			}
+		}
+		return ""
+	}
+
+	t.Run("serves both", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			jsonBytes, err := json.Marshal(v2Req)
+			require.NoError(t, err)
+			w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(jsonBytes))
+			w.WriteHeader(http.StatusPaymentRequired)
+			json.NewEncoder(w).Encode(v1Req)
+		}))
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{compat: true})
+
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, []string{"v1", "v2"}, result.SupportedProtocols)
+		assert.Equal(t, output.StatusPass, checkStatus(result, "v1 client compatible"))
+		assert.Equal(t, output.StatusPass, checkStatus(result, "v2 client compatible"))
+	})
+
+	t.Run("v2 only", func(t *testing.T) {
+		server := createMock402Server(t, x402.ProtocolV2, v2Req)
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{compat: true})
+
+		assert.Equal(t, 4, result.ExitCode)
+		assert.Equal(t, []string{"v2"}, result.SupportedProtocols)
+		assert.Equal(t, output.StatusFail, checkStatus(result, "v1 client compatible"))
+		assert.Len(t, result.PaymentOptions, 1, "options are still reported")
+	})
+
+	t.Run("v1 body with v2 amount field", func(t *testing.T) {
+		server := createMock402Server(t, x402.ProtocolV1, v2Req)
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{compat: true})
+
+		assert.Equal(t, 4, result.ExitCode)
+		assert.Empty(t, result.SupportedProtocols)
+		assert.Equal(t, output.StatusFail, checkStatus(result, "v1 client compatible"))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server := createMock402Server(t, x402.ProtocolV2, v2Req)
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{})
+
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Nil(t, result.SupportedProtocols)
+		assert.Empty(t, checkStatus(result, "v1 client compatible"))
+	})
+}
+
+func TestCheckHealth_Strict(t *testing.T) {
+	checkFor := func(result *output.HealthResult, name string) *output.Check {
+		for i := range result.Checks {
+			if result.Checks[i].Name == name {
+				return &result.Checks[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("valid v2", func(t *testing.T) {
+		paymentReq := &x402.PaymentRequired{
+			X402Version: 2,
+			Resource:    x402.ResourceInfo{URL: "https://example.com/api"},
+			Accepts: []x402.PaymentRequirement{{
+				Scheme:            "exact",
+				Network:           "eip155:84532",
+				Amount:            "1000",
+				Asset:             "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+				PayTo:             "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+				MaxTimeoutSeconds: 300,
+			}},
+		}
+		server := createMock402Server(t, x402.ProtocolV2, paymentReq)
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{strict: true})
+
+		assert.Equal(t, 0, result.ExitCode)
+		check := checkFor(result, "Strict schema")
+		require.NotNil(t, check)
+		assert.Equal(t, output.StatusPass, check.Status)
+		assert.Empty(t, result.SchemaViolations)
+	})
+
+	t.Run("v1 body with violations", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusPaymentRequired)
+			w.Write([]byte(`{"x402Version": 1, "accepts": [{"scheme": "exact", "network": "base-sepolia",
+				"maxAmountRequired": 1000, "resource": "https://example.com/api", "description": "",
+				"mimeType": "application/json", "maxTimeoutSeconds": 60,
+				"asset": "0x036cbd53842c5426634e7929541ec2318f3dcf7e"}]}`))
+		}))
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{strict: true})
+
+		// Lenient parsing fails on the numeric amount, so strict mode is never reached
+		assert.Equal(t, 4, result.ExitCode)
+		assert.Nil(t, checkFor(result, "Strict schema"))
+	})
+
+	t.Run("v1 body missing payTo", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusPaymentRequired)
+			w.Write([]byte(`{"x402Version": 1, "accepts": [{"scheme": "exact", "network": "base-sepolia",
+				"maxAmountRequired": "1000", "resource": "https://example.com/api", "description": "",
+				"mimeType": "application/json", "maxTimeoutSeconds": 60,
+				"asset": "0x036cbd53842c5426634e7929541ec2318f3dcf7e"}]}`))
+		}))
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{strict: true})
+
+		assert.Equal(t, 4, result.ExitCode)
+		check := checkFor(result, "Strict schema")
+		require.NotNil(t, check)
+		assert.Equal(t, output.StatusFail, check.Status)
+		assert.Equal(t, []x402.Violation{
+			{Pointer: "/accepts/0/payTo", Message: "required field missing"},
+		}, result.SchemaViolations)
+	})
+}
+
+func TestCheckHealth_VersionHandling(t *testing.T) {
+	newRequirements := func(version int) *x402.PaymentRequired {
+		return &x402.PaymentRequired{
+			X402Version: version,
+			Accepts: []x402.PaymentRequirement{{
+				Scheme:  "exact",
+				Network: "eip155:84532",
+				Amount:  "1000",
+				Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+				PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+			}},
+		}
+	}
+
+	t.Run("header declaring v1", func(t *testing.T) {
+		server := createMock402Server(t, x402.ProtocolV2, newRequirements(1))
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{})
+
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, "v2", result.Protocol)
+
+		var found bool
+		for _, c := range result.Checks {
+			if c.Name == "Version consistent" {
+				found = true
+				assert.Equal(t, output.StatusWarn, c.Status)
+				assert.Contains(t, c.Message, "declares x402Version 1")
+			}
+		}
+		assert.True(t, found, "expected version mismatch check")
+	})
+
+	t.Run("future version", func(t *testing.T) {
+		server := createMock402Server(t, x402.ProtocolV2, newRequirements(3))
+		defer server.Close()
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{})
+
+		assert.Equal(t, 4, result.ExitCode)
+		last := result.Checks[len(result.Checks)-1]
+		assert.Equal(t, "Valid payment header", last.Name)
+		assert.Equal(t, output.StatusFail, last.Status)
+		assert.Equal(t, "unsupported protocol version 3", last.Message)
+	})
+}
+
+func TestCheckHealth_HAR(t *testing.T) {
+	paymentReq := &x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:  "exact",
+			Network: "eip155:84532",
+			Amount:  "1000",
+			Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+	server := createMock402Server(t, x402.ProtocolV2, paymentReq)
+	defer server.Close()
+
+	recorder := newHARRecorder("out.har", false)
+	result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{recorder: recorder})
+
+	assert.Equal(t, 0, result.ExitCode)
+	entries := recorder.HAR().Log.Entries
+	require.Len(t, entries, 1)
+	assert.Equal(t, http.StatusPaymentRequired, entries[0].Response.Status)
+
+	var comment string
+	for _, h := range entries[0].Response.Headers {
+		if h.Name == "Payment-Required" {
+			comment = h.Comment
+		}
+	}
+	assert.Contains(t, comment, `"amount": "1000"`)
+}
+
+func TestCheckHealth_RecordReplay(t *testing.T) {
+	paymentReq := &x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:  "exact",
+			Network: "eip155:84532",
+			Amount:  "1000",
+			Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+	server := createMock402Server(t, x402.ProtocolV2, paymentReq)
+	dir := t.TempDir()
+
+	recorder, err := cassetteTransport(dir, "", nil)
+	require.NoError(t, err)
+	recorded := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{transport: recorder})
+	require.Equal(t, 0, recorded.ExitCode)
+
+	// Replay works with the server gone
+	server.Close()
+
+	replayer, err := cassetteTransport("", dir, nil)
+	require.NoError(t, err)
+	replayed := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{transport: replayer})
+
+	assert.Equal(t, 0, replayed.ExitCode)
+	assert.Equal(t, recorded.Protocol, replayed.Protocol)
+	assert.Equal(t, recorded.PaymentOptions, replayed.PaymentOptions)
+}
+
+func TestCheckHealth_Baseline(t *testing.T) {
+	paymentReq := &x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:  "exact",
+			Network: "eip155:84532",
+			Amount:  "1000",
+			Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+	server := createMock402Server(t, x402.ProtocolV2, paymentReq)
+	defer server.Close()
+
+	pinned, err := pinEndpoint(context.Background(), server.URL, "GET", 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, pinned.Options, 1)
+	assert.Equal(t, "1000", pinned.Options[0].Amount)
+
+	file := &baseline.File{Version: baseline.FileVersion}
+	file.Pin(*pinned)
+
+	t.Run("matches", func(t *testing.T) {
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "get", healthOptions{baseline: file})
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, output.StatusPass, result.Checks[len(result.Checks)-1].Status)
+		assert.Empty(t, result.BaselineDrift)
+	})
+
+	t.Run("payTo drift", func(t *testing.T) {
+		tampered := *file
+		tampered.Endpoints = []baseline.Endpoint{*pinned}
+		tampered.Endpoints[0].Options = []baseline.Option{pinned.Options[0]}
+		tampered.Endpoints[0].Options[0].PayTo = "0x0000000000000000000000000000000000000001"
+
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{baseline: &tampered})
+		assert.Equal(t, int(exitcode.BaselineDrift), result.ExitCode)
+		check := result.Checks[len(result.Checks)-1]
+		assert.Equal(t, "Matches baseline", check.Name)
+		assert.Equal(t, output.StatusFail, check.Status)
+		require.Len(t, result.BaselineDrift, 1)
+		assert.Equal(t, "payTo", result.BaselineDrift[0].Field)
+	})
+
+	t.Run("not pinned", func(t *testing.T) {
+		result := checkHealth(context.Background(), server.URL, 5*time.Second, "POST", healthOptions{baseline: file})
+		assert.Equal(t, 2, result.ExitCode)
+		assert.Contains(t, result.Checks[len(result.Checks)-1].Message, "No baseline pinned for POST")
+	})
+
+	t.Run("stopped charging", func(t *testing.T) {
+		free := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer free.Close()
+
+		moved := *pinned
+		moved.URL = free.URL
+		freeFile := &baseline.File{Version: baseline.FileVersion}
+		freeFile.Pin(moved)
+
+		result := checkHealth(context.Background(), free.URL, 5*time.Second, "GET", healthOptions{baseline: freeFile})
+		assert.Equal(t, int(exitcode.BaselineDrift), result.ExitCode)
+		require.Len(t, result.BaselineDrift, 1)
+		assert.Equal(t, "removed", result.BaselineDrift[0].Field)
+	})
+
+	t.Run("earlier failure keeps its code", func(t *testing.T) {
+		result := &output.HealthResult{ExitCode: int(exitcode.Protocol)}
+		applyBaseline(result, file, "POST", server.URL)
+		assert.Equal(t, int(exitcode.Protocol), result.ExitCode)
+		check := result.Checks[len(result.Checks)-1]
+		assert.Equal(t, "Matches baseline", check.Name)
+		assert.Equal(t, output.StatusFail, check.Status)
+	})
+}
+
+func TestRunHealth_MissingBaseline(t *testing.T) {
+	defer func(path string) { healthBaseline = path }(healthBaseline)
+	healthBaseline = filepath.Join(t.TempDir(), "missing.json")
+
+	err := runHealth(healthCmd, []string{"https://api.example.com"})
+	assert.ErrorContains(t, err, "failed to read baseline")
+	assert.Equal(t, exitcode.Input, exitcode.Of(err))
+}
+
+func TestCheckHealth_Retries(t *testing.T) {
+	paymentReq := &x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirement{{
+			Scheme:  "exact",
+			Network: "eip155:84532",
+			Amount:  "1000",
+			Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+			PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		}},
+	}
+	paid := createMock402Server(t, x402.ProtocolV2, paymentReq)
+	defer paid.Close()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		paid.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	retry := client.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}
+	result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{retry: retry})
+
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, http.StatusPaymentRequired, result.Status)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Contains(t, result.Checks[0].Message, "after 2 attempts")
+}
+
+func TestCheckHealth_Timing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{})
+
+	require.NotNil(t, result.Timing)
+	assert.GreaterOrEqual(t, result.Timing.ServerMs, int64(20))
+	assert.GreaterOrEqual(t, result.Timing.TTFBMs, result.Timing.ServerMs)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"timing":{"dnsMs":0,`)
+}
+
+func TestNetworkTransport(t *testing.T) {
+	transport, err := networkTransport(client.TransportConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, transport, "no flags keep the default transport")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	transport, err = networkTransport(client.TransportConfig{Insecure: true})
+	require.NoError(t, err)
+
+	result := checkHealth(context.Background(), server.URL, 5*time.Second, "GET", healthOptions{transport: transport})
+	assert.Equal(t, http.StatusPaymentRequired, result.Status)
+}
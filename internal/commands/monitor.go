@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/monitor"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// Monitor command flags
+var (
+	monitorInterval time.Duration
+	monitorWebhook  string
+	monitorParallel int
+	monitorTimeout  int
+	monitorCount    int
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor <file>",
+	Short: "Continuously check endpoints and report changes",
+	Long: `Repeatedly health check the endpoints in a batch file and report only
+what changed between rounds.
+
+The first round establishes a baseline. After that, each round prints a line
+for every transition:
+  - status changes (pass, warn, fail)
+  - price changes on a network
+  - payTo or asset changes on a network
+  - networks added to or removed from the payment options
+
+The input file uses any format batch-health accepts. With --json, each change
+is printed as one JSON object per line.
+
+Use --webhook to POST each round's changes as {"changes": [...]} to a URL.
+Webhook failures are reported as warnings and do not stop monitoring.
+
+Examples:
+  x402 monitor urls.json
+  x402 monitor urls.json --interval 5m
+  x402 monitor urls.json --webhook https://hooks.example.com/x402
+  x402 monitor urls.json --json --parallel 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMonitor,
+}
+
+func init() {
+	monitorCmd.Flags().DurationVar(&monitorInterval, "interval", 60*time.Second, "Time between check rounds")
+	monitorCmd.Flags().StringVar(&monitorWebhook, "webhook", "", "POST changes as JSON to this URL")
+	monitorCmd.Flags().IntVar(&monitorParallel, "parallel", 1, "Number of parallel checks")
+	monitorCmd.Flags().IntVar(&monitorTimeout, "timeout", 30, "Request timeout in seconds")
+	monitorCmd.Flags().IntVar(&monitorCount, "count", 0, "Stop after this many rounds (0 runs until interrupted)")
+
+	rootCmd.AddCommand(monitorCmd)
+}
+
+// monitorConfig holds the settings for a monitoring session.
+type monitorConfig struct {
+	interval time.Duration
+	timeout  time.Duration
+	parallel int
+	count    int
+	webhook  string
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	if monitorInterval <= 0 {
+		return exitcode.Errorf(exitcode.Input, "--interval must be positive")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return exitcode.Errorf(exitcode.Input, "failed to read file: %w", err)
+	}
+
+	entries, err := loadBatchFile(args[0], data)
+	if err != nil {
+		return exitcode.New(exitcode.Input, err)
+	}
+	if len(entries) == 0 {
+		return exitcode.Errorf(exitcode.Input, "no URLs in file")
+	}
+
+	cfg := monitorConfig{
+		interval: monitorInterval,
+		timeout:  time.Duration(monitorTimeout) * time.Second,
+		parallel: monitorParallel,
+		count:    monitorCount,
+		webhook:  monitorWebhook,
+	}
+
+	jsonOutput := GetJSONOutput()
+	if !jsonOutput {
+		fmt.Printf("Monitoring %d endpoint(s) every %s (Ctrl+C to stop)\n", len(entries), cfg.interval)
+	}
+
+	return runMonitorLoop(cmd.Context(), entries, cfg, func(round int, results []output.HealthResult, changes []monitor.Change) {
+		printMonitorRound(round, results, changes, jsonOutput)
+	})
+}
+
+// runMonitorLoop runs check rounds until ctx is cancelled or cfg.count rounds
+// have completed, calling report after each round with the detected changes.
+func runMonitorLoop(ctx context.Context, entries []BatchEntry, cfg monitorConfig, report func(round int, results []output.HealthResult, changes []monitor.Change)) error {
+	tracker := monitor.NewTracker()
+	webhookClient := &http.Client{Timeout: cfg.timeout}
+
+	for round := 1; ; round++ {
+		results := checkRound(ctx, entries, cfg.timeout, cfg.parallel)
+		if ctx.Err() != nil {
+			return nil // Interrupted mid-round; its results are incomplete
+		}
+
+		now := time.Now().UTC()
+		var changes []monitor.Change
+		for i, entry := range entries {
+			changes = append(changes, tracker.Update(entry.Method, entry.URL, &results[i], now)...)
+		}
+
+		report(round, results, changes)
+
+		if cfg.webhook != "" && len(changes) > 0 {
+			if err := monitor.PostWebhook(ctx, webhookClient, cfg.webhook, changes); err != nil {
+				output.PrintWarning(err.Error())
+			}
+		}
+
+		if cfg.count > 0 && round >= cfg.count {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cfg.interval):
+		}
+	}
+}
+
+func printMonitorRound(round int, results []output.HealthResult, changes []monitor.Change, jsonOutput bool) {
+	if jsonOutput {
+		for _, c := range changes {
+			output.PrintJSONCompact(c)
+		}
+		return
+	}
+
+	timestamp := time.Now().Format("15:04:05")
+
+	if round == 1 {
+		counts := map[string]int{}
+		for i := range results {
+			counts[monitor.NewSnapshot(&results[i]).State]++
+		}
+		fmt.Printf("[%s] Baseline: %d passed, %d passed with warnings, %d failed\n",
+			timestamp, counts[monitor.StatePass], counts[monitor.StateWarn], counts[monitor.StateFail])
+		return
+	}
+
+	for _, c := range changes {
+		fmt.Printf("[%s] %s\n", timestamp, c)
+	}
+}
+
+// checkRound checks every entry once and returns the results in entry order.
+// A round interrupted by cancelling ctx is incomplete and must be discarded.
+func checkRound(ctx context.Context, entries []BatchEntry, timeout time.Duration, parallel int) []output.HealthResult {
+	results := make([]output.HealthResult, len(entries))
+	_, _ = streamBatchChecks(ctx, &sliceReader{entries: entries}, batchConfig{timeout: timeout, parallel: parallel}, healthOptions{},
+		func(idx int, r *output.HealthResult) {
+			results[idx] = *r
+		})
+	return results
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/monitor"
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestRunMonitorLoop_ReportsPriceChange(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		amount := "1000000"
+		if calls.Add(1) > 1 {
+			amount = "2000000"
+		}
+		paymentReq := &x402.PaymentRequired{
+			X402Version: 2,
+			Accepts: []x402.PaymentRequirement{{
+				Scheme:  "exact",
+				Network: "eip155:84532",
+				Amount:  amount,
+				Asset:   "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+				PayTo:   "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+			}},
+		}
+		jsonBytes, err := json.Marshal(paymentReq)
+		require.NoError(t, err)
+		w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(jsonBytes))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	var webhookChanges []monitor.Change
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload monitor.WebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		webhookChanges = append(webhookChanges, payload.Changes...)
+	}))
+	defer webhook.Close()
+
+	cfg := monitorConfig{
+		interval: time.Millisecond,
+		timeout:  5 * time.Second,
+		parallel: 1,
+		count:    3,
+		webhook:  webhook.URL,
+	}
+	entries := []BatchEntry{{URL: server.URL, Method: "GET"}}
+
+	var rounds [][]monitor.Change
+	err := runMonitorLoop(context.Background(), entries, cfg, func(round int, results []output.HealthResult, changes []monitor.Change) {
+		assert.Len(t, results, 1)
+		rounds = append(rounds, changes)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rounds, 3)
+	assert.Empty(t, rounds[0], "baseline round reports no changes")
+	require.Len(t, rounds[1], 1)
+	assert.Equal(t, monitor.KindPrice, rounds[1][0].Kind)
+	assert.Equal(t, server.URL, rounds[1][0].URL)
+	assert.Empty(t, rounds[2], "unchanged price is not reported again")
+
+	require.Len(t, webhookChanges, 1)
+	assert.Equal(t, monitor.KindPrice, webhookChanges[0].Kind)
+}
+
+func TestRunMonitorLoop_StopsOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := monitorConfig{interval: time.Hour, timeout: 5 * time.Second, parallel: 1}
+
+	rounds := 0
+	err := runMonitorLoop(ctx, []BatchEntry{{URL: server.URL, Method: "GET"}}, cfg, func(int, []output.HealthResult, []monitor.Change) {
+		rounds++
+		cancel()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rounds)
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// addNetworkFlags registers the proxy, TLS and address override flags.
+func addNetworkFlags(cmd *cobra.Command, cfg *client.TransportConfig) {
+	cmd.Flags().StringVar(&cfg.Proxy, "proxy", "", "Proxy URL (http, https or socks5; default: HTTP_PROXY/HTTPS_PROXY)")
+	cmd.Flags().StringVar(&cfg.CACert, "cacert", "", "PEM file of extra CA certificates to trust")
+	cmd.Flags().StringVar(&cfg.Cert, "cert", "", "PEM client certificate for mutual TLS")
+	cmd.Flags().StringVar(&cfg.Key, "key", "", "PEM private key for --cert")
+	cmd.Flags().BoolVar(&cfg.Insecure, "insecure", false, "Skip TLS certificate verification")
+	cmd.Flags().StringArrayVar(&cfg.Resolve, "resolve", nil, "Connect host:port to another address, as host:port:addr (repeatable)")
+}
+
+// networkTransport returns the transport for the network flags, or nil when
+// none are set.
+func networkTransport(cfg client.TransportConfig) (http.RoundTripper, error) {
+	if cfg.IsZero() {
+		return nil, nil
+	}
+	if cfg.Insecure && !GetJSONOutput() {
+		output.PrintWarning("TLS certificate verification is disabled (--insecure)")
+	}
+	transport, err := client.NewTransport(cfg)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Input, err)
+	}
+	return transport, nil
+}
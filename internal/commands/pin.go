@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/baseline"
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// Pin command flags
+var (
+	pinOutput  string
+	pinMethod  string
+	pinTimeout int
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin <url>",
+	Short: "Pin an endpoint's payment options to a baseline file",
+	Long: `Record the payment options an endpoint currently advertises (network,
+asset, amount and payTo) in a baseline file.
+
+Run "x402 health --baseline <file>" later, e.g. in CI, to fail with exit
+code 6 if any of them drift. Pinning more endpoints into the same file adds
+them; pinning an endpoint again replaces its entry.
+
+Examples:
+  x402 pin https://api.example.com/endpoint
+  x402 pin https://api.example.com/endpoint --output pinned.json
+  x402 pin https://api.example.com/endpoint --method POST`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPin,
+}
+
+func init() {
+	pinCmd.Flags().StringVarP(&pinOutput, "output", "o", "x402-baseline.json", "Baseline file to write")
+	pinCmd.Flags().StringVarP(&pinMethod, "method", "X", "GET", "HTTP method")
+	pinCmd.Flags().IntVar(&pinTimeout, "timeout", 30, "Request timeout in seconds")
+
+	rootCmd.AddCommand(pinCmd)
+}
+
+func runPin(cmd *cobra.Command, args []string) error {
+	endpoint, err := normalizeURL(args[0])
+	if err != nil {
+		return err
+	}
+
+	file, err := baseline.LoadOrNew(pinOutput)
+	if err != nil {
+		return exitcode.New(exitcode.Input, err)
+	}
+
+	pinned, err := pinEndpoint(cmd.Context(), endpoint, strings.ToUpper(pinMethod), time.Duration(pinTimeout)*time.Second)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+
+	file.Pin(*pinned)
+	if err := file.Save(pinOutput); err != nil {
+		return err
+	}
+
+	if GetJSONOutput() {
+		return output.PrintJSON(pinned)
+	}
+
+	fmt.Printf("Pinned %d payment option(s) for %s %s to %s\n", len(pinned.Options), pinned.Method, pinned.URL, pinOutput)
+	if GetVerbose() {
+		for _, opt := range pinned.Options {
+			fmt.Printf("  %s %s %s → %s\n", opt.Network, opt.Amount, opt.Asset, opt.PayTo)
+		}
+	}
+	return nil
+}
+
+// pinEndpoint fetches the endpoint's current payment options. Endpoints that
+// fail the health check cannot be pinned.
+func pinEndpoint(ctx context.Context, url, method string, timeout time.Duration) (*baseline.Endpoint, error) {
+	result := checkHealth(ctx, url, timeout, method, healthOptions{})
+	if result.ExitCode != 0 {
+		reason := result.Error
+		for _, c := range result.Checks {
+			if c.Status == output.StatusFail {
+				reason = c.Message
+				break
+			}
+		}
+		return nil, exitcode.Errorf(exitcode.Code(result.ExitCode), "cannot pin %s: %s", url, reason)
+	}
+	if len(result.PaymentOptions) == 0 {
+		return nil, exitcode.Errorf(exitcode.Protocol, "cannot pin %s: no payment options (got %d)", url, result.Status)
+	}
+
+	return &baseline.Endpoint{
+		URL:      url,
+		Method:   method,
+		PinnedAt: time.Now().UTC().Truncate(time.Second),
+		Options:  baselineOptions(result.PaymentOptions),
+	}, nil
+}
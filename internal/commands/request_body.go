@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// bodyFlags holds curl-style request body flags.
+type bodyFlags struct {
+	data   []string // -d: inline data, @file or @- with CR and LF removed
+	binary []string // --data-binary: inline data, @file or @- sent as-is
+	json   []string // --data-json: JSON, @file or @-; sets Content-Type
+	form   []string // -F: multipart fields, name=value, name=@file or name=<file
+}
+
+// addBodyFlags registers the request body flags on cmd.
+func addBodyFlags(cmd *cobra.Command, f *bodyFlags) {
+	cmd.Flags().StringArrayVarP(&f.data, "data", "d", nil, "Request body data; @file reads a file and @- stdin, without newlines (repeatable, joined with &)")
+	cmd.Flags().StringArrayVar(&f.binary, "data-binary", nil, "Request body data; @file and @- are sent byte for byte (repeatable)")
+	cmd.Flags().StringArrayVar(&f.json, "data-json", nil, "JSON request body (or @file, @-); sets Content-Type and Accept to application/json")
+	cmd.Flags().StringArrayVarP(&f.form, "form", "F", nil, "Multipart form field name=value, name=@file to upload a file or name=<file for its contents (repeatable)")
+}
+
+// requestBody is an encoded request body. The same bytes are sent with the
+// unpaid request and the paid retry.
+type requestBody struct {
+	data        []byte
+	contentType string // Set unless the body is raw data
+	accept      string
+	fromStdin   bool
+}
+
+// build encodes the body described by the flags, reading @- from stdin.
+// It returns a nil body when no flags are set.
+func (f *bodyFlags) build(stdin io.Reader) (*requestBody, error) {
+	groups := 0
+	for _, g := range [][]string{f.data, f.binary, f.json, f.form} {
+		if len(g) > 0 {
+			groups++
+		}
+	}
+	switch {
+	case groups == 0:
+		return nil, nil
+	case groups > 1 && len(f.form) > 0:
+		return nil, fmt.Errorf("--form cannot be combined with --data, --data-binary or --data-json")
+	case len(f.json) > 0 && groups > 1:
+		return nil, fmt.Errorf("--data-json cannot be combined with --data or --data-binary")
+	}
+
+	src := &bodySource{stdin: stdin}
+	body := &requestBody{}
+	var err error
+	switch {
+	case len(f.form) > 0:
+		body.data, body.contentType, err = encodeForm(f.form, src)
+	case len(f.json) > 0:
+		var parts [][]byte
+		parts, err = readData(f.json, src, false)
+		body.data = bytes.Join(parts, nil)
+		if err == nil && !json.Valid(body.data) {
+			err = fmt.Errorf("--data-json is not valid JSON")
+		}
+		body.contentType, body.accept = "application/json", "application/json"
+	default:
+		// Like curl, values are joined with &; -d values come first
+		var parts [][]byte
+		parts, err = readData(f.data, src, true)
+		if err == nil {
+			var binary [][]byte
+			binary, err = readData(f.binary, src, false)
+			parts = append(parts, binary...)
+		}
+		body.data = bytes.Join(parts, []byte("&"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	body.fromStdin = src.used
+	return body, nil
+}
+
+// applyHeaders sets Content-Type and Accept unless headers already has them.
+func (b *requestBody) applyHeaders(headers map[string]string) {
+	setDefault := func(name, value string) {
+		if value == "" {
+			return
+		}
+		for k := range headers {
+			if strings.EqualFold(k, name) {
+				return
+			}
+		}
+		headers[name] = value
+	}
+	setDefault("Content-Type", b.contentType)
+	setDefault("Accept", b.accept)
+}
+
+// bodySource reads @file and @- references. Stdin can only be read once.
+type bodySource struct {
+	stdin io.Reader
+	used  bool
+}
+
+func (s *bodySource) read(name string) ([]byte, error) {
+	if name == "-" {
+		if s.used {
+			return nil, fmt.Errorf("stdin (@-) can only be used once")
+		}
+		s.used = true
+		data, err := io.ReadAll(s.stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body file: %w", err)
+	}
+	return data, nil
+}
+
+// readData resolves each value, reading those starting with @ from a file or
+// stdin. With stripNewlines, CR and LF are removed from what is read, as
+// curl does for -d.
+func readData(values []string, src *bodySource, stripNewlines bool) ([][]byte, error) {
+	parts := make([][]byte, 0, len(values))
+	for _, v := range values {
+		name, ok := strings.CutPrefix(v, "@")
+		if !ok {
+			parts = append(parts, []byte(v))
+			continue
+		}
+		data, err := src.read(name)
+		if err != nil {
+			return nil, err
+		}
+		if stripNewlines {
+			data = bytes.ReplaceAll(data, []byte("\r"), nil)
+			data = bytes.ReplaceAll(data, []byte("\n"), nil)
+		}
+		parts = append(parts, data)
+	}
+	return parts, nil
+}
+
+// encodeForm encodes curl-style form fields as multipart/form-data:
+//
+//	name=value           text field
+//	name=@path           file upload, named after the file
+//	name=<path           text field holding the file's contents
+//
+// A file upload may be followed by ;type=<mime> and ;filename=<name>.
+func encodeForm(fields []string, src *bodySource) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	for _, field := range fields {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok || name == "" {
+			return nil, "", fmt.Errorf("invalid form field %q (expected name=value, name=@file or name=<file)", field)
+		}
+
+		switch {
+		case strings.HasPrefix(value, "@"):
+			path, params, _ := strings.Cut(value[1:], ";")
+			data, err := src.read(path)
+			if err != nil {
+				return nil, "", err
+			}
+
+			filename := filepath.Base(path)
+			if path == "-" {
+				filename = "-"
+			}
+			contentType := mime.TypeByExtension(filepath.Ext(path))
+			for _, param := range strings.Split(params, ";") {
+				key, val, _ := strings.Cut(param, "=")
+				switch strings.TrimSpace(key) {
+				case "":
+				case "type":
+					contentType = val
+				case "filename":
+					filename = val
+				default:
+					return nil, "", fmt.Errorf("invalid form field %q: unknown option %q", field, key)
+				}
+			}
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name, "filename": filename}))
+			header.Set("Content-Type", contentType)
+			part, err := w.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write(data); err != nil {
+				return nil, "", err
+			}
+		case strings.HasPrefix(value, "<"):
+			data, err := src.read(value[1:])
+			if err != nil {
+				return nil, "", err
+			}
+			if err := w.WriteField(name, string(data)); err != nil {
+				return nil, "", err
+			}
+		default:
+			if err := w.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
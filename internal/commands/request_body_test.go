@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func writeTempFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+func TestBodyFlags_Data(t *testing.T) {
+	path := writeTempFile(t, "body.txt", []byte("a=1\r\nb=2\n"))
+
+	tests := []struct {
+		name  string
+		flags bodyFlags
+		stdin string
+		want  string
+	}{
+		{"inline", bodyFlags{data: []string{`{"q":"test"}`}}, "", `{"q":"test"}`},
+		{"joined", bodyFlags{data: []string{"a=1", "b=2"}}, "", "a=1&b=2"},
+		{"file without newlines", bodyFlags{data: []string{"@" + path}}, "", "a=1b=2"},
+		{"stdin", bodyFlags{data: []string{"@-"}}, "x=1\n", "x=1"},
+		{"binary file", bodyFlags{binary: []string{"@" + path}}, "", "a=1\r\nb=2\n"},
+		{"binary stdin", bodyFlags{binary: []string{"@-"}}, "\x00\x01\n", "\x00\x01\n"},
+		{"data then binary", bodyFlags{data: []string{"a=1"}, binary: []string{"b=2"}}, "", "a=1&b=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.flags.build(strings.NewReader(tt.stdin))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(body.data))
+			assert.Empty(t, body.contentType)
+			assert.Equal(t, tt.stdin != "", body.fromStdin)
+		})
+	}
+}
+
+func TestBodyFlags_None(t *testing.T) {
+	body, err := (&bodyFlags{}).build(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Nil(t, body)
+}
+
+func TestBodyFlags_JSON(t *testing.T) {
+	path := writeTempFile(t, "query.json", []byte("{\"q\": \"cats\"}\n"))
+
+	body, err := (&bodyFlags{json: []string{"@" + path}}).build(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"q\": \"cats\"}\n", string(body.data))
+
+	headers := map[string]string{"accept": "text/event-stream"}
+	body.applyHeaders(headers)
+	assert.Equal(t, map[string]string{"accept": "text/event-stream", "Content-Type": "application/json"}, headers)
+
+	_, err = (&bodyFlags{json: []string{"{oops"}}).build(nil)
+	assert.ErrorContains(t, err, "not valid JSON")
+}
+
+func TestBodyFlags_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags bodyFlags
+		want  string
+	}{
+		{"form and data", bodyFlags{form: []string{"a=1"}, data: []string{"b"}}, "--form cannot be combined"},
+		{"json and data", bodyFlags{json: []string{"{}"}, binary: []string{"b"}}, "--data-json cannot be combined"},
+		{"stdin twice", bodyFlags{data: []string{"@-", "@-"}}, "stdin (@-) can only be used once"},
+		{"missing file", bodyFlags{binary: []string{"@/does/not/exist"}}, "failed to read body file"},
+		{"form without name", bodyFlags{form: []string{"=x"}}, "invalid form field"},
+		{"form option", bodyFlags{form: []string{"f=@-;size=1"}}, `unknown option "size"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.flags.build(strings.NewReader(""))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestBodyFlags_Form(t *testing.T) {
+	image := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	imagePath := writeTempFile(t, "cat.png", image)
+	notePath := writeTempFile(t, "note.txt", []byte("hello"))
+
+	body, err := (&bodyFlags{form: []string{
+		"image=@" + imagePath,
+		"raw=@-;type=audio/wav;filename=clip.wav",
+		"note=<" + notePath,
+		"size=small",
+	}}).build(strings.NewReader("RIFF"))
+	require.NoError(t, err)
+	assert.True(t, body.fromStdin)
+
+	mediaType, params, err := mime.ParseMediaType(body.contentType)
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	r := multipart.NewReader(bytes.NewReader(body.data), params["boundary"])
+	form, err := r.ReadForm(1 << 20)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"hello"}, form.Value["note"])
+	assert.Equal(t, []string{"small"}, form.Value["size"])
+
+	require.Len(t, form.File["image"], 1)
+	img := form.File["image"][0]
+	assert.Equal(t, "cat.png", img.Filename)
+	assert.Equal(t, "image/png", img.Header.Get("Content-Type"))
+	f, err := img.Open()
+	require.NoError(t, err)
+	data, _ := io.ReadAll(f)
+	assert.Equal(t, image, data)
+
+	require.Len(t, form.File["raw"], 1)
+	assert.Equal(t, "clip.wav", form.File["raw"][0].Filename)
+	assert.Equal(t, "audio/wav", form.File["raw"][0].Header.Get("Content-Type"))
+}
+
+// Binary uploads are base64-encoded in the HAR file, so it stays valid JSON
+// and the body can be recovered byte for byte.
+func TestBodyFlags_FormInHAR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	path := writeTempFile(t, "cat.png", []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe})
+	body, err := (&bodyFlags{form: []string{"image=@" + path}}).build(nil)
+	require.NoError(t, err)
+
+	headers := map[string]string{}
+	body.applyHeaders(headers)
+	recorder := newHARRecorder("out.har", false)
+	c := client.New(client.WithRecorder(recorder))
+	resp, err := c.Request(context.Background(), http.MethodPost, server.URL, headers, body.data)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	entries := recorder.HAR().Log.Entries
+	require.Len(t, entries, 1)
+	postData := entries[0].Request.PostData
+	require.NotNil(t, postData)
+	assert.Equal(t, body.contentType, postData.MimeType)
+	assert.Equal(t, "base64", postData.Encoding)
+	decoded, err := base64.StdEncoding.DecodeString(postData.Text)
+	require.NoError(t, err)
+	assert.Equal(t, body.data, decoded)
+}
+
+// The paid retry must carry the same bytes as the request that returned 402,
+// including the multipart boundary.
+func TestBodyFlags_PaidRetrySendsSameBytes(t *testing.T) {
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, data)
+		if r.Header.Get(x402.HeaderPaymentSignature) == "" {
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := writeTempFile(t, "clip.mp3", []byte("ID3\x00\x01binary"))
+	body, err := (&bodyFlags{form: []string{"audio=@" + path}}).build(nil)
+	require.NoError(t, err)
+
+	headers := map[string]string{}
+	body.applyHeaders(headers)
+	c := client.New()
+	for _, paid := range []bool{false, true} {
+		if paid {
+			headers[x402.HeaderPaymentSignature] = "signed"
+		}
+		result, err := c.TimedRequest(context.Background(), http.MethodPost, server.URL, headers, body.data)
+		require.NoError(t, err)
+		result.Response.Body.Close()
+	}
+
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Equal(t, body.data, bodies[1])
+}
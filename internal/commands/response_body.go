@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/port402/x402-cli/internal/output"
+)
+
+// responseOptions controls how readResponseBody consumes a paid response.
+type responseOptions struct {
+	path  string    // Save the body of a successful payment to this file
+	live  io.Writer // Copy a successful streaming body here as it arrives
+	start time.Time // When the paid request was sent
+}
+
+// readResponseBody reads the paid response body into result: text as
+// ResponseBody, binary as ResponseBase64, with its type, size and SHA-256.
+// When opts.path is set and the payment succeeded, the body is streamed to
+// that file instead and nil is returned. Likewise a successful streaming
+// body (server-sent events or chunked text) is copied to opts.live, if set,
+// as it arrives. Streaming bodies record their time to first token.
+func readResponseBody(resp *http.Response, opts responseOptions, result *output.TestResult) ([]byte, error) {
+	result.ResponseType = resp.Header.Get("Content-Type")
+	hash := sha256.New()
+	writers := []io.Writer{hash}
+
+	var clock *tokenClock
+	if isStreamingResponse(resp) {
+		result.Streamed = true
+		clock = &tokenClock{sse: isEventStream(result.ResponseType)}
+		writers = append(writers, clock)
+	}
+	defer func() {
+		if opts.start.IsZero() {
+			return
+		}
+		result.DurationMs = time.Since(opts.start).Milliseconds()
+		if clock != nil && !clock.at.IsZero() {
+			result.TTFTMs = clock.at.Sub(opts.start).Milliseconds()
+		}
+	}()
+
+	ok := resp.StatusCode == http.StatusOK
+	switch {
+	case ok && opts.path != "":
+		file, err := os.Create(opts.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save response: %w", err)
+		}
+		n, err := io.Copy(io.MultiWriter(append(writers, file)...), resp.Body)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(opts.path)
+			return nil, fmt.Errorf("failed to save response: %w", err)
+		}
+		result.OutputFile = opts.path
+		result.ResponseSize = n
+		result.ResponseSHA256 = hex.EncodeToString(hash.Sum(nil))
+		return nil, nil
+	case ok && clock != nil && opts.live != nil:
+		n, err := io.Copy(io.MultiWriter(append(writers, opts.live)...), resp.Body)
+		result.ResponseSize = n
+		result.ResponseSHA256 = hex.EncodeToString(hash.Sum(nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(io.TeeReader(resp.Body, io.MultiWriter(writers...)))
+	result.ResponseSize = int64(len(data))
+	result.ResponseSHA256 = hex.EncodeToString(hash.Sum(nil))
+	if output.IsTextBody(result.ResponseType, data) {
+		result.ResponseBody = string(data)
+	} else {
+		result.ResponseBase64 = base64.StdEncoding.EncodeToString(data)
+	}
+	if err != nil {
+		return data, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// isStreamingResponse reports whether resp is a stream worth printing as it
+// arrives: server-sent events, or text sent in chunks of unknown total size.
+func isStreamingResponse(resp *http.Response) bool {
+	contentType := resp.Header.Get("Content-Type")
+	if isEventStream(contentType) {
+		return true
+	}
+	chunked := len(resp.TransferEncoding) > 0 && resp.TransferEncoding[0] == "chunked"
+	return chunked && output.IsTextBody(contentType, nil)
+}
+
+func isEventStream(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/event-stream"
+}
+
+// tokenClock notes when the first token of a streamed body arrives: the
+// first "data:" line of server-sent events, or the first byte otherwise.
+type tokenClock struct {
+	sse  bool
+	line []byte // Start of the current SSE line, until the first token
+	at   time.Time
+}
+
+func (c *tokenClock) Write(p []byte) (int, error) {
+	if !c.at.IsZero() || len(p) == 0 {
+		return len(p), nil
+	}
+	if !c.sse {
+		c.at = time.Now()
+		return len(p), nil
+	}
+
+	c.line = append(c.line, p...)
+	for {
+		if bytes.HasPrefix(c.line, []byte("data:")) {
+			c.at = time.Now()
+			c.line = nil
+			break
+		}
+		i := bytes.IndexByte(c.line, '\n')
+		if i < 0 {
+			break
+		}
+		c.line = c.line[i+1:]
+	}
+	return len(p), nil
+}
+
+// terminalStream prints a streamed body on a terminal under a heading,
+// ending it with a newline.
+type terminalStream struct {
+	w       io.Writer
+	started bool
+	last    byte
+}
+
+func (t *terminalStream) Write(p []byte) (int, error) {
+	if !t.started {
+		fmt.Fprintln(t.w, "Response:")
+		t.started = true
+	}
+	if len(p) > 0 {
+		t.last = p[len(p)-1]
+	}
+	return t.w.Write(p)
+}
+
+// end finishes the stream's last line and separates it from what follows.
+func (t *terminalStream) end() {
+	if !t.started {
+		return
+	}
+	if t.last != '\n' {
+		fmt.Fprintln(t.w)
+	}
+	fmt.Fprintln(t.w)
+}
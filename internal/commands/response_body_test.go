@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/output"
+)
+
+func testResponse(status int, contentType string, body []byte) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+	}
+}
+
+func TestReadResponseBody(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\n\x00\xff\x00\xfe")
+	sum := sha256.Sum256(image)
+
+	t.Run("text", func(t *testing.T) {
+		result := &output.TestResult{}
+		data, err := readResponseBody(testResponse(200, "application/json", []byte(`{"ok":true}`)), responseOptions{}, result)
+		require.NoError(t, err)
+		assert.Equal(t, `{"ok":true}`, string(data))
+		assert.Equal(t, `{"ok":true}`, result.ResponseBody)
+		assert.Empty(t, result.ResponseBase64)
+		assert.Equal(t, int64(11), result.ResponseSize)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		result := &output.TestResult{}
+		data, err := readResponseBody(testResponse(200, "image/png", image), responseOptions{}, result)
+		require.NoError(t, err)
+		assert.Equal(t, image, data)
+		assert.Empty(t, result.ResponseBody)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(image), result.ResponseBase64)
+		assert.Equal(t, "image/png", result.ResponseType)
+		assert.Equal(t, hex.EncodeToString(sum[:]), result.ResponseSHA256)
+	})
+
+	t.Run("saved to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.png")
+		result := &output.TestResult{}
+		data, err := readResponseBody(testResponse(200, "image/png", image), responseOptions{path: path}, result)
+		require.NoError(t, err)
+		assert.Nil(t, data)
+
+		saved, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, image, saved)
+		assert.Equal(t, path, result.OutputFile)
+		assert.Equal(t, int64(len(image)), result.ResponseSize)
+		assert.Equal(t, hex.EncodeToString(sum[:]), result.ResponseSHA256)
+		assert.Empty(t, result.ResponseBase64)
+	})
+
+	t.Run("failed payment is not saved", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.png")
+		result := &output.TestResult{}
+		_, err := readResponseBody(testResponse(402, "application/json", []byte(`{"error":"insufficient_funds"}`)), responseOptions{path: path}, result)
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+		assert.Empty(t, result.OutputFile)
+		assert.Equal(t, `{"error":"insufficient_funds"}`, result.ResponseBody)
+	})
+
+	t.Run("unwritable path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "out.png")
+		_, err := readResponseBody(testResponse(200, "image/png", image), responseOptions{path: path}, &output.TestResult{})
+		assert.ErrorContains(t, err, "failed to save response")
+	})
+}
+
+// notifyWriter signals the first write.
+type notifyWriter struct {
+	bytes.Buffer
+	first chan struct{}
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	if w.Len() == 0 {
+		close(w.first)
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestReadResponseBody_Streams(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, "data: {\"token\":\"Hel\"}\n\n")
+		w.(http.Flusher).Flush()
+		// The rest is only sent once the client has shown the first event
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+		fmt.Fprint(w, "data: {\"token\":\"lo\"}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	live := &notifyWriter{first: make(chan struct{})}
+	go func() {
+		<-live.first
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	result := &output.TestResult{}
+	data, err := readResponseBody(resp, responseOptions{live: live, start: start}, result)
+	require.NoError(t, err)
+
+	assert.Nil(t, data)
+	assert.Less(t, time.Since(start), 4*time.Second, "body was buffered instead of streamed")
+	assert.Contains(t, live.String(), `data: {"token":"lo"}`)
+	assert.True(t, result.Streamed)
+	assert.Empty(t, result.ResponseBody)
+	assert.Equal(t, int64(live.Len()), result.ResponseSize)
+	assert.GreaterOrEqual(t, result.TTFTMs, int64(20), "first token is the first data line, not the comment")
+	assert.GreaterOrEqual(t, result.DurationMs, result.TTFTMs)
+}
+
+func TestReadResponseBody_StreamInJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "part one, ")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "part two")
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Without a live writer the stream is collected into the result
+	result := &output.TestResult{}
+	data, err := readResponseBody(resp, responseOptions{start: start}, result)
+	require.NoError(t, err)
+	assert.Equal(t, "part one, part two", string(data))
+	assert.Equal(t, "part one, part two", result.ResponseBody)
+	assert.True(t, result.Streamed)
+}
+
+func TestIsStreamingResponse(t *testing.T) {
+	chunked := func(contentType string) *http.Response {
+		resp := testResponse(200, contentType, nil)
+		resp.TransferEncoding = []string{"chunked"}
+		return resp
+	}
+
+	assert.True(t, isStreamingResponse(testResponse(200, "text/event-stream; charset=utf-8", nil)))
+	assert.True(t, isStreamingResponse(chunked("application/x-ndjson")))
+	assert.False(t, isStreamingResponse(chunked("image/png")))
+	assert.False(t, isStreamingResponse(testResponse(200, "application/json", nil)))
+}
+
+func TestTerminalStream(t *testing.T) {
+	var buf bytes.Buffer
+	ts := &terminalStream{w: &buf}
+	ts.end()
+	assert.Empty(t, buf.String())
+
+	fmt.Fprint(ts, "hello")
+	ts.end()
+	assert.Equal(t, "Response:\nhello\n\n", buf.String())
+}
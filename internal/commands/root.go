@@ -2,12 +2,20 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
+
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/output"
 )
 
 // Version information (set at build time via ldflags)
@@ -37,8 +45,15 @@ Commands:
   health       Check if an endpoint is x402-enabled (no wallet needed)
   test         Make a test payment to an x402 endpoint
   batch-health Check multiple endpoints from a file
+  monitor      Continuously check endpoints and report changes
+  pin          Pin an endpoint's payment options to a baseline file
+  exporter     Expose endpoint health as Prometheus metrics
+  discover     Find x402-gated operations in an OpenAPI spec
   agent        Discover A2A agent card from an endpoint
+  decode       Decode and validate an x402 header or payload
   networks     List supported networks
+  exit-codes   List process exit codes
+  facilitator  Run a local facilitator for offline testing
   completion   Generate shell completion scripts
   version      Show version information
 
@@ -58,11 +73,56 @@ Examples:
 	SilenceErrors: true,
 }
 
-// Execute runs the root command.
+// errCancelled is returned by commands interrupted by Ctrl+C or SIGTERM
+// after they have reported what they completed.
+var errCancelled = exitcode.New(exitcode.Cancelled, errors.New("cancelled"))
+
+// errCancelReported is errCancelled for commands that have already told the
+// user they were cancelled, e.g. "Cancelled by user. No payment was made.",
+// so Execute exits 130 without printing it again.
+var errCancelReported = exitcode.New(exitcode.Cancelled, errors.New("cancelled"))
+
+// Execute runs the root command and exits with the code of the error it
+// returns (see x402 exit-codes). Ctrl+C or SIGTERM cancels the context passed
+// to commands so in-flight requests stop cleanly; a second signal exits
+// immediately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	classifyUsageErrors(rootCmd)
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		printError(os.Stderr, err)
+		os.Exit(int(exitcode.Of(err)))
+	}
+}
+
+// printError prints the error a command returned, unless the command has
+// already reported it.
+func printError(w io.Writer, err error) {
+	if errors.Is(err, errCancelReported) {
+		return
+	}
+	fmt.Fprintln(w, err)
+}
+
+// classifyUsageErrors makes bad arguments and flags exit with the input
+// error code.
+func classifyUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return exitcode.New(exitcode.Input, err)
+	})
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return exitcode.New(exitcode.Input, validate(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		classifyUsageErrors(sub)
 	}
 }
 
@@ -82,6 +142,19 @@ func GetJSONOutput() bool {
 	return jsonOutput
 }
 
+// resolveFormat returns the report format for a --format flag value.
+// Without --format, --json selects JSON and plain text is the default.
+func resolveFormat(flag string) (output.ReportFormat, error) {
+	if flag == "" {
+		if GetJSONOutput() {
+			return output.ReportJSON, nil
+		}
+		return output.ReportText, nil
+	}
+	format, err := output.ParseReportFormat(flag)
+	return format, exitcode.New(exitcode.Input, err)
+}
+
 // normalizeURL adds https:// if no scheme is present and validates the result.
 func normalizeURL(raw string) (string, error) {
 	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
@@ -89,10 +162,10 @@ func normalizeURL(raw string) (string, error) {
 	}
 	parsed, err := url.Parse(raw)
 	if err != nil {
-		return "", fmt.Errorf("invalid URL %q: %w", raw, err)
+		return "", exitcode.Errorf(exitcode.Input, "invalid URL %q: %w", raw, err)
 	}
 	if parsed.Host == "" {
-		return "", fmt.Errorf("invalid URL %q: missing host", raw)
+		return "", exitcode.Errorf(exitcode.Input, "invalid URL %q: missing host", raw)
 	}
 	return raw, nil
 }
@@ -1,19 +1,21 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"os/signal"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
 	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/payment"
 	"github.com/port402/x402-cli/internal/tokens"
 	"github.com/port402/x402-cli/internal/wallet"
 	"github.com/port402/x402-cli/internal/x402"
@@ -25,7 +27,7 @@ var (
 	walletKey               string
 	solanaKeypairPath       string
 	solanaRPC               string
-	requestData             string
+	testBody                bodyFlags
 	requestMethod           string
 	requestHeaders          []string
 	testTimeout             int
@@ -33,6 +35,15 @@ var (
 	skipPaymentConfirmation bool
 	noConfirm               bool
 	maxAmount               string
+	testProtocol            string
+	testHAR                 string
+	testRedact              bool
+	testRecord              string
+	testReplay              string
+	testNetwork             client.TransportConfig
+	testRetries             int
+	testIdempotencyKey      string
+	testOutput              string
 )
 
 var testCmd = &cobra.Command{
@@ -65,7 +76,68 @@ Examples:
   x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --skip-payment-confirmation
 
   # Set maximum payment amount
-  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --max-amount 0.05`,
+  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --max-amount 0.05
+
+  # Send a v1 (X-PAYMENT) payment even if the server advertises v2
+  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --protocol v1
+
+  # Save the 402 and the paid retry as a HAR file for a bug report
+  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --har payment.har --redact
+
+  # Record a known-good session, then replay it in CI without network access
+  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --record testdata/cassette
+  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --replay testdata/cassette -y
+
+  # Upload a file as multipart form data (POST unless -X is given)
+  x402 test https://api.example.com/transcribe --keystore ~/.foundry/keystores/my-wallet -F audio=@clip.mp3 -F language=en
+
+  # Send a JSON body from a file, or raw bytes from stdin
+  x402 test https://api.example.com/search --keystore ~/.foundry/keystores/my-wallet --data-json @query.json
+  cat image.png | x402 test https://api.example.com/resize --keystore ~/.foundry/keystores/my-wallet --data-binary @- -H "Content-Type: image/png" -y
+
+  # Watch a paid LLM stream as it is generated
+  x402 test https://api.example.com/chat --keystore ~/.foundry/keystores/my-wallet --data-json '{"prompt":"hi","stream":true}'
+
+  # Save a generated image
+  x402 test https://api.example.com/generate --keystore ~/.foundry/keystores/my-wallet -d '{"prompt":"cat"}' -o cat.png
+
+  # Retry transient failures, including the paid request
+  x402 test https://api.example.com/endpoint --keystore ~/.foundry/keystores/my-wallet --retries 3 --idempotency-key order-42
+
+  # Through a corporate proxy to a staging server with an internal CA
+  x402 test https://staging.internal/endpoint --keystore ~/.foundry/keystores/my-wallet --proxy http://proxy.corp:3128 --cacert corp-ca.pem
+
+--retries retries connection errors and 408, 429 and 5xx responses with
+exponential backoff, honoring Retry-After. The unpaid request is always
+retried; the paid request is only retried when it carries an
+Idempotency-Key header (--idempotency-key or -H), and each retry resends
+the same signed payment rather than signing a new one.
+
+The request body is built once, from -d, --data-binary, --data-json or
+-F, and the paid retry resends exactly the same bytes. As in curl, -d and
+--data-binary read @file or @- (stdin); -d removes newlines from what it
+reads. --data-json sets Content-Type and Accept to application/json, and -F
+sends multipart/form-data with name=value fields, name=@file uploads
+(optionally ;type=mime and ;filename=name) and name=<file text fields.
+Headers given with -H take precedence. A body makes the method POST unless
+-X is given.
+
+The paid response body is printed when it is text, pretty-printed if it is
+JSON. Binary bodies (by Content-Type, or sniffed when it is missing) are
+summarized with their type, size and SHA-256 instead, and written to stdout
+unchanged when it is not a terminal. -o saves the body of a successful
+payment to a file. With --json, text bodies appear as responseBody and
+binary ones base64-encoded as responseBodyBase64.
+
+Streaming responses (text/event-stream, or chunked text) are printed as
+they arrive instead of after the last byte, and the result records the time
+to the first token (first SSE "data:" line) and the total duration, both
+measured from sending the paid request. --har and --record capture the whole
+response before it is shown, so streams then appear all at once.
+
+Replay matches requests on method, URL and body, ignoring payment header
+values, so fresh nonces and timestamps still match the recording. Solana
+RPC calls made while building transactions are not recorded.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTest,
 }
@@ -74,7 +146,6 @@ func init() {
 	testCmd.Flags().StringVar(&keystorePath, "keystore", "", "Path to EVM keystore file")
 	testCmd.Flags().StringVar(&walletKey, "wallet", "", "EVM hex private key (or use PRIVATE_KEY env)")
 	testCmd.Flags().StringVar(&solanaKeypairPath, "solana-keypair", "", "Path to Solana keypair file")
-	testCmd.Flags().StringVarP(&requestData, "data", "d", "", "Request body data")
 	testCmd.Flags().StringVarP(&requestMethod, "method", "X", "GET", "HTTP method")
 	testCmd.Flags().StringArrayVarP(&requestHeaders, "header", "H", nil, "Custom headers (repeatable)")
 	testCmd.Flags().IntVar(&testTimeout, "timeout", 30, "Request timeout in seconds")
@@ -83,6 +154,15 @@ func init() {
 	testCmd.Flags().BoolVarP(&noConfirm, "no-confirm", "y", false, "Skip payment confirmation prompt")
 	testCmd.Flags().StringVar(&maxAmount, "max-amount", "", "Maximum payment amount (e.g., 0.05)")
 	testCmd.Flags().StringVar(&solanaRPC, "solana-rpc", "", "Custom Solana RPC endpoint URL")
+	testCmd.Flags().StringVar(&testProtocol, "protocol", "", "Force payment protocol version (v1 or v2)")
+	testCmd.Flags().StringVar(&testHAR, "har", "", "Write the HTTP exchanges to a HAR file")
+	testCmd.Flags().BoolVar(&testRedact, "redact", false, "Redact signatures in the HAR file")
+	testCmd.Flags().IntVar(&testRetries, "retries", 0, "Retry connection errors, 408, 429 and 5xx responses this many times")
+	addBodyFlags(testCmd, &testBody)
+	testCmd.Flags().StringVarP(&testOutput, "output", "o", "", "Save the paid response body to a file")
+	testCmd.Flags().StringVar(&testIdempotencyKey, "idempotency-key", "", "Idempotency-Key header for the paid request, allowing it to be retried")
+	addCassetteFlags(testCmd, &testRecord, &testReplay)
+	addNetworkFlags(testCmd, &testNetwork)
 	testCmd.Flags().MarkHidden("skip-payment-confirmation")
 
 	rootCmd.AddCommand(testCmd)
@@ -91,33 +171,37 @@ func init() {
 func runTest(cmd *cobra.Command, args []string) error {
 	endpoint, err := normalizeURL(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid URL %q: %w", args[0], err)
+		return err
 	}
 	timeout := time.Duration(testTimeout) * time.Second
 
-	// Set up interrupt handler
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt)
-	signatureSent := false
-
-	go func() {
-		<-sigChan
-		fmt.Fprintln(os.Stderr)
-		if signatureSent {
-			fmt.Fprintln(os.Stderr, "⚠ Warning: Payment signature was already sent to the server.")
-			fmt.Fprintln(os.Stderr, "  The payment may still be processed. Check your wallet balance.")
-		} else {
-			fmt.Fprintln(os.Stderr, "Cancelled by user. No payment was made.")
-		}
-		os.Exit(1)
-	}()
+	forcedProtocol, err := x402.ParseProtocolVersion(testProtocol)
+	if err != nil {
+		return exitcode.Errorf(exitcode.Input, "invalid --protocol: %w", err)
+	}
 
-	// Step 1: Make initial request
-	if GetVerbose() && !GetJSONOutput() {
-		fmt.Fprintln(os.Stderr, "• Fetching payment requirements...")
+	// Ctrl+C cancels ctx, aborting whichever step is in flight
+	ctx := cmd.Context()
+
+	network, err := networkTransport(testNetwork)
+	if err != nil {
+		return err
+	}
+	transport, err := cassetteTransport(testRecord, testReplay, network)
+	if err != nil {
+		return err
 	}
 
-	httpClient := client.New(client.WithTimeout(timeout))
+	clientOpts := []client.Option{
+		client.WithTimeout(timeout),
+		client.WithTransport(transport),
+		client.WithRetry(client.RetryPolicy{MaxRetries: testRetries}),
+	}
+	if recorder := newHARRecorder(testHAR, testRedact); recorder != nil {
+		clientOpts = append(clientOpts, client.WithRecorder(recorder))
+		defer writeHARFile(recorder, testHAR)
+	}
+	httpClient := client.New(clientOpts...)
 
 	// Build headers from "Key: Value" format
 	headers := make(map[string]string)
@@ -129,63 +213,76 @@ func runTest(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	var body []byte
-	if requestData != "" {
-		body = []byte(requestData)
-	}
-
-	reqResult, err := httpClient.TimedRequest(requestMethod, endpoint, headers, body)
+	// Encode the body once; the paid retry resends the same bytes
+	reqBody, err := testBody.build(os.Stdin)
 	if err != nil {
-		return fmt.Errorf("connection failed: %w", err)
-	}
-	defer reqResult.Response.Body.Close()
-
-	// Check for 402
-	if reqResult.Response.StatusCode != http.StatusPaymentRequired {
-		if reqResult.Response.StatusCode == http.StatusOK {
-			bodyBytes, _ := io.ReadAll(reqResult.Response.Body)
-			if GetJSONOutput() {
-				return output.PrintJSON(map[string]interface{}{
-					"url":      endpoint,
-					"status":   200,
-					"message":  "Endpoint does not require payment",
-					"body":     string(bodyBytes),
-					"exitCode": 0,
-				})
-			}
-			fmt.Printf("Endpoint returned 200 OK (no payment required)\n")
-			fmt.Printf("Response: %s\n", string(bodyBytes))
-			return nil
+		return exitcode.New(exitcode.Input, err)
+	}
+	var body []byte
+	method := requestMethod
+	if reqBody != nil {
+		body = reqBody.data
+		reqBody.applyHeaders(headers)
+		if !cmd.Flags().Changed("method") {
+			method = http.MethodPost
 		}
-		return fmt.Errorf("expected 402 Payment Required, got %d", reqResult.Response.StatusCode)
 	}
+	bodyFromStdin := reqBody != nil && reqBody.fromStdin
+
+	var paidStart time.Time
+	p := payment.New(httpClient, method, endpoint,
+		payment.WithHeaders(headers),
+		payment.WithBody(body),
+		payment.WithProtocol(forcedProtocol),
+		payment.WithIdempotencyKey(testIdempotencyKey),
+		payment.WithSelector(func(required *x402.PaymentRequired) (*x402.PaymentRequirement, error) {
+			return selectPaymentOption(x402.FindSolanaOption(required), x402.FindEVMOption(required), solanaKeypairPath != "")
+		}),
+		payment.WithSigner(loadTestSigner(bodyFromStdin)),
+		payment.WithConfirm(confirmTestPayment(bodyFromStdin)),
+		payment.WithObserver(func(state payment.State, p *payment.Payment) {
+			if state == payment.StateSending {
+				paidStart = time.Now()
+			}
+			if GetVerbose() && !GetJSONOutput() {
+				printPaymentProgress(state, p)
+			}
+		}),
+	)
+	defer p.Close()
 
-	// Step 2: Parse payment requirements
-	if GetVerbose() && !GetJSONOutput() {
-		fmt.Fprintln(os.Stderr, "• Parsing 402 response...")
+	// Steps 1-2: request the payment requirements and select an option
+	err = p.Quote(ctx)
+	if p.Required != nil && p.Required.VersionMismatch() && !GetJSONOutput() {
+		output.PrintWarning(fmt.Sprintf("402 delivered as v%d but declares x402Version %d",
+			p.Required.ProtocolVersion, p.Required.DeclaredVersion))
 	}
-
-	parseResult, err := x402.ParsePaymentRequired(reqResult.Response)
 	if err != nil {
-		return fmt.Errorf("failed to parse payment requirements: %w", err)
+		if exitcode.Of(err) == exitcode.Cancelled {
+			return paymentCancelled(nil, false)
+		}
+		return err
 	}
 
-	// Find payment option based on provided credentials
-	solanaOption := x402.FindSolanaOption(parseResult.PaymentRequired)
-	evmOption := x402.FindEVMOption(parseResult.PaymentRequired)
-
-	paymentOption, isSolana, err := selectPaymentOption(solanaOption, evmOption, solanaKeypairPath != "")
-	if err != nil {
-		return fmt.Errorf("select payment option: %w", err)
+	if p.State() == payment.StateNotRequired {
+		bodyBytes, _ := io.ReadAll(p.Initial.Response.Body)
+		if GetJSONOutput() {
+			return output.PrintJSON(map[string]interface{}{
+				"url":      endpoint,
+				"status":   200,
+				"message":  "Endpoint does not require payment",
+				"body":     string(bodyBytes),
+				"exitCode": 0,
+			})
+		}
+		fmt.Printf("Endpoint returned 200 OK (no payment required)\n")
+		fmt.Printf("Response: %s\n", string(bodyBytes))
+		return nil
 	}
 
-	// Get chain ID for EVM
-	var chainID int64
-	if !isSolana {
-		chainID, err = x402.ExtractChainID(paymentOption.Network)
-		if err != nil {
-			return fmt.Errorf("invalid network: %w", err)
-		}
+	paymentOption := p.Option
+	if forcedProtocol != 0 && forcedProtocol != p.Required.ProtocolVersion && GetVerbose() && !GetJSONOutput() {
+		fmt.Fprintf(os.Stderr, "• Forcing v%d payment (server advertised v%d)\n", forcedProtocol, p.Required.ProtocolVersion)
 	}
 
 	// Format payment info
@@ -206,19 +303,19 @@ func runTest(cmd *cobra.Command, args []string) error {
 		tokenInfo := tokens.GetTokenInfo(paymentOption.Network, paymentOption.Asset)
 		maxRaw, err := tokens.ParseHumanAmount(maxAmount, tokenInfo.Decimals)
 		if err != nil {
-			return fmt.Errorf("invalid --max-amount: %w", err)
+			return exitcode.Errorf(exitcode.Input, "invalid --max-amount: %w", err)
 		}
 		if tokens.CompareAmounts(paymentOption.GetAmount(), maxRaw) > 0 {
-			return fmt.Errorf("payment amount %s exceeds maximum %s %s", amountHuman, maxAmount, tokenInfo.Symbol)
+			return exitcode.Errorf(exitcode.PolicyRefused, "payment amount %s exceeds maximum %s %s", amountHuman, maxAmount, tokenInfo.Symbol)
 		}
 	}
 
 	// Build result for display/output
 	result := &output.TestResult{
 		URL:        endpoint,
-		Status:     reqResult.Response.StatusCode,
-		StatusText: reqResult.Response.Status,
-		Protocol:   fmt.Sprintf("v%d", parseResult.ProtocolVersion),
+		Status:     p.Initial.Response.StatusCode,
+		StatusText: p.Initial.Response.Status,
+		Protocol:   fmt.Sprintf("v%d", p.Required.ProtocolVersion),
 		PaymentOption: output.PaymentOptionDisplay{
 			Index:       1,
 			Scheme:      paymentOption.Scheme,
@@ -233,6 +330,9 @@ func runTest(cmd *cobra.Command, args []string) error {
 		DryRun:   dryRun,
 		ExitCode: 0,
 	}
+	if forcedProtocol != 0 {
+		result.PaymentProtocol = fmt.Sprintf("v%d", forcedProtocol)
+	}
 
 	// Show payment details
 	if !GetJSONOutput() {
@@ -255,149 +355,45 @@ func runTest(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Load wallet and create signer
-	var fromAddress string
-	var signer wallet.Signer
-
-	if isSolana {
-		// Load Solana keypair
-		if GetVerbose() && !GetJSONOutput() {
-			fmt.Fprintln(os.Stderr, "• Loading Solana keypair...")
-		}
-
-		solanaKey, err := wallet.LoadSolanaKeypair(solanaKeypairPath)
-		if err != nil {
-			return fmt.Errorf("failed to load Solana keypair: %w", err)
+	// Steps 3-6: load the wallet, confirm, sign and retry with the payment
+	if err := p.Pay(ctx); err != nil {
+		switch {
+		case errors.Is(err, errCancelReported):
+			// Declined at the prompt
+			return err
+		case exitcode.Of(err) == exitcode.Cancelled:
+			return paymentCancelled(result, p.SignatureSent())
 		}
-
-		fromAddress = wallet.GetSolanaAddress(solanaKey)
-		rpcURL, err := x402.GetSolanaRPCURL(paymentOption.Network)
-		if err != nil {
-			return fmt.Errorf("failed to get Solana RPC URL: %w", err)
-		}
-		if solanaRPC != "" {
-			if _, err := normalizeURL(solanaRPC); err != nil {
-				return fmt.Errorf("invalid --solana-rpc URL: %w", err)
-			}
-			rpcURL = solanaRPC
-		}
-		signer = wallet.NewSolanaSigner(solanaKey, rpcURL)
-	} else {
-		// Load EVM wallet
-		if GetVerbose() && !GetJSONOutput() {
-			fmt.Fprintln(os.Stderr, "• Loading wallet...")
-		}
-
-		privateKeyLoaded, err := wallet.LoadPrivateKey(keystorePath, walletKey, !output.IsStdinTTY())
-		if err != nil {
-			return fmt.Errorf("failed to load wallet: %w", err)
-		}
-
-		fromAddress = wallet.GetAddress(privateKeyLoaded)
-		signer = wallet.NewEVMSigner(privateKeyLoaded)
-	}
-
-	if GetVerbose() && !GetJSONOutput() {
-		fmt.Fprintf(os.Stderr, "  Wallet: %s\n", fromAddress)
+		return err
 	}
+	retryResult := p.Paid
 
-	// Mainnet warning
-	if !GetJSONOutput() && !tokens.IsTestnet(paymentOption.Network) {
-		output.PrintWarning("This is a MAINNET endpoint — real funds will be used")
-	}
-
-	// Confirmation prompt
-	if !(skipPaymentConfirmation || noConfirm) && output.IsTTY() {
-		if !output.PromptConfirm("Proceed with payment?") {
-			fmt.Println("Cancelled by user. No payment was made.")
-			return nil
-		}
-		fmt.Println()
-	}
-
-	// Step 4: Sign authorization
-	if GetVerbose() && !GetJSONOutput() {
-		if isSolana {
-			fmt.Fprintln(os.Stderr, "• Building Solana transaction...")
-		} else {
-			fmt.Fprintln(os.Stderr, "• Signing EIP-3009 authorization...")
-		}
-	}
-
-	// Prepare sign params based on chain type
-	var signParams wallet.SignParams
-	if isSolana {
-		signParams = wallet.PrepareSolanaSignParams(paymentOption, fromAddress)
-	} else {
-		signParams = wallet.PrepareSignParams(paymentOption, fromAddress, chainID)
-	}
-
-	signResult, err := signer.Sign(signParams)
-	if err != nil {
-		return fmt.Errorf("failed to sign authorization: %w", err)
-	}
-
-	// Step 5: Build payment payload
-	if GetVerbose() && !GetJSONOutput() {
-		fmt.Fprintln(os.Stderr, "• Building payment payload...")
+	if retryResult.Attempts > 1 && GetVerbose() && !GetJSONOutput() {
+		fmt.Fprintf(os.Stderr, "• Payment sent %d times (same signature)\n", retryResult.Attempts)
 	}
 
-	resource := parseResult.PaymentRequired.Resource
-	if parseResult.ProtocolVersion == x402.ProtocolV1 {
-		// v1 doesn't have resource in top-level
-		resource = x402.ResourceInfo{
-			URL: endpoint,
-		}
-	}
-
-	var headerName, headerValue string
-	if isSolana {
-		// Solana uses the transaction as the payload
-		payload := x402.BuildPayloadV2Solana(resource, paymentOption, signResult.Signature)
-		headerValue, err = x402.EncodePayload(payload)
-		if err != nil {
-			return fmt.Errorf("failed to encode Solana payload: %w", err)
-		}
-		headerName = x402.HeaderPaymentSignature
-	} else {
-		// EVM uses signature and authorization
-		headerName, headerValue, err = x402.BuildAndEncodePayload(
-			parseResult.ProtocolVersion,
-			resource,
-			paymentOption,
-			signResult.Signature,
-			signResult.Authorization,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to build payment payload: %w", err)
+	// Read response body, save it with --output, or print a stream as it
+	// arrives. The payment has been made either way, so a read or write
+	// error is reported after the result.
+	bodyOpts := responseOptions{path: testOutput, start: paidStart}
+	var live *terminalStream
+	if !GetJSONOutput() {
+		bodyOpts.live = os.Stdout
+		if output.IsTTY() {
+			live = &terminalStream{w: os.Stdout}
+			bodyOpts.live = live
 		}
 	}
-
-	// Step 6: Retry with payment
-	if GetVerbose() && !GetJSONOutput() {
-		fmt.Fprintln(os.Stderr, "• Sending payment...")
-	}
-
-	// Mark that signature has been sent (for Ctrl+C warning)
-	signatureSent = true
-
-	// Add payment header to existing headers
-	headers[headerName] = headerValue
-
-	retryResult, err := httpClient.TimedRequest(requestMethod, endpoint, headers, body)
-	if err != nil {
-		return fmt.Errorf("retry request failed: %w", err)
+	responseBody, bodyErr := readResponseBody(retryResult.Response, bodyOpts, result)
+	if live != nil {
+		live.end()
 	}
-	defer retryResult.Response.Body.Close()
-
-	// Read response body
-	responseBody, _ := io.ReadAll(retryResult.Response.Body)
-	result.ResponseBody = string(responseBody)
+	result.PaymentTiming = newTiming(retryResult.Timings())
 	result.Status = retryResult.Response.StatusCode
 	result.StatusText = retryResult.Response.Status
 
-	// Parse payment response header
-	paymentResp, _ := x402.ParsePaymentResponse(retryResult.Response, parseResult.ProtocolVersion)
+	// Payment response header
+	paymentResp := p.Response
 	if paymentResp != nil {
 		result.PaymentResponse = paymentResp
 		if paymentResp.Transaction != "" {
@@ -408,28 +404,37 @@ func runTest(cmd *cobra.Command, args []string) error {
 
 	// Check success
 	if retryResult.Response.StatusCode != http.StatusOK {
-		result.ExitCode = 5 // Payment rejected
+		code := payment.RejectionCode(paymentResp, responseBody)
+		result.ExitCode = int(code)
 		result.Error = fmt.Sprintf("Payment failed: %d %s", retryResult.Response.StatusCode, retryResult.Response.Status)
 
 		if GetJSONOutput() {
-			return output.PrintJSON(result)
+			if err := output.PrintJSON(result); err != nil {
+				return err
+			}
+		} else {
+			output.PrintTestResult(result, GetVerbose())
 		}
-
-		output.PrintTestResult(result, GetVerbose())
-		return errors.New(result.Error)
+		return exitcode.New(code, errors.New(result.Error))
 	}
 
 	// Success!
 	if GetJSONOutput() {
-		return output.PrintJSON(result)
+		if err := output.PrintJSON(result); err != nil {
+			return err
+		}
+		return bodyErr
 	}
 
 	// TTY vs pipe output
 	if output.IsTTY() {
 		output.PrintTestResult(result, GetVerbose())
 	} else {
-		// Pipe mode: response body to stdout, summary to stderr
-		fmt.Print(result.ResponseBody)
+		// Pipe mode: response body to stdout byte for byte, summary to stderr
+		os.Stdout.Write(responseBody)
+		if result.OutputFile != "" {
+			fmt.Fprintf(os.Stderr, "Saved: %s (%s)\n", result.OutputFile, output.FormatSize(result.ResponseSize))
+		}
 		if result.Transaction != "" {
 			fmt.Fprintf(os.Stderr, "Transaction: %s\n", result.Transaction)
 			if result.TransactionURL != "" {
@@ -438,28 +443,141 @@ func runTest(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	return nil
+	return bodyErr
+}
+
+// paymentCancelled reports a payment flow interrupted by Ctrl+C. Once the
+// paid request has been sent the server may still settle the payment, so the
+// user is warned instead of being told nothing was paid. result is nil if the
+// flow was cancelled before the 402 was parsed.
+func paymentCancelled(result *output.TestResult, signatureSent bool) error {
+	if result != nil {
+		result.Cancelled = true
+		result.SignatureSent = signatureSent
+		result.ExitCode = int(exitcode.Cancelled)
+		result.Error = "Cancelled by user"
+		if GetJSONOutput() {
+			if err := output.PrintJSON(result); err != nil {
+				return err
+			}
+			return errCancelled
+		}
+	}
+
+	fmt.Fprintln(os.Stderr)
+	if signatureSent {
+		fmt.Fprintln(os.Stderr, "⚠ Warning: Payment signature was already sent to the server.")
+		fmt.Fprintln(os.Stderr, "  The payment may still be processed. Check your wallet balance.")
+	} else {
+		fmt.Fprintln(os.Stderr, "Cancelled by user. No payment was made.")
+	}
+	return errCancelReported
 }
 
 // selectPaymentOption chooses the appropriate payment option based on available options
 // and whether the user provided a Solana keypair.
-func selectPaymentOption(solanaOpt, evmOpt *x402.PaymentRequirement, hasSolanaKeypair bool) (*x402.PaymentRequirement, bool, error) {
+func selectPaymentOption(solanaOpt, evmOpt *x402.PaymentRequirement, hasSolanaKeypair bool) (*x402.PaymentRequirement, error) {
 	if hasSolanaKeypair {
 		if solanaOpt != nil {
-			return solanaOpt, true, nil
+			return solanaOpt, nil
 		}
 		if evmOpt != nil {
-			return nil, false, fmt.Errorf("endpoint does not accept Solana payments, but --solana-keypair was provided")
+			return nil, fmt.Errorf("endpoint does not accept Solana payments, but --solana-keypair was provided")
 		}
-		return nil, false, fmt.Errorf("no supported payment options found")
+		return nil, fmt.Errorf("no supported payment options found")
 	}
 
 	// Default to EVM
 	if evmOpt != nil {
-		return evmOpt, false, nil
+		return evmOpt, nil
 	}
 	if solanaOpt != nil {
-		return nil, false, fmt.Errorf("endpoint only accepts Solana payments (use --solana-keypair)")
+		return nil, fmt.Errorf("endpoint only accepts Solana payments (use --solana-keypair)")
+	}
+	return nil, fmt.Errorf("no supported payment options found")
+}
+
+// loadTestSigner loads the wallet for the selected option from the test
+// flags: the Solana keypair, or an EVM keystore, hex key or PRIVATE_KEY.
+func loadTestSigner(bodyFromStdin bool) payment.SignerFunc {
+	return func(ctx context.Context, p *payment.Payment) (wallet.Signer, error) {
+		if p.Solana {
+			solanaKey, err := wallet.LoadSolanaKeypair(solanaKeypairPath)
+			if err != nil {
+				return nil, exitcode.Errorf(exitcode.Input, "failed to load Solana keypair: %w", err)
+			}
+			rpcURL, err := x402.GetSolanaRPCURL(p.Option.Network)
+			if err != nil {
+				return nil, exitcode.Errorf(exitcode.Protocol, "failed to get Solana RPC URL: %w", err)
+			}
+			if solanaRPC != "" {
+				if _, err := normalizeURL(solanaRPC); err != nil {
+					return nil, fmt.Errorf("invalid --solana-rpc URL: %w", err)
+				}
+				rpcURL = solanaRPC
+			}
+			return wallet.NewSolanaSigner(solanaKey, rpcURL), nil
+		}
+
+		// Stdin holds the request body if it was read with @-
+		privateKey, err := wallet.LoadPrivateKey(keystorePath, walletKey, !output.IsStdinTTY() && !bodyFromStdin)
+		if err != nil {
+			return nil, exitcode.Errorf(exitcode.Input, "failed to load wallet: %w", err)
+		}
+		return wallet.NewEVMSigner(privateKey), nil
+	}
+}
+
+// confirmTestPayment warns about mainnet payments and asks for confirmation
+// on a terminal unless -y was given.
+func confirmTestPayment(bodyFromStdin bool) payment.ConfirmFunc {
+	return func(ctx context.Context, p *payment.Payment) error {
+		if !GetJSONOutput() && !tokens.IsTestnet(p.Option.Network) {
+			output.PrintWarning("This is a MAINNET endpoint — real funds will be used")
+		}
+		if skipPaymentConfirmation || noConfirm || !output.IsTTY() {
+			return nil
+		}
+		if bodyFromStdin {
+			return exitcode.Errorf(exitcode.Input, "the request body was read from stdin, so the payment cannot be confirmed; use --no-confirm")
+		}
+		confirmed, err := output.PromptConfirmContext(ctx, "Proceed with payment?")
+		if err != nil {
+			return exitcode.New(exitcode.Cancelled, err)
+		}
+		if !confirmed {
+			fmt.Println("Cancelled by user. No payment was made.")
+			return errCancelReported
+		}
+		fmt.Println()
+		return nil
+	}
+}
+
+// printPaymentProgress prints verbose progress as the payment flow advances.
+func printPaymentProgress(state payment.State, p *payment.Payment) {
+	switch state {
+	case payment.StateRequesting:
+		fmt.Fprintln(os.Stderr, "• Fetching payment requirements...")
+	case payment.StateParsing:
+		fmt.Fprintln(os.Stderr, "• Parsing 402 response...")
+	case payment.StateLoadingSigner:
+		if p.Solana {
+			fmt.Fprintln(os.Stderr, "• Loading Solana keypair...")
+		} else {
+			fmt.Fprintln(os.Stderr, "• Loading wallet...")
+		}
+	case payment.StateConfirming:
+		fmt.Fprintf(os.Stderr, "  Wallet: %s\n", p.Signer.Address())
+	case payment.StateSigning:
+		if p.Solana {
+			fmt.Fprintln(os.Stderr, "• Building Solana transaction...")
+		} else {
+			fmt.Fprintln(os.Stderr, "• Signing EIP-3009 authorization...")
+		}
+	case payment.StateEncoding:
+		fmt.Fprintln(os.Stderr, "• Building payment payload...")
+	case payment.StateSending:
+		fmt.Fprintln(os.Stderr, "• Sending payment...")
 	}
-	return nil, false, fmt.Errorf("no supported payment options found")
 }
@@ -0,0 +1,109 @@
+// Package exitcode defines the process exit codes of the CLI and typed
+// errors carrying them, so scripts can tell failure classes apart.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Code is a process exit code. Codes are stable across releases.
+type Code int
+
+// Exit codes.
+const (
+	OK                 Code = 0   // Success
+	General            Code = 1   // Any failure without a more specific code
+	Input              Code = 2   // Invalid arguments, flags or input files
+	Network            Code = 3   // The server could not be reached or answered 408, 429 or 5xx
+	Protocol           Code = 4   // The server's response is not valid x402
+	PaymentRejected    Code = 5   // The server refused the signed payment
+	BaselineDrift      Code = 6   // Payment options differ from a pinned baseline
+	PolicyRefused      Code = 7   // A local policy such as --max-amount refused to pay
+	InsufficientFunds  Code = 8   // The payment was rejected for lack of funds
+	PaymentUnconfirmed Code = 9   // The payment was sent but no response arrived
+	ExpectationFailed  Code = 10  // A batch entry's expectation did not hold
+	Cancelled          Code = 130 // Interrupted by Ctrl+C or SIGTERM, or declined at the prompt
+)
+
+// Info describes an exit code.
+type Info struct {
+	Code        Code   `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Retryable   bool   `json:"retryable"` // Running the command again may succeed without changes
+}
+
+// All lists every exit code in ascending order.
+var All = []Info{
+	{OK, "ok", "Success", false},
+	{General, "error", "Failure without a more specific code", false},
+	{Input, "input", "Invalid arguments, flags or input files", false},
+	{Network, "network", "The server could not be reached (connection, DNS, TLS or timeout) or answered 408, 429 or 5xx", true},
+	{Protocol, "protocol", "The server's response is not a valid x402 payment flow", false},
+	{PaymentRejected, "payment-rejected", "The server refused the signed payment", false},
+	{BaselineDrift, "baseline-drift", "Payment options differ from the pinned baseline", false},
+	{PolicyRefused, "policy-refused", "A local policy such as --max-amount refused to pay", false},
+	{InsufficientFunds, "insufficient-funds", "The payment was rejected because the wallet lacks funds", false},
+	{PaymentUnconfirmed, "payment-unconfirmed", "The payment was sent but no response arrived; it may have settled", false},
+	{ExpectationFailed, "expectation-failed", "A batch entry's expected protocol, price, networks, asset, agent card or listing did not hold", false},
+	{Cancelled, "cancelled", "Interrupted by Ctrl+C or SIGTERM, or declined at the confirmation prompt", false},
+}
+
+// Lookup returns the description of code.
+func Lookup(code Code) (Info, bool) {
+	for _, info := range All {
+		if info.Code == code {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// String returns the code's name, e.g. "network".
+func (c Code) String() string {
+	if info, ok := Lookup(c); ok {
+		return info.Name
+	}
+	return fmt.Sprintf("code %d", int(c))
+}
+
+// Error is an error that exits the process with Code.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns err classified as code. A nil err stays nil.
+func New(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error classified as code.
+func Errorf(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Of returns the exit code for err: OK for nil, the code of the outermost
+// *Error in its chain, Cancelled for context cancellation, and General
+// otherwise.
+func Of(err error) Code {
+	if err == nil {
+		return OK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	if errors.Is(err, context.Canceled) {
+		return Cancelled
+	}
+	return General
+}
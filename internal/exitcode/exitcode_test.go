@@ -0,0 +1,63 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, OK},
+		{"plain", errors.New("boom"), General},
+		{"typed", Errorf(Network, "connection refused"), Network},
+		{"wrapped", fmt.Errorf("checking: %w", New(Protocol, errors.New("bad header"))), Protocol},
+		{"outermost wins", New(Input, New(Network, errors.New("x"))), Input},
+		{"context cancelled", fmt.Errorf("request: %w", context.Canceled), Cancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Of(tt.err))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.NoError(t, New(Input, nil))
+
+	cause := errors.New("no such file")
+	err := New(Input, cause)
+	assert.EqualError(t, err, "no such file")
+	assert.ErrorIs(t, err, cause)
+
+	err = Errorf(Input, "failed to read file: %w", cause)
+	assert.EqualError(t, err, "failed to read file: no such file")
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestAll(t *testing.T) {
+	seen := map[Code]bool{}
+	names := map[string]bool{}
+	for i, info := range All {
+		assert.False(t, seen[info.Code], "duplicate code %d", info.Code)
+		assert.False(t, names[info.Name], "duplicate name %s", info.Name)
+		seen[info.Code], names[info.Name] = true, true
+		if i > 0 {
+			assert.Greater(t, info.Code, All[i-1].Code)
+		}
+	}
+
+	info, ok := Lookup(PaymentUnconfirmed)
+	assert.True(t, ok)
+	assert.False(t, info.Retryable, "a payment that may have settled must not be retried")
+	assert.Equal(t, "network", Network.String())
+	assert.Equal(t, "code 42", Code(42).String())
+}
@@ -0,0 +1,282 @@
+// Package exporter exposes x402 health check results as Prometheus metrics
+// in the text exposition format.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/tokens"
+)
+
+// DefaultBuckets are the latency histogram upper bounds in seconds.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Exporter keeps the latest health check results per endpoint and renders
+// them as metrics. It is safe for concurrent use.
+type Exporter struct {
+	mu        sync.Mutex
+	buckets   []float64
+	endpoints map[string]*endpointState
+}
+
+// Option configures the Exporter.
+type Option func(*Exporter)
+
+// WithBuckets sets the latency histogram upper bounds in seconds.
+func WithBuckets(buckets []float64) Option {
+	return func(e *Exporter) {
+		e.buckets = append([]float64(nil), buckets...)
+		sort.Float64s(e.buckets)
+	}
+}
+
+type price struct {
+	network string
+	asset   string
+	token   string
+	value   float64
+}
+
+// endpointState holds the metrics for one method and URL.
+type endpointState struct {
+	url       string
+	method    string
+	up        bool
+	returns   bool // Returned 402
+	healthy   bool
+	options   int
+	prices    []price
+	agentCard *bool // nil when agent discovery was not run
+	lastCheck time.Time
+
+	// Latency histogram, cumulative over all checks
+	bucketCounts []uint64
+	latencySum   float64
+	latencyCount uint64
+}
+
+// New creates an Exporter with the given options.
+func New(opts ...Option) *Exporter {
+	e := &Exporter{
+		buckets:   DefaultBuckets,
+		endpoints: make(map[string]*endpointState),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Observe records a health check result. A result without an agent card
+// keeps the previous agent-card value, so the metric is only exported for
+// endpoints where discovery has run.
+func (e *Exporter) Observe(result *output.HealthResult, at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := result.Method + " " + result.URL
+	state, ok := e.endpoints[key]
+	if !ok {
+		state = &endpointState{
+			url:          result.URL,
+			method:       result.Method,
+			bucketCounts: make([]uint64, len(e.buckets)),
+		}
+		e.endpoints[key] = state
+	}
+
+	state.up = result.Status != 0
+	state.returns = result.Status == http.StatusPaymentRequired
+	state.healthy = result.ExitCode == 0
+	state.options = len(result.PaymentOptions)
+	state.lastCheck = at
+	state.prices = pricesOf(result.PaymentOptions)
+
+	if result.AgentCard != nil {
+		found := result.AgentCard.Found
+		state.agentCard = &found
+	}
+
+	if state.up {
+		seconds := float64(result.LatencyMs) / 1000
+		for i, upper := range e.buckets {
+			if seconds <= upper {
+				state.bucketCounts[i]++
+			}
+		}
+		state.latencySum += seconds
+		state.latencyCount++
+	}
+}
+
+// pricesOf converts options with a known token to prices in token units.
+// Options paying in unknown tokens are skipped since their decimals are unknown.
+func pricesOf(options []output.PaymentOptionDisplay) []price {
+	var prices []price
+	for _, opt := range options {
+		info := tokens.GetTokenInfo(opt.Network, opt.Asset)
+		if info == nil {
+			continue
+		}
+		amount, ok := new(big.Float).SetString(opt.Amount)
+		if !ok {
+			continue
+		}
+		divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(info.Decimals)), nil))
+		value, _ := new(big.Float).Quo(amount, divisor).Float64()
+
+		prices = append(prices, price{
+			network: opt.Network,
+			asset:   opt.Asset,
+			token:   info.Symbol,
+			value:   value,
+		})
+	}
+	return prices
+}
+
+// Handler serves the metrics on /metrics.
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		e.WriteTo(w)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "x402 exporter - metrics at /metrics")
+	})
+	return mux
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	states := make([]*endpointState, 0, len(e.endpoints))
+	for _, s := range e.endpoints {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].url != states[j].url {
+			return states[i].url < states[j].url
+		}
+		return states[i].method < states[j].method
+	})
+
+	mw := &metricWriter{w: w}
+
+	mw.header("x402_endpoint_up", "gauge", "Whether the endpoint was reachable on the last check.")
+	for _, s := range states {
+		mw.sample("x402_endpoint_up", s.labels(), boolValue(s.up))
+	}
+
+	mw.header("x402_endpoint_returns_402", "gauge", "Whether the endpoint returned 402 Payment Required on the last check.")
+	for _, s := range states {
+		mw.sample("x402_endpoint_returns_402", s.labels(), boolValue(s.returns))
+	}
+
+	mw.header("x402_endpoint_healthy", "gauge", "Whether all health checks passed on the last check.")
+	for _, s := range states {
+		mw.sample("x402_endpoint_healthy", s.labels(), boolValue(s.healthy))
+	}
+
+	mw.header("x402_endpoint_latency_seconds", "histogram", "Latency of the unpaid request.")
+	for _, s := range states {
+		for i, upper := range e.buckets {
+			mw.sample("x402_endpoint_latency_seconds_bucket", s.labels("le", formatFloat(upper)), float64(s.bucketCounts[i]))
+		}
+		mw.sample("x402_endpoint_latency_seconds_bucket", s.labels("le", "+Inf"), float64(s.latencyCount))
+		mw.sample("x402_endpoint_latency_seconds_sum", s.labels(), s.latencySum)
+		mw.sample("x402_endpoint_latency_seconds_count", s.labels(), float64(s.latencyCount))
+	}
+
+	mw.header("x402_endpoint_payment_options", "gauge", "Number of payment options advertised.")
+	for _, s := range states {
+		mw.sample("x402_endpoint_payment_options", s.labels(), float64(s.options))
+	}
+
+	mw.header("x402_endpoint_price", "gauge", "Price in token units per network, for known tokens.")
+	for _, s := range states {
+		for _, p := range s.prices {
+			mw.sample("x402_endpoint_price", s.labels("network", p.network, "asset", p.asset, "token", p.token), p.value)
+		}
+	}
+
+	mw.header("x402_endpoint_agent_card", "gauge", "Whether an A2A agent card was discovered.")
+	for _, s := range states {
+		if s.agentCard != nil {
+			mw.sample("x402_endpoint_agent_card", s.labels(), boolValue(*s.agentCard))
+		}
+	}
+
+	mw.header("x402_endpoint_last_check_timestamp_seconds", "gauge", "Unix time of the last check.")
+	for _, s := range states {
+		mw.sample("x402_endpoint_last_check_timestamp_seconds", s.labels(), float64(s.lastCheck.Unix()))
+	}
+
+	return mw.n, mw.err
+}
+
+// labels returns the endpoint labels followed by extra name/value pairs.
+func (s *endpointState) labels(extra ...string) string {
+	pairs := append([]string{"url", s.url, "method", s.method}, extra...)
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", pairs[i], escapeLabel(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
+
+// metricWriter writes exposition lines and keeps the first error.
+type metricWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (m *metricWriter) printf(format string, args ...interface{}) {
+	if m.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(m.w, format, args...)
+	m.n += int64(n)
+	m.err = err
+}
+
+func (m *metricWriter) header(name, kind, help string) {
+	m.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (m *metricWriter) sample(name, labels string, value float64) {
+	m.printf("%s%s %s\n", name, labels, formatFloat(value))
+}
@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/output"
+)
+
+func render(t *testing.T, e *Exporter) string {
+	t.Helper()
+	var sb strings.Builder
+	_, err := e.WriteTo(&sb)
+	require.NoError(t, err)
+	return sb.String()
+}
+
+func TestExporter_Observe(t *testing.T) {
+	e := New(WithBuckets([]float64{0.1, 1}))
+	at := time.Unix(1736467200, 0)
+
+	e.Observe(&output.HealthResult{
+		URL:       "https://api.example.com/data",
+		Method:    "GET",
+		Status:    402,
+		LatencyMs: 250,
+		PaymentOptions: []output.PaymentOptionDisplay{
+			{Network: "eip155:84532", Asset: "0x036cbd53842c5426634e7929541ec2318f3dcf7e", Amount: "10000"},
+			{Network: "eip155:84532", Asset: "0xunknown", Amount: "5"},
+		},
+		AgentCard: &a2a.Result{Found: true},
+	}, at)
+
+	text := render(t, e)
+	labels := `url="https://api.example.com/data",method="GET"`
+
+	assert.Contains(t, text, "# TYPE x402_endpoint_up gauge\n")
+	assert.Contains(t, text, "x402_endpoint_up{"+labels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_returns_402{"+labels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_healthy{"+labels+"} 1\n")
+	assert.Contains(t, text, "# TYPE x402_endpoint_latency_seconds histogram\n")
+	assert.Contains(t, text, "x402_endpoint_latency_seconds_bucket{"+labels+`,le="0.1"} 0`+"\n")
+	assert.Contains(t, text, "x402_endpoint_latency_seconds_bucket{"+labels+`,le="1"} 1`+"\n")
+	assert.Contains(t, text, "x402_endpoint_latency_seconds_bucket{"+labels+`,le="+Inf"} 1`+"\n")
+	assert.Contains(t, text, "x402_endpoint_latency_seconds_sum{"+labels+"} 0.25\n")
+	assert.Contains(t, text, "x402_endpoint_payment_options{"+labels+"} 2\n")
+	assert.Contains(t, text, "x402_endpoint_price{"+labels+`,network="eip155:84532",asset="0x036cbd53842c5426634e7929541ec2318f3dcf7e",token="USDC"} 0.01`+"\n")
+	assert.NotContains(t, text, "0xunknown", "unknown tokens have no price")
+	assert.Contains(t, text, "x402_endpoint_agent_card{"+labels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_last_check_timestamp_seconds{"+labels+"} 1.7364672e+09\n")
+
+	// An outage keeps the histogram but flips the gauges
+	e.Observe(&output.HealthResult{URL: "https://api.example.com/data", Method: "GET", ExitCode: 3}, at)
+	text = render(t, e)
+	assert.Contains(t, text, "x402_endpoint_up{"+labels+"} 0\n")
+	assert.Contains(t, text, "x402_endpoint_healthy{"+labels+"} 0\n")
+	assert.Contains(t, text, "x402_endpoint_latency_seconds_count{"+labels+"} 1\n")
+	assert.Contains(t, text, "x402_endpoint_agent_card{"+labels+"} 1\n")
+}
+
+func TestExporter_EscapesLabels(t *testing.T) {
+	e := New()
+	e.Observe(&output.HealthResult{URL: `https://x.example/"q"\`, Method: "GET"}, time.Now())
+	assert.Contains(t, render(t, e), `x402_endpoint_up{url="https://x.example/\"q\"\\",method="GET"} 0`)
+}
+
+func TestExporter_Handler(t *testing.T) {
+	e := New()
+	e.Observe(&output.HealthResult{URL: "https://api.example.com", Method: "GET", Status: 402}, time.Now())
+
+	rec := httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, rec.Body.String(), "x402_endpoint_returns_402")
+
+	rec = httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,352 @@
+// Package facilitator implements an offline stand-in for an x402 facilitator.
+//
+// It speaks the standard facilitator API (/verify, /settle, /supported) so a
+// resource server under development can be exercised end-to-end without any
+// network access. Verification is performed locally (EIP-712 signature
+// recovery for EVM, transaction inspection for Solana); settlement is
+// simulated and returns a fake transaction hash.
+package facilitator
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+
+	"github.com/port402/x402-cli/internal/tokens"
+	"github.com/port402/x402-cli/internal/wallet"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// SchemeExact is the only payment scheme the local facilitator understands.
+const SchemeExact = "exact"
+
+// maxRequestSize limits facilitator request bodies (1MB).
+const maxRequestSize = 1 << 20
+
+// Invalid reasons reported by /verify and /settle.
+const (
+	ReasonInvalidRequest        = "invalid_request"
+	ReasonInvalidVersion        = "invalid_x402_version"
+	ReasonUnsupportedScheme     = "unsupported_scheme"
+	ReasonInvalidNetwork        = "invalid_network"
+	ReasonInvalidPayload        = "invalid_payload"
+	ReasonEVMSignature          = "invalid_exact_evm_payload_signature"
+	ReasonEVMRecipientMismatch  = "invalid_exact_evm_payload_recipient_mismatch"
+	ReasonEVMValue              = "invalid_exact_evm_payload_authorization_value"
+	ReasonEVMValidAfter         = "invalid_exact_evm_payload_authorization_valid_after"
+	ReasonEVMValidBefore        = "invalid_exact_evm_payload_authorization_valid_before"
+	ReasonEVMNonceUsed          = "invalid_exact_evm_payload_authorization_nonce_used"
+	ReasonSVMTransaction        = "invalid_exact_svm_payload_transaction"
+	ReasonSVMTransactionSettled = "invalid_exact_svm_payload_transaction_already_settled"
+)
+
+// Server is an in-memory facilitator. It is safe for concurrent use.
+type Server struct {
+	mu      sync.Mutex
+	settled map[string]bool // payment IDs already settled (replay protection)
+	now     func() time.Time
+	log     io.Writer
+}
+
+// Option configures the Server.
+type Option func(*Server)
+
+// WithClock overrides the time source used for authorization validity checks.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// WithLog writes a line per handled request to w.
+func WithLog(w io.Writer) Option {
+	return func(s *Server) {
+		s.log = w
+	}
+}
+
+// New creates a new Server with the given options.
+func New(opts ...Option) *Server {
+	s := &Server{
+		settled: make(map[string]bool),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the facilitator API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /verify", s.handleVerify)
+	mux.HandleFunc("POST /settle", s.handleSettle)
+	mux.HandleFunc("GET /supported", s.handleSupported)
+	return mux
+}
+
+// verification is the outcome of checking a payment against its requirements.
+type verification struct {
+	payer     string
+	paymentID string // Identifies the payment for replay protection
+	reason    string // Empty when valid
+	message   string
+}
+
+func invalid(reason, format string, args ...interface{}) verification {
+	return verification{reason: reason, message: fmt.Sprintf(format, args...)}
+}
+
+// Verify checks a payment payload against its requirements without settling it.
+func (s *Server) Verify(req *x402.FacilitatorRequest) *x402.VerifyResponse {
+	v := s.verify(req)
+	return &x402.VerifyResponse{
+		IsValid:        v.reason == "",
+		InvalidReason:  v.reason,
+		InvalidMessage: v.message,
+		Payer:          v.payer,
+	}
+}
+
+// Settle verifies a payment and, if valid, simulates settling it on-chain.
+// Each payment can only be settled once.
+func (s *Server) Settle(req *x402.FacilitatorRequest) *x402.PaymentResponse {
+	network := req.PaymentRequirements.Network
+	v := s.verify(req)
+	if v.reason != "" {
+		return &x402.PaymentResponse{
+			Success:     false,
+			Network:     network,
+			Payer:       v.payer,
+			Error:       v.message,
+			ErrorReason: v.reason,
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Re-check under the lock: a concurrent settle may have won the race
+	if s.settled[v.paymentID] {
+		reason := ReasonEVMNonceUsed
+		if x402.IsSolanaNetwork(network) {
+			reason = ReasonSVMTransactionSettled
+		}
+		return &x402.PaymentResponse{
+			Success:     false,
+			Network:     network,
+			Payer:       v.payer,
+			Error:       "payment already settled",
+			ErrorReason: reason,
+		}
+	}
+	s.settled[v.paymentID] = true
+
+	return &x402.PaymentResponse{
+		Success:     true,
+		Transaction: fakeTransaction(network, v.paymentID),
+		Network:     network,
+		Payer:       v.payer,
+	}
+}
+
+// Supported lists the scheme/network pairs this facilitator verifies. v1
+// kinds use the plain network names v1 clients send, v2 kinds use CAIP-2.
+func (s *Server) Supported() *x402.SupportedResponse {
+	resp := &x402.SupportedResponse{Kinds: []x402.SupportedKind{}}
+	for _, protocol := range x402.Protocols() {
+		for _, n := range tokens.ListNetworks() {
+			network := n.ID
+			if protocol.Version == x402.ProtocolV1 {
+				if network = tokens.V1NetworkName(n.ID); network == "" {
+					continue
+				}
+			}
+			resp.Kinds = append(resp.Kinds, x402.SupportedKind{
+				X402Version: protocol.Version,
+				Scheme:      SchemeExact,
+				Network:     network,
+			})
+		}
+	}
+	return resp
+}
+
+func (s *Server) verify(req *x402.FacilitatorRequest) verification {
+	payload := &req.PaymentPayload
+	option := &req.PaymentRequirements
+
+	if _, err := x402.LookupProtocol(payload.X402Version); err != nil {
+		return invalid(ReasonInvalidVersion, "%v", err)
+	}
+	if payload.GetScheme() != SchemeExact || option.Scheme != SchemeExact {
+		return invalid(ReasonUnsupportedScheme, "only the %q scheme is supported", SchemeExact)
+	}
+	if !sameNetwork(payload.GetNetwork(), option.Network) {
+		return invalid(ReasonInvalidNetwork, "payload network %q does not match requirement network %q", payload.GetNetwork(), option.Network)
+	}
+
+	switch {
+	case x402.IsEVMNetwork(option.Network):
+		return s.verifyEVM(payload, option)
+	case x402.IsSolanaNetwork(option.Network):
+		return s.verifySolana(payload, option)
+	default:
+		return invalid(ReasonInvalidNetwork, "unsupported network %q", option.Network)
+	}
+}
+
+func (s *Server) verifyEVM(payload *x402.PaymentPayload, option *x402.PaymentRequirement) verification {
+	var evm x402.ExactEvmPayload
+	if err := json.Unmarshal(payload.Payload, &evm); err != nil {
+		return invalid(ReasonInvalidPayload, "invalid EVM payload: %v", err)
+	}
+	auth := evm.Authorization
+
+	chainID, err := x402.ExtractChainID(option.Network)
+	if err != nil {
+		return invalid(ReasonInvalidNetwork, "%v", err)
+	}
+
+	if !strings.EqualFold(auth.To, option.PayTo) {
+		return invalid(ReasonEVMRecipientMismatch, "authorization pays %s, requirement expects %s", auth.To, option.PayTo)
+	}
+
+	value, ok := new(big.Int).SetString(auth.Value, 10)
+	required, ok2 := new(big.Int).SetString(option.GetAmount(), 10)
+	if !ok || !ok2 || value.Cmp(required) != 0 {
+		return invalid(ReasonEVMValue, "authorization value %q does not match required amount %q", auth.Value, option.GetAmount())
+	}
+
+	now := s.now().Unix()
+	if validAfter, err := strconv.ParseInt(auth.ValidAfter, 10, 64); err != nil || now < validAfter {
+		return invalid(ReasonEVMValidAfter, "authorization not yet valid (validAfter %s)", auth.ValidAfter)
+	}
+	if validBefore, err := strconv.ParseInt(auth.ValidBefore, 10, 64); err != nil || now >= validBefore {
+		return invalid(ReasonEVMValidBefore, "authorization expired (validBefore %s)", auth.ValidBefore)
+	}
+
+	signer, err := wallet.RecoverTransferAuthorizationSigner(option, chainID, auth, evm.Signature)
+	if err != nil {
+		return invalid(ReasonEVMSignature, "%v", err)
+	}
+	if !strings.EqualFold(signer, auth.From) {
+		return invalid(ReasonEVMSignature, "signature recovers to %s, authorization is from %s", signer, auth.From)
+	}
+
+	v := verification{
+		payer:     auth.From,
+		paymentID: strings.ToLower(fmt.Sprintf("%d:%s:%s:%s", chainID, option.Asset, auth.From, auth.Nonce)),
+	}
+	if s.isSettled(v.paymentID) {
+		return invalid(ReasonEVMNonceUsed, "authorization nonce %s already used", auth.Nonce)
+	}
+	return v
+}
+
+func (s *Server) verifySolana(payload *x402.PaymentPayload, option *x402.PaymentRequirement) verification {
+	var svm x402.ExactSvmPayload
+	if err := json.Unmarshal(payload.Payload, &svm); err != nil || svm.Transaction == "" {
+		return invalid(ReasonInvalidPayload, "invalid Solana payload: missing transaction")
+	}
+
+	transfer, err := wallet.VerifySolanaTransaction(svm.Transaction, option)
+	if err != nil {
+		return invalid(ReasonSVMTransaction, "%v", err)
+	}
+
+	v := verification{payer: transfer.Payer, paymentID: svm.Transaction}
+	if s.isSettled(v.paymentID) {
+		return invalid(ReasonSVMTransactionSettled, "transaction already settled")
+	}
+	return v
+}
+
+func (s *Server) isSettled(paymentID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settled[paymentID]
+}
+
+// sameNetwork compares networks, treating Solana aliases as their CAIP-2 form.
+func sameNetwork(a, b string) bool {
+	if x402.IsSolanaNetwork(a) && x402.IsSolanaNetwork(b) {
+		return x402.NormalizeSolanaNetwork(a) == x402.NormalizeSolanaNetwork(b)
+	}
+	return strings.EqualFold(a, b)
+}
+
+// fakeTransaction derives a deterministic, chain-shaped transaction identifier.
+func fakeTransaction(network, paymentID string) string {
+	if x402.IsSolanaNetwork(network) {
+		sum := sha512.Sum512([]byte(paymentID))
+		return base58.Encode(sum[:])
+	}
+	return "0x" + common.Bytes2Hex(crypto.Keccak256([]byte(paymentID)))
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	resp := s.Verify(req)
+	s.logf("verify %s %s valid=%t %s", req.PaymentRequirements.Network, resp.Payer, resp.IsValid, resp.InvalidReason)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSettle(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	resp := s.Settle(req)
+	s.logf("settle %s %s success=%t %s%s", req.PaymentRequirements.Network, resp.Payer, resp.Success, resp.Transaction, resp.ErrorReason)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSupported(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Supported())
+}
+
+// decodeRequest parses a facilitator request body, writing a 400 response on failure.
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request) (*x402.FacilitatorRequest, bool) {
+	var req x402.FacilitatorRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(&req); err != nil {
+		s.logf("%s rejected: %v", r.URL.Path, err)
+		writeJSON(w, http.StatusBadRequest, &x402.VerifyResponse{
+			IsValid:        false,
+			InvalidReason:  ReasonInvalidRequest,
+			InvalidMessage: fmt.Sprintf("invalid JSON: %v", err),
+		})
+		return nil, false
+	}
+	return &req, true
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.log == nil {
+		return
+	}
+	fmt.Fprintf(s.log, "%s %s\n", s.now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
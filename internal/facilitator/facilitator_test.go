@@ -0,0 +1,361 @@
+package facilitator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/wallet"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// Test private key from Foundry/Anvil - NEVER use for real funds
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+func evmRequirement() x402.PaymentRequirement {
+	return x402.PaymentRequirement{
+		Scheme:            "exact",
+		Network:           "eip155:84532",
+		Amount:            "10000",
+		Asset:             "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		PayTo:             "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		MaxTimeoutSeconds: 300,
+		Extra:             map[string]interface{}{"name": "USDC", "version": "2"},
+	}
+}
+
+// signedEVMRequest signs a payment for option and wraps it in a facilitator request.
+func signedEVMRequest(t *testing.T, option x402.PaymentRequirement) *x402.FacilitatorRequest {
+	t.Helper()
+	key, err := wallet.LoadFromHex(testPrivateKey)
+	require.NoError(t, err)
+
+	chainID, err := x402.ExtractChainID(option.Network)
+	require.NoError(t, err)
+
+	signer := wallet.NewEVMSigner(key)
+	signed, err := signer.Sign(context.Background(), wallet.PrepareSignParams(&option, signer.Address(), chainID))
+	require.NoError(t, err)
+
+	payload := x402.BuildPayloadV2(x402.ResourceInfo{URL: "https://example.com"}, &option, signed.Signature, signed.Authorization)
+	return toFacilitatorRequest(t, payload, option)
+}
+
+// toFacilitatorRequest round-trips a payload through JSON as a resource server would.
+func toFacilitatorRequest(t *testing.T, payload interface{}, option x402.PaymentRequirement) *x402.FacilitatorRequest {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req := &x402.FacilitatorRequest{PaymentRequirements: option}
+	require.NoError(t, json.Unmarshal(raw, &req.PaymentPayload))
+	req.X402Version = req.PaymentPayload.X402Version
+	return req
+}
+
+func TestVerify_EVM_Valid(t *testing.T) {
+	req := signedEVMRequest(t, evmRequirement())
+
+	resp := New().Verify(req)
+
+	assert.True(t, resp.IsValid, resp.InvalidMessage)
+	assert.Empty(t, resp.InvalidReason)
+	assert.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", resp.Payer)
+}
+
+func TestVerify_EVM_V1Payload(t *testing.T) {
+	option := evmRequirement()
+	option.Amount = ""
+	option.MaxAmountRequired = "10000"
+
+	key, err := wallet.LoadFromHex(testPrivateKey)
+	require.NoError(t, err)
+	signer := wallet.NewEVMSigner(key)
+	signed, err := signer.Sign(context.Background(), wallet.PrepareSignParams(&option, signer.Address(), 84532))
+	require.NoError(t, err)
+
+	req := toFacilitatorRequest(t, x402.BuildPayloadV1(&option, signed.Signature, signed.Authorization), option)
+
+	resp := New().Verify(req)
+	assert.True(t, resp.IsValid, resp.InvalidMessage)
+}
+
+func TestVerify_EVM_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(req *x402.FacilitatorRequest)
+		reason string
+	}{
+		{
+			name: "recipient mismatch",
+			mutate: func(req *x402.FacilitatorRequest) {
+				req.PaymentRequirements.PayTo = "0x0000000000000000000000000000000000000001"
+			},
+			reason: ReasonEVMRecipientMismatch,
+		},
+		{
+			name:   "amount mismatch",
+			mutate: func(req *x402.FacilitatorRequest) { req.PaymentRequirements.Amount = "20000" },
+			reason: ReasonEVMValue,
+		},
+		{
+			name:   "network mismatch",
+			mutate: func(req *x402.FacilitatorRequest) { req.PaymentRequirements.Network = "eip155:8453" },
+			reason: ReasonInvalidNetwork,
+		},
+		{
+			name:   "unsupported scheme",
+			mutate: func(req *x402.FacilitatorRequest) { req.PaymentRequirements.Scheme = "upto" },
+			reason: ReasonUnsupportedScheme,
+		},
+		{
+			name:   "domain mismatch",
+			mutate: func(req *x402.FacilitatorRequest) { req.PaymentRequirements.Extra["name"] = "USD Coin" },
+			reason: ReasonEVMSignature,
+		},
+		{
+			name:   "unknown version",
+			mutate: func(req *x402.FacilitatorRequest) { req.PaymentPayload.X402Version = 3 },
+			reason: ReasonInvalidVersion,
+		},
+		{
+			name:   "malformed payload",
+			mutate: func(req *x402.FacilitatorRequest) { req.PaymentPayload.Payload = json.RawMessage(`"nope"`) },
+			reason: ReasonInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := signedEVMRequest(t, evmRequirement())
+			tt.mutate(req)
+
+			resp := New().Verify(req)
+
+			assert.False(t, resp.IsValid)
+			assert.Equal(t, tt.reason, resp.InvalidReason)
+			assert.NotEmpty(t, resp.InvalidMessage)
+		})
+	}
+}
+
+func TestVerify_EVM_Expired(t *testing.T) {
+	req := signedEVMRequest(t, evmRequirement())
+	later := func() time.Time { return time.Now().Add(time.Hour) }
+
+	resp := New(WithClock(later)).Verify(req)
+
+	assert.False(t, resp.IsValid)
+	assert.Equal(t, ReasonEVMValidBefore, resp.InvalidReason)
+}
+
+func TestSettle_EVM_OnlyOnce(t *testing.T) {
+	req := signedEVMRequest(t, evmRequirement())
+	f := New()
+
+	first := f.Settle(req)
+	require.True(t, first.Success, first.Error)
+	assert.Len(t, first.Transaction, 66)
+	assert.Equal(t, "eip155:84532", first.Network)
+
+	second := f.Settle(req)
+	assert.False(t, second.Success)
+	assert.Equal(t, ReasonEVMNonceUsed, second.ErrorReason)
+
+	// Verify also rejects an already-settled authorization
+	assert.Equal(t, ReasonEVMNonceUsed, f.Verify(req).InvalidReason)
+}
+
+// solanaFixture builds a partially-signed transfer like SolanaSigner.Sign does.
+type solanaFixture struct {
+	owner    solana.PrivateKey
+	feePayer solana.PublicKey
+	payTo    solana.PublicKey
+	mint     solana.PublicKey
+}
+
+func newSolanaFixture(t *testing.T) *solanaFixture {
+	t.Helper()
+	return &solanaFixture{
+		owner:    solana.NewWallet().PrivateKey,
+		feePayer: solana.NewWallet().PublicKey(),
+		payTo:    solana.NewWallet().PublicKey(),
+		mint:     solana.MustPublicKeyFromBase58("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"),
+	}
+}
+
+func (f *solanaFixture) requirement() x402.PaymentRequirement {
+	return x402.PaymentRequirement{
+		Scheme:  "exact",
+		Network: x402.SolanaDevnet,
+		Amount:  "10000",
+		Asset:   f.mint.String(),
+		PayTo:   f.payTo.String(),
+		Extra:   map[string]interface{}{"feePayer": f.feePayer.String()},
+	}
+}
+
+func (f *solanaFixture) transaction(t *testing.T, amount uint64, createATA bool) string {
+	t.Helper()
+	source, _, err := solana.FindAssociatedTokenAddress(f.owner.PublicKey(), f.mint)
+	require.NoError(t, err)
+	dest, _, err := solana.FindAssociatedTokenAddress(f.payTo, f.mint)
+	require.NoError(t, err)
+
+	var instructions []solana.Instruction
+	if createATA {
+		instructions = append(instructions, associatedtokenaccount.NewCreateInstruction(f.feePayer, f.payTo, f.mint).Build())
+	}
+	instructions = append(instructions,
+		computebudget.NewSetComputeUnitLimitInstruction(200_000).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(1).Build(),
+		token.NewTransferCheckedInstruction(amount, 6, source, f.mint, dest, f.owner.PublicKey(), nil).Build(),
+	)
+
+	tx, err := solana.NewTransaction(instructions, solana.Hash{1, 2, 3}, solana.TransactionPayer(f.feePayer))
+	require.NoError(t, err)
+	_, err = tx.PartialSign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key.Equals(f.owner.PublicKey()) {
+			return &f.owner
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	encoded, err := tx.ToBase64()
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestVerify_Solana(t *testing.T) {
+	fixture := newSolanaFixture(t)
+	option := fixture.requirement()
+
+	t.Run("valid", func(t *testing.T) {
+		payload := x402.BuildPayloadV2Solana(x402.ResourceInfo{}, &option, fixture.transaction(t, 10000, false))
+		resp := New().Verify(toFacilitatorRequest(t, payload, option))
+
+		assert.True(t, resp.IsValid, resp.InvalidMessage)
+		assert.Equal(t, fixture.owner.PublicKey().String(), resp.Payer)
+	})
+
+	t.Run("valid with ATA creation", func(t *testing.T) {
+		payload := x402.BuildPayloadV2Solana(x402.ResourceInfo{}, &option, fixture.transaction(t, 10000, true))
+		resp := New().Verify(toFacilitatorRequest(t, payload, option))
+
+		assert.True(t, resp.IsValid, resp.InvalidMessage)
+	})
+
+	t.Run("wrong amount", func(t *testing.T) {
+		payload := x402.BuildPayloadV2Solana(x402.ResourceInfo{}, &option, fixture.transaction(t, 1, false))
+		resp := New().Verify(toFacilitatorRequest(t, payload, option))
+
+		assert.False(t, resp.IsValid)
+		assert.Equal(t, ReasonSVMTransaction, resp.InvalidReason)
+		assert.Contains(t, resp.InvalidMessage, "amount mismatch")
+	})
+
+	t.Run("network alias", func(t *testing.T) {
+		aliased := option
+		aliased.Network = "solana-devnet"
+		payload := x402.BuildPayloadV2Solana(x402.ResourceInfo{}, &option, fixture.transaction(t, 10000, false))
+		resp := New().Verify(toFacilitatorRequest(t, payload, aliased))
+
+		assert.True(t, resp.IsValid, resp.InvalidMessage)
+	})
+
+	t.Run("settle once", func(t *testing.T) {
+		payload := x402.BuildPayloadV2Solana(x402.ResourceInfo{}, &option, fixture.transaction(t, 10000, false))
+		req := toFacilitatorRequest(t, payload, option)
+		f := New()
+
+		first := f.Settle(req)
+		require.True(t, first.Success, first.Error)
+		assert.NotEmpty(t, first.Transaction)
+
+		second := f.Settle(req)
+		assert.False(t, second.Success)
+		assert.Equal(t, ReasonSVMTransactionSettled, second.ErrorReason)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(New().Handler())
+	defer server.Close()
+
+	post := func(path string, body []byte) *http.Response {
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("verify", func(t *testing.T) {
+		body, err := json.Marshal(signedEVMRequest(t, evmRequirement()))
+		require.NoError(t, err)
+
+		resp := post("/verify", body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var verify x402.VerifyResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&verify))
+		assert.True(t, verify.IsValid, verify.InvalidMessage)
+	})
+
+	t.Run("settle", func(t *testing.T) {
+		body, err := json.Marshal(signedEVMRequest(t, evmRequirement()))
+		require.NoError(t, err)
+
+		resp := post("/settle", body)
+		defer resp.Body.Close()
+
+		var settle x402.PaymentResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&settle))
+		assert.True(t, settle.Success, settle.Error)
+		assert.NotEmpty(t, settle.Transaction)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		resp := post("/verify", []byte("{"))
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("supported", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/supported")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var supported x402.SupportedResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&supported))
+		assert.Contains(t, supported.Kinds, x402.SupportedKind{X402Version: 2, Scheme: "exact", Network: "eip155:84532"})
+		assert.Contains(t, supported.Kinds, x402.SupportedKind{X402Version: 1, Scheme: "exact", Network: "base-sepolia"})
+		assert.Contains(t, supported.Kinds, x402.SupportedKind{X402Version: 1, Scheme: "exact", Network: "solana-devnet"})
+
+		// v1 clients send plain network names, never CAIP-2
+		for _, kind := range supported.Kinds {
+			if kind.X402Version == 1 {
+				assert.NotContains(t, kind.Network, ":", "v1 kind %s", kind.Network)
+				assert.True(t, x402.IsEVMNetwork(kind.Network) || x402.IsSolanaNetwork(kind.Network), "v1 kind %s", kind.Network)
+			}
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/verify")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
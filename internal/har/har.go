@@ -0,0 +1,368 @@
+// Package har records HTTP exchanges in HAR 1.2 format, with x402 payment
+// headers decoded into comments for bug reports to API providers.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// Version is the HAR specification version written by the recorder.
+const Version = "1.2"
+
+// redactedValue replaces signature material when redaction is enabled.
+const redactedValue = "[REDACTED]"
+
+// redactedFields are payload fields that carry signature material.
+var redactedFields = map[string]bool{
+	"signature":   true,
+	"transaction": true,
+}
+
+// HAR is the top-level HAR document.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the HAR log object.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifies the application that wrote the HAR.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request/response exchange.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	Comment         string   `json:"comment,omitempty"`
+}
+
+// Request is the HAR request object.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response is the HAR response object.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// NameValue is a header, query parameter or cookie.
+type NameValue struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// PostData describes a request body. Binary bodies, such as multipart file
+// uploads, are base64-encoded like response content.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Content describes a response body.
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Timings breaks down the time spent on an exchange, in milliseconds.
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Recorder collects exchanges from a client.Client. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+	redact  bool
+	creator Creator
+}
+
+// Option configures the Recorder.
+type Option func(*Recorder)
+
+// WithRedaction replaces signatures and signed transactions in payment
+// headers with a placeholder. Redacted headers remain decodable.
+func WithRedaction() Option {
+	return func(r *Recorder) {
+		r.redact = true
+	}
+}
+
+// WithCreator sets the creator recorded in the HAR log.
+func WithCreator(name, version string) Option {
+	return func(r *Recorder) {
+		r.creator = Creator{Name: name, Version: version}
+	}
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder(opts ...Option) *Recorder {
+	r := &Recorder{
+		entries: []Entry{},
+		creator: Creator{Name: "x402-cli", Version: "dev"},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Record implements client.Recorder.
+func (r *Recorder) Record(e *client.Exchange) {
+	entry := r.buildEntry(e)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// HAR returns the recorded exchanges as a HAR document.
+func (r *Recorder) HAR() *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &HAR{Log: Log{
+		Version: Version,
+		Creator: r.creator,
+		Entries: append([]Entry{}, r.entries...),
+	}}
+}
+
+// WriteFile writes the HAR document to path.
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write HAR file: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Recorder) buildEntry(e *client.Exchange) Entry {
+	ms := float64(e.Duration.Microseconds()) / 1000
+
+	req := e.Request
+	entry := Entry{
+		StartedDateTime: e.Started.UTC().Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: Request{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []NameValue{},
+			Headers:     r.headers(req.Header),
+			QueryString: queryString(req),
+			HeadersSize: -1,
+			BodySize:    len(e.RequestBody),
+		},
+		Response: Response{
+			Cookies:     []NameValue{},
+			Headers:     []NameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: Timings{Wait: ms},
+	}
+
+	if len(e.RequestBody) > 0 {
+		body := content(req.Header.Get("Content-Type"), e.RequestBody)
+		entry.Request.PostData = &PostData{
+			MimeType: body.MimeType,
+			Text:     body.Text,
+			Encoding: body.Encoding,
+		}
+	}
+
+	if e.Err != nil {
+		entry.Comment = fmt.Sprintf("request failed: %v", e.Err)
+	}
+
+	if resp := e.Response; resp != nil {
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprintf("%d", resp.StatusCode)))
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = r.headers(resp.Header)
+		entry.Response.RedirectURL = resp.Header.Get("Location")
+		entry.Response.BodySize = len(e.ResponseBody)
+		entry.Response.Content = content(resp.Header.Get("Content-Type"), e.ResponseBody)
+	}
+
+	return entry
+}
+
+// headers converts HTTP headers to sorted HAR name/value pairs, decoding
+// payment headers into comments.
+func (r *Recorder) headers(h http.Header) []NameValue {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := []NameValue{}
+	for _, name := range names {
+		for _, value := range h[name] {
+			nv := NameValue{Name: name, Value: value}
+			if isPaymentHeader(name) {
+				nv.Value, nv.Comment = r.decodePaymentHeader(value)
+			}
+			result = append(result, nv)
+		}
+	}
+	return result
+}
+
+// decodePaymentHeader returns the (possibly redacted) header value and its
+// decoded JSON for the comment.
+func (r *Recorder) decodePaymentHeader(value string) (string, string) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if r.redact {
+			return redactedValue, "not valid base64"
+		}
+		return value, "not valid base64"
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(decoded, &doc); err != nil {
+		if r.redact {
+			return redactedValue, "not valid JSON"
+		}
+		return value, "not valid JSON"
+	}
+
+	if r.redact && redactFields(doc) {
+		redacted, err := json.Marshal(doc)
+		if err == nil {
+			value = base64.StdEncoding.EncodeToString(redacted)
+		}
+	}
+
+	pretty, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return value, string(decoded)
+	}
+	return value, string(pretty)
+}
+
+// redactFields replaces signature material anywhere in doc, reporting
+// whether anything was replaced.
+func redactFields(doc interface{}) bool {
+	changed := false
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if _, ok := child.(string); ok && redactedFields[key] {
+				v[key] = redactedValue
+				changed = true
+				continue
+			}
+			if redactFields(child) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if redactFields(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// isPaymentHeader reports whether name carries a base64 x402 document in any protocol version.
+func isPaymentHeader(name string) bool {
+	for _, p := range x402.Protocols() {
+		for _, h := range []string{p.RequirementsHeader, p.PaymentHeader, p.ResponseHeader} {
+			if h != "" && strings.EqualFold(h, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func queryString(req *http.Request) []NameValue {
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := []NameValue{}
+	for _, name := range names {
+		for _, value := range query[name] {
+			result = append(result, NameValue{Name: name, Value: value})
+		}
+	}
+	return result
+}
+
+// content builds the content of a body, base64-encoding binary bodies.
+func content(mimeType string, body []byte) Content {
+	c := Content{Size: len(body), MimeType: mimeType}
+	if len(body) == 0 {
+		return c
+	}
+	if utf8.Valid(body) {
+		c.Text = string(body)
+	} else {
+		c.Text = base64.StdEncoding.EncodeToString(body)
+		c.Encoding = "base64"
+	}
+	return c
+}
@@ -0,0 +1,161 @@
+package har
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+const testPayment = `{"x402Version":2,"payload":{"signature":"0xdeadbeef","authorization":{"from":"0x1","nonce":"0x2"}}}`
+
+// newPaymentServer returns 402 until a payment header is sent, then 200 with a payment response.
+func newPaymentServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(x402.HeaderPaymentSignature) == "" {
+			w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"accepts":[]}`)))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(x402.HeaderPaymentResponse, base64.StdEncoding.EncodeToString([]byte(`{"success":true,"transaction":"0xabc","network":"eip155:84532"}`)))
+		w.Write([]byte(`{"data":"paid"}`))
+	}))
+}
+
+func runExchange(t *testing.T, rec *Recorder) {
+	server := newPaymentServer(t)
+	defer server.Close()
+
+	c := client.New(client.WithRecorder(rec))
+
+	resp, err := c.Request(context.Background(), http.MethodGet, server.URL+"/data?q=1", nil, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	payment := base64.StdEncoding.EncodeToString([]byte(testPayment))
+	resp, err = c.Request(context.Background(), http.MethodPost, server.URL+"/data?q=1",
+		map[string]string{x402.HeaderPaymentSignature: payment, "Content-Type": "application/json"}, []byte(`{"in":1}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func findHeader(headers []NameValue, name string) *NameValue {
+	for i := range headers {
+		if headers[i].Name == name {
+			return &headers[i]
+		}
+	}
+	return nil
+}
+
+func TestRecorder_PaymentExchange(t *testing.T) {
+	rec := NewRecorder(WithCreator("x402-cli", "1.2.3"))
+	runExchange(t, rec)
+
+	doc := rec.HAR()
+	assert.Equal(t, "1.2", doc.Log.Version)
+	assert.Equal(t, Creator{Name: "x402-cli", Version: "1.2.3"}, doc.Log.Creator)
+	require.Len(t, doc.Log.Entries, 2)
+
+	initial := doc.Log.Entries[0]
+	assert.Equal(t, "GET", initial.Request.Method)
+	assert.Equal(t, []NameValue{{Name: "q", Value: "1"}}, initial.Request.QueryString)
+	assert.Equal(t, 402, initial.Response.Status)
+	assert.Equal(t, "Payment Required", initial.Response.StatusText)
+	required := findHeader(initial.Response.Headers, "Payment-Required")
+	require.NotNil(t, required)
+	assert.Contains(t, required.Comment, `"x402Version": 2`)
+
+	paid := doc.Log.Entries[1]
+	assert.Equal(t, "POST", paid.Request.Method)
+	require.NotNil(t, paid.Request.PostData)
+	assert.Equal(t, `{"in":1}`, paid.Request.PostData.Text)
+	assert.Equal(t, "application/json", paid.Request.PostData.MimeType)
+
+	signature := findHeader(paid.Request.Headers, "Payment-Signature")
+	require.NotNil(t, signature)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(testPayment)), signature.Value)
+	assert.Contains(t, signature.Comment, "0xdeadbeef")
+
+	assert.Equal(t, 200, paid.Response.Status)
+	assert.Equal(t, `{"data":"paid"}`, paid.Response.Content.Text)
+	response := findHeader(paid.Response.Headers, "Payment-Response")
+	require.NotNil(t, response)
+	assert.Contains(t, response.Comment, `"transaction": "0xabc"`)
+}
+
+func TestRecorder_Redaction(t *testing.T) {
+	rec := NewRecorder(WithRedaction())
+	runExchange(t, rec)
+
+	doc := rec.HAR()
+	require.Len(t, doc.Log.Entries, 2)
+
+	signature := findHeader(doc.Log.Entries[1].Request.Headers, "Payment-Signature")
+	require.NotNil(t, signature)
+	assert.NotContains(t, signature.Comment, "0xdeadbeef")
+	assert.Contains(t, signature.Comment, redactedValue)
+
+	// Redacted value is still decodable
+	decoded, err := base64.StdEncoding.DecodeString(signature.Value)
+	require.NoError(t, err)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(decoded, &payload))
+	assert.Equal(t, redactedValue, payload["payload"].(map[string]interface{})["signature"])
+	assert.Equal(t, "0x2", payload["payload"].(map[string]interface{})["authorization"].(map[string]interface{})["nonce"])
+
+	response := findHeader(doc.Log.Entries[1].Response.Headers, "Payment-Response")
+	require.NotNil(t, response)
+	assert.Contains(t, response.Comment, `"transaction": "[REDACTED]"`)
+}
+
+func TestRecorder_BinaryAndFailedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte{0xff, 0xfe, 0x00})
+	}))
+	defer server.Close()
+
+	rec := NewRecorder()
+	c := client.New(client.WithRecorder(rec))
+
+	resp, err := c.Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = c.Get(context.Background(), "http://localhost:99999/nonexistent")
+	require.Error(t, err)
+
+	entries := rec.HAR().Log.Entries
+	require.Len(t, entries, 2)
+	assert.Equal(t, "base64", entries[0].Response.Content.Encoding)
+	assert.Equal(t, "//4A", entries[0].Response.Content.Text)
+	assert.Equal(t, 0, entries[1].Response.Status)
+	assert.Contains(t, entries[1].Comment, "request failed")
+}
+
+func TestRecorder_WriteFile(t *testing.T) {
+	rec := NewRecorder()
+	runExchange(t, rec)
+
+	path := filepath.Join(t.TempDir(), "out.har")
+	require.NoError(t, rec.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var doc HAR
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Len(t, doc.Log.Entries, 2)
+}
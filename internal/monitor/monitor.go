@@ -0,0 +1,226 @@
+// Package monitor tracks x402 endpoint health across repeated checks and
+// reports state transitions such as failures, price changes and payTo changes.
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/port402/x402-cli/internal/output"
+	"github.com/port402/x402-cli/internal/tokens"
+)
+
+// Change kinds.
+const (
+	KindStatus         = "status"
+	KindPrice          = "price"
+	KindPayTo          = "payTo"
+	KindAsset          = "asset"
+	KindNetworkAdded   = "network_added"
+	KindNetworkRemoved = "network_removed"
+)
+
+// Endpoint states.
+const (
+	StatePass = "pass"
+	StateWarn = "warn"
+	StateFail = "fail"
+)
+
+// Option is the part of a payment option that monitoring compares.
+type Option struct {
+	Amount      string `json:"amount"`
+	AmountHuman string `json:"amountHuman"`
+	Asset       string `json:"asset"`
+	PayTo       string `json:"payTo"`
+}
+
+// Snapshot is the monitored state of one endpoint at one point in time.
+type Snapshot struct {
+	State   string            `json:"state"`
+	Options map[string]Option `json:"options"` // Keyed by network
+}
+
+// Change is a single state transition between two snapshots.
+type Change struct {
+	Time    time.Time `json:"time"`
+	URL     string    `json:"url"`
+	Method  string    `json:"method"`
+	Kind    string    `json:"kind"`
+	Network string    `json:"network,omitempty"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to,omitempty"`
+}
+
+// NewSnapshot extracts the monitored state from a health result.
+// Only the first option per network is tracked.
+func NewSnapshot(result *output.HealthResult) Snapshot {
+	s := Snapshot{State: StatePass, Options: make(map[string]Option)}
+
+	if result.ExitCode != 0 {
+		s.State = StateFail
+	} else {
+		for _, c := range result.Checks {
+			if c.Status == output.StatusWarn {
+				s.State = StateWarn
+				break
+			}
+		}
+	}
+
+	for _, opt := range result.PaymentOptions {
+		if _, seen := s.Options[opt.Network]; seen {
+			continue
+		}
+		s.Options[opt.Network] = Option{
+			Amount:      opt.Amount,
+			AmountHuman: opt.AmountHuman,
+			Asset:       opt.Asset,
+			PayTo:       opt.PayTo,
+		}
+	}
+
+	return s
+}
+
+// Diff returns the changes from prev to curr, ordered by network.
+// Payment options are only compared when both checks got a 402, so an
+// outage is reported as a status change rather than removed networks.
+func Diff(prev, curr Snapshot) []Change {
+	var changes []Change
+
+	if prev.State != curr.State {
+		changes = append(changes, Change{Kind: KindStatus, From: prev.State, To: curr.State})
+	}
+
+	if len(prev.Options) == 0 || len(curr.Options) == 0 {
+		return changes
+	}
+
+	for _, network := range sortedNetworks(prev.Options, curr.Options) {
+		before, hadBefore := prev.Options[network]
+		after, hasAfter := curr.Options[network]
+
+		switch {
+		case !hadBefore:
+			changes = append(changes, Change{Kind: KindNetworkAdded, Network: network, To: after.AmountHuman})
+		case !hasAfter:
+			changes = append(changes, Change{Kind: KindNetworkRemoved, Network: network, From: before.AmountHuman})
+		default:
+			if before.Asset != after.Asset {
+				changes = append(changes, Change{Kind: KindAsset, Network: network, From: before.Asset, To: after.Asset})
+			} else if before.Amount != after.Amount {
+				changes = append(changes, Change{Kind: KindPrice, Network: network, From: before.AmountHuman, To: after.AmountHuman})
+			}
+			if before.PayTo != after.PayTo {
+				changes = append(changes, Change{Kind: KindPayTo, Network: network, From: before.PayTo, To: after.PayTo})
+			}
+		}
+	}
+
+	return changes
+}
+
+func sortedNetworks(a, b map[string]Option) []string {
+	seen := make(map[string]bool)
+	var networks []string
+	for _, m := range []map[string]Option{a, b} {
+		for network := range m {
+			if !seen[network] {
+				seen[network] = true
+				networks = append(networks, network)
+			}
+		}
+	}
+	sort.Strings(networks)
+	return networks
+}
+
+// String formats the change for terminal output.
+func (c Change) String() string {
+	target := fmt.Sprintf("%s %s", c.Method, c.URL)
+	network := tokens.GetNetworkName(c.Network)
+
+	switch c.Kind {
+	case KindStatus:
+		return fmt.Sprintf("%s  status %s → %s", target, c.From, c.To)
+	case KindPrice:
+		return fmt.Sprintf("%s  price changed on %s: %s → %s", target, network, c.From, c.To)
+	case KindPayTo:
+		return fmt.Sprintf("%s  payTo changed on %s: %s → %s", target, network, c.From, c.To)
+	case KindAsset:
+		return fmt.Sprintf("%s  asset changed on %s: %s → %s", target, network, c.From, c.To)
+	case KindNetworkAdded:
+		return fmt.Sprintf("%s  network added: %s (%s)", target, network, c.To)
+	case KindNetworkRemoved:
+		return fmt.Sprintf("%s  network removed: %s", target, network)
+	default:
+		return fmt.Sprintf("%s  %s changed: %s → %s", target, c.Kind, c.From, c.To)
+	}
+}
+
+// WebhookPayload is the JSON body posted to a webhook.
+type WebhookPayload struct {
+	Changes []Change `json:"changes"`
+}
+
+// PostWebhook posts changes as JSON to url. Non-2xx responses are errors.
+func PostWebhook(ctx context.Context, client *http.Client, url string, changes []Change) error {
+	body, err := json.Marshal(WebhookPayload{Changes: changes})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Tracker remembers the last snapshot of each endpoint between rounds.
+type Tracker struct {
+	snapshots map[string]Snapshot
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{snapshots: make(map[string]Snapshot)}
+}
+
+// Update records a new result for the endpoint and returns what changed since
+// the previous one. The first result for an endpoint is the baseline and
+// reports no changes.
+func (t *Tracker) Update(method, url string, result *output.HealthResult, now time.Time) []Change {
+	key := method + " " + url
+	curr := NewSnapshot(result)
+	prev, seen := t.snapshots[key]
+	t.snapshots[key] = curr
+	if !seen {
+		return nil
+	}
+
+	changes := Diff(prev, curr)
+	for i := range changes {
+		changes[i].Time = now
+		changes[i].URL = url
+		changes[i].Method = method
+	}
+	return changes
+}
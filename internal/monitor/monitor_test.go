@@ -0,0 +1,138 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/output"
+)
+
+const (
+	baseSepolia = "eip155:84532"
+	solanaDev   = "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"
+)
+
+func healthResult(exitCode int, checks []output.Check, options ...output.PaymentOptionDisplay) *output.HealthResult {
+	return &output.HealthResult{ExitCode: exitCode, Checks: checks, PaymentOptions: options}
+}
+
+func option(network, amount, payTo string) output.PaymentOptionDisplay {
+	return output.PaymentOptionDisplay{
+		Network:     network,
+		Amount:      amount,
+		AmountHuman: amount + " USDC",
+		Asset:       "0xusdc",
+		PayTo:       payTo,
+	}
+}
+
+func TestNewSnapshot_State(t *testing.T) {
+	assert.Equal(t, StatePass, NewSnapshot(healthResult(0, []output.Check{{Status: output.StatusPass}})).State)
+	assert.Equal(t, StateWarn, NewSnapshot(healthResult(0, []output.Check{{Status: output.StatusWarn}})).State)
+	assert.Equal(t, StateFail, NewSnapshot(healthResult(3, nil)).State)
+}
+
+func TestDiff(t *testing.T) {
+	base := NewSnapshot(healthResult(0, nil, option(baseSepolia, "1", "0xA")))
+
+	tests := []struct {
+		name string
+		curr *output.HealthResult
+		want []Change
+	}{
+		{
+			name: "no change",
+			curr: healthResult(0, nil, option(baseSepolia, "1", "0xA")),
+			want: nil,
+		},
+		{
+			name: "price changed",
+			curr: healthResult(0, nil, option(baseSepolia, "2", "0xA")),
+			want: []Change{{Kind: KindPrice, Network: baseSepolia, From: "1 USDC", To: "2 USDC"}},
+		},
+		{
+			name: "payTo changed",
+			curr: healthResult(0, nil, option(baseSepolia, "1", "0xB")),
+			want: []Change{{Kind: KindPayTo, Network: baseSepolia, From: "0xA", To: "0xB"}},
+		},
+		{
+			name: "network added",
+			curr: healthResult(0, nil, option(baseSepolia, "1", "0xA"), option(solanaDev, "1", "Sol")),
+			want: []Change{{Kind: KindNetworkAdded, Network: solanaDev, To: "1 USDC"}},
+		},
+		{
+			name: "network removed",
+			curr: healthResult(0, nil, option(solanaDev, "1", "Sol")),
+			want: []Change{
+				{Kind: KindNetworkRemoved, Network: baseSepolia, From: "1 USDC"},
+				{Kind: KindNetworkAdded, Network: solanaDev, To: "1 USDC"},
+			},
+		},
+		{
+			name: "outage reports status only",
+			curr: healthResult(3, nil),
+			want: []Change{{Kind: KindStatus, From: StatePass, To: StateFail}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(base, NewSnapshot(tt.curr)))
+		})
+	}
+}
+
+func TestTracker_Update(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, tracker.Update("GET", "https://a.example", healthResult(0, nil, option(baseSepolia, "1", "0xA")), now))
+	assert.Nil(t, tracker.Update("POST", "https://a.example", healthResult(3, nil), now))
+
+	changes := tracker.Update("GET", "https://a.example", healthResult(0, nil, option(baseSepolia, "5", "0xA")), now)
+	require.Len(t, changes, 1)
+	assert.Equal(t, Change{Time: now, URL: "https://a.example", Method: "GET", Kind: KindPrice, Network: baseSepolia, From: "1 USDC", To: "5 USDC"}, changes[0])
+
+	assert.Empty(t, tracker.Update("POST", "https://a.example", healthResult(3, nil), now))
+}
+
+func TestChange_String(t *testing.T) {
+	c := Change{URL: "https://a.example", Method: "GET", Kind: KindPrice, Network: baseSepolia, From: "1 USDC", To: "2 USDC"}
+	assert.Equal(t, "GET https://a.example  price changed on Base Sepolia: 1 USDC → 2 USDC", c.String())
+
+	c = Change{URL: "https://a.example", Method: "GET", Kind: KindStatus, From: StatePass, To: StateFail}
+	assert.Equal(t, "GET https://a.example  status pass → fail", c.String())
+}
+
+func TestPostWebhook(t *testing.T) {
+	var received WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	changes := []Change{{URL: "https://a.example", Method: "GET", Kind: KindStatus, From: StatePass, To: StateFail}}
+	require.NoError(t, PostWebhook(context.Background(), server.Client(), server.URL, changes))
+	require.Len(t, received.Changes, 1)
+	assert.Equal(t, KindStatus, received.Changes[0].Kind)
+}
+
+func TestPostWebhook_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := PostWebhook(context.Background(), server.Client(), server.URL, nil)
+	assert.ErrorContains(t, err, "webhook returned 500")
+}
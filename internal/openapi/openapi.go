@@ -0,0 +1,365 @@
+// Package openapi enumerates the operations in an OpenAPI 3 document as
+// concrete requests, filling parameters from the examples in the spec, so
+// each operation can be probed for an x402 payment requirement.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Methods lists the HTTP methods an OpenAPI path item can define, in the
+// order operations are reported.
+var Methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Document is the subset of an OpenAPI 3 document needed to build requests.
+type Document struct {
+	OpenAPI    string              `yaml:"openapi"`
+	Servers    []Server            `yaml:"servers"`
+	Paths      map[string]PathItem `yaml:"paths"`
+	Components struct {
+		Parameters    map[string]Parameter   `yaml:"parameters"`
+		RequestBodies map[string]RequestBody `yaml:"requestBodies"`
+	} `yaml:"components"`
+}
+
+// Server is an entry of the document's servers list.
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+// PathItem holds the operations defined for one path.
+type PathItem struct {
+	Parameters []Parameter `yaml:"parameters"`
+	Get        *Operation  `yaml:"get"`
+	Head       *Operation  `yaml:"head"`
+	Post       *Operation  `yaml:"post"`
+	Put        *Operation  `yaml:"put"`
+	Patch      *Operation  `yaml:"patch"`
+	Delete     *Operation  `yaml:"delete"`
+	Options    *Operation  `yaml:"options"`
+}
+
+// Operation is a single API operation.
+type Operation struct {
+	OperationID string       `yaml:"operationId"`
+	Summary     string       `yaml:"summary"`
+	Tags        []string     `yaml:"tags"`
+	Parameters  []Parameter  `yaml:"parameters"`
+	RequestBody *RequestBody `yaml:"requestBody"`
+}
+
+// Parameter is an operation or path-level parameter.
+type Parameter struct {
+	Ref      string             `yaml:"$ref"`
+	Name     string             `yaml:"name"`
+	In       string             `yaml:"in"`
+	Required bool               `yaml:"required"`
+	Example  any                `yaml:"example"`
+	Examples map[string]Example `yaml:"examples"`
+	Schema   *Schema            `yaml:"schema"`
+}
+
+// RequestBody describes an operation's request body by media type.
+type RequestBody struct {
+	Ref      string               `yaml:"$ref"`
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+// MediaType holds the examples for one request body media type.
+type MediaType struct {
+	Example  any                `yaml:"example"`
+	Examples map[string]Example `yaml:"examples"`
+	Schema   *Schema            `yaml:"schema"`
+}
+
+// Example is a named example value.
+type Example struct {
+	Value any `yaml:"value"`
+}
+
+// Schema holds the schema fields that can supply a sample value.
+type Schema struct {
+	Example any   `yaml:"example"`
+	Default any   `yaml:"default"`
+	Enum    []any `yaml:"enum"`
+}
+
+// Request is an operation resolved to a concrete request.
+type Request struct {
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	URL         string            `json:"url,omitempty"`
+	OperationID string            `json:"operationId,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body,omitempty"`
+
+	// Skipped explains why no request could be built, e.g. a required
+	// parameter without an example. URL is empty when set.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Parse decodes an OpenAPI 3 document in YAML or JSON.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		if doc.OpenAPI == "" {
+			return nil, fmt.Errorf("not an OpenAPI 3 document: missing \"openapi\" version")
+		}
+		return nil, fmt.Errorf("unsupported OpenAPI version %q: only 3.x is supported", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// ServerURL returns the first absolute server URL in the document, or "".
+func (d *Document) ServerURL() string {
+	for _, s := range d.Servers {
+		if u, err := url.Parse(s.URL); err == nil && u.IsAbs() {
+			return s.URL
+		}
+	}
+	return ""
+}
+
+// Requests builds a request for every operation whose method is in methods,
+// against base. Paths are sorted and methods follow the order of Methods.
+// An empty methods list selects every method.
+func (d *Document) Requests(base string, methods []string) []Request {
+	base = strings.TrimRight(base, "/")
+
+	paths := make([]string, 0, len(d.Paths))
+	for p := range d.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var requests []Request
+	for _, path := range paths {
+		item := d.Paths[path]
+		for _, method := range Methods {
+			op := item.operation(method)
+			if op == nil || !containsFold(methods, method) {
+				continue
+			}
+			requests = append(requests, d.build(base, path, method, item.Parameters, op))
+		}
+	}
+	return requests
+}
+
+func (d *Document) build(base, path, method string, shared []Parameter, op *Operation) Request {
+	req := Request{Method: method, Path: path, OperationID: op.OperationID, Tags: op.Tags}
+
+	resolved := path
+	query := url.Values{}
+	for _, p := range d.parameters(shared, op.Parameters) {
+		value, ok := p.sample()
+		if !ok {
+			if p.In == "path" || p.Required {
+				req.Skipped = fmt.Sprintf("no example for %s parameter %q", p.In, p.Name)
+				return req
+			}
+			continue
+		}
+
+		switch p.In {
+		case "path":
+			resolved = strings.ReplaceAll(resolved, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			if p.Required {
+				query.Set(p.Name, value)
+			}
+		case "header":
+			if p.Required {
+				if req.Headers == nil {
+					req.Headers = make(map[string]string)
+				}
+				req.Headers[p.Name] = value
+			}
+		}
+	}
+
+	if strings.Contains(resolved, "{") {
+		req.Skipped = "path template has parameters the spec does not declare"
+		return req
+	}
+
+	if body := d.requestBody(op.RequestBody); body != nil {
+		contentType, content, ok := body.sample()
+		if !ok && body.Required {
+			req.Skipped = "no example for required request body"
+			return req
+		}
+		if ok {
+			if req.Headers == nil {
+				req.Headers = make(map[string]string)
+			}
+			req.Headers["Content-Type"] = contentType
+			req.Body = content
+		}
+	}
+
+	req.URL = base + resolved
+	if len(query) > 0 {
+		req.URL += "?" + query.Encode()
+	}
+	return req
+}
+
+// parameters merges path-level and operation parameters, resolving local
+// references. Operation parameters override path-level ones with the same
+// name and location.
+func (d *Document) parameters(shared, own []Parameter) []Parameter {
+	var merged []Parameter
+	index := make(map[string]int)
+	for _, p := range append(append([]Parameter{}, shared...), own...) {
+		if p.Ref != "" {
+			p = d.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+		}
+		key := p.In + ":" + p.Name
+		if i, ok := index[key]; ok {
+			merged[i] = p
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+func (d *Document) requestBody(body *RequestBody) *RequestBody {
+	if body == nil || body.Ref == "" {
+		return body
+	}
+	if resolved, ok := d.Components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]; ok {
+		return &resolved
+	}
+	return nil
+}
+
+func (item PathItem) operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return item.Get
+	case "HEAD":
+		return item.Head
+	case "POST":
+		return item.Post
+	case "PUT":
+		return item.Put
+	case "PATCH":
+		return item.Patch
+	case "DELETE":
+		return item.Delete
+	case "OPTIONS":
+		return item.Options
+	}
+	return nil
+}
+
+// sample returns an example value for the parameter from, in order, its
+// example, its first named example, or its schema's example, default or
+// first enum value.
+func (p Parameter) sample() (string, bool) {
+	if v, ok := firstValue(p.Example, p.Examples, p.Schema); ok {
+		return formatScalar(v), true
+	}
+	return "", false
+}
+
+// sample returns the media type and serialized example of the request body.
+// JSON media types are preferred.
+func (b RequestBody) sample() (contentType, body string, ok bool) {
+	types := make([]string, 0, len(b.Content))
+	for t := range b.Content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	sort.SliceStable(types, func(i, j int) bool {
+		return isJSON(types[i]) && !isJSON(types[j])
+	})
+
+	for _, t := range types {
+		mt := b.Content[t]
+		v, found := firstValue(mt.Example, mt.Examples, mt.Schema)
+		if !found {
+			continue
+		}
+		if s, isString := v.(string); isString && !isJSON(t) {
+			return t, s, true
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		return t, string(data), true
+	}
+	return "", "", false
+}
+
+func firstValue(example any, examples map[string]Example, schema *Schema) (any, bool) {
+	if example != nil {
+		return example, true
+	}
+	if len(examples) > 0 {
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v := examples[name].Value; v != nil {
+				return v, true
+			}
+		}
+	}
+	if schema != nil {
+		switch {
+		case schema.Example != nil:
+			return schema.Example, true
+		case schema.Default != nil:
+			return schema.Default, true
+		case len(schema.Enum) > 0:
+			return schema.Enum[0], true
+		}
+	}
+	return nil, false
+}
+
+func formatScalar(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []any, map[string]any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(contentType, "json")
+}
+
+func containsFold(list []string, s string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
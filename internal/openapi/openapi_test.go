@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `
+openapi: 3.0.3
+servers:
+  - url: /relative
+  - url: https://api.example.com/v1
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      required: true
+      schema:
+        type: integer
+        default: 10
+paths:
+  /search:
+    get:
+      operationId: search
+      tags: [search]
+      parameters:
+        - name: q
+          in: query
+          required: true
+          example: weather report
+        - $ref: '#/components/parameters/Limit'
+        - name: cursor
+          in: query
+          schema: {type: string}
+    post:
+      operationId: searchAdvanced
+      requestBody:
+        required: true
+        content:
+          text/plain:
+            example: plain
+          application/json:
+            example: {q: test, limit: 5}
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        examples:
+          b: {value: second}
+          a: {value: item 1}
+    get:
+      operationId: getItem
+    delete:
+      operationId: deleteItem
+  /users/{userId}:
+    get:
+      parameters:
+        - name: userId
+          in: path
+          required: true
+          schema: {type: string}
+  /reports:
+    post:
+      parameters:
+        - name: X-Tenant
+          in: header
+          required: true
+          schema:
+            enum: [acme, other]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {type: object}
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(spec))
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v1", doc.ServerURL())
+
+	_, err = Parse([]byte(`{"swagger": "2.0", "paths": {}}`))
+	assert.ErrorContains(t, err, "missing \"openapi\" version")
+
+	_, err = Parse([]byte(`{"openapi": "2.0"}`))
+	assert.ErrorContains(t, err, "only 3.x is supported")
+
+	_, err = Parse([]byte(`openapi: [`))
+	assert.ErrorContains(t, err, "failed to parse OpenAPI document")
+}
+
+func TestRequests(t *testing.T) {
+	doc, err := Parse([]byte(spec))
+	require.NoError(t, err)
+
+	requests := doc.Requests("https://api.example.com/v1/", nil)
+	require.Len(t, requests, 6)
+
+	byOperation := make(map[string]Request)
+	for _, r := range requests {
+		byOperation[r.Method+" "+r.Path] = r
+	}
+
+	t.Run("sorted by path then method", func(t *testing.T) {
+		var order []string
+		for _, r := range requests {
+			order = append(order, r.Method+" "+r.Path)
+		}
+		assert.Equal(t, []string{
+			"GET /items/{id}",
+			"DELETE /items/{id}",
+			"POST /reports",
+			"GET /search",
+			"POST /search",
+			"GET /users/{userId}",
+		}, order)
+	})
+
+	t.Run("required query params and refs", func(t *testing.T) {
+		r := byOperation["GET /search"]
+		assert.Equal(t, "https://api.example.com/v1/search?limit=10&q=weather+report", r.URL)
+		assert.Equal(t, "search", r.OperationID)
+		assert.Equal(t, []string{"search"}, r.Tags)
+		assert.Empty(t, r.Skipped)
+	})
+
+	t.Run("path-level params from first named example", func(t *testing.T) {
+		assert.Equal(t, "https://api.example.com/v1/items/item%201", byOperation["GET /items/{id}"].URL)
+		assert.Equal(t, "https://api.example.com/v1/items/item%201", byOperation["DELETE /items/{id}"].URL)
+	})
+
+	t.Run("json body preferred", func(t *testing.T) {
+		r := byOperation["POST /search"]
+		assert.Equal(t, "application/json", r.Headers["Content-Type"])
+		assert.JSONEq(t, `{"q": "test", "limit": 5}`, r.Body)
+	})
+
+	t.Run("missing examples skip", func(t *testing.T) {
+		r := byOperation["GET /users/{userId}"]
+		assert.Equal(t, `no example for path parameter "userId"`, r.Skipped)
+		assert.Empty(t, r.URL)
+
+		r = byOperation["POST /reports"]
+		assert.Equal(t, "no example for required request body", r.Skipped)
+	})
+
+	t.Run("method filter", func(t *testing.T) {
+		requests := doc.Requests("https://api.example.com", []string{"post"})
+		require.Len(t, requests, 2)
+		for _, r := range requests {
+			assert.Equal(t, "POST", r.Method)
+		}
+	})
+}
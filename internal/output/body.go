@@ -0,0 +1,62 @@
+package output
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// IsTextBody reports whether a response body is text that can be printed,
+// judged by its Content-Type or, when that is missing or generic, by
+// sniffing the content.
+func IsTextBody(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+		if mediaType == "text/plain" {
+			return utf8.Valid(body)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-ndjson", "application/x-www-form-urlencoded", "application/yaml":
+		return true
+	}
+	return false
+}
+
+// FormatSize formats a byte count for display, e.g. "512 B" or "1.5 MB".
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// describeBody summarizes a body that is not printed: its type, size and hash.
+func describeBody(result *TestResult) string {
+	parts := []string{}
+	if result.ResponseType != "" {
+		parts = append(parts, result.ResponseType)
+	}
+	parts = append(parts, FormatSize(result.ResponseSize))
+	if result.ResponseSHA256 != "" {
+		parts = append(parts, "sha256 "+result.ResponseSHA256)
+	}
+	return strings.Join(parts, ", ")
+}
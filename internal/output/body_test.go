@@ -0,0 +1,42 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTextBody(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        bool
+	}{
+		{"json", "application/json; charset=utf-8", []byte(`{"a":1}`), true},
+		{"vendor json", "application/problem+json", []byte(`{}`), true},
+		{"html", "text/html", []byte("<p>hi</p>"), true},
+		{"image", "image/png", png, false},
+		{"pdf", "application/pdf", []byte("%PDF-1.7"), false},
+		{"untyped text", "", []byte("plain words"), true},
+		{"untyped image", "", png, false},
+		{"octet-stream text", "application/octet-stream", []byte("hello"), true},
+		{"octet-stream binary", "application/octet-stream", []byte{0x00, 0xff, 0xfe}, false},
+		{"empty", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTextBody(tt.contentType, tt.body))
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "0 B", FormatSize(0))
+	assert.Equal(t, "1023 B", FormatSize(1023))
+	assert.Equal(t, "1.5 KB", FormatSize(1536))
+	assert.Equal(t, "2.0 MB", FormatSize(2<<20))
+}
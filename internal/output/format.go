@@ -2,13 +2,16 @@ package output
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/port402/x402-cli/internal/a2a"
+	"github.com/port402/x402-cli/internal/baseline"
 	"github.com/port402/x402-cli/internal/tokens"
+	"github.com/port402/x402-cli/internal/x402"
 )
 
 // CheckStatus represents the result of a validation check.
@@ -55,6 +58,46 @@ type HealthResult struct {
 	ExitCode       int                    `json:"exitCode"`
 	Error          string                 `json:"error,omitempty"`
 	AgentCard      *a2a.Result            `json:"agentCard,omitempty"`
+
+	// Timing breaks the latency into connection phases
+	Timing *Timing `json:"timing,omitempty"`
+
+	// Attempts is the number of requests sent when the check was retried (--retries only)
+	Attempts int `json:"attempts,omitempty"`
+
+	// SupportedProtocols lists the client versions that can read the 402 (--compat only)
+	SupportedProtocols []string `json:"supportedProtocols,omitempty"`
+
+	// SchemaViolations lists strict schema failures in the 402 payload (--strict only)
+	SchemaViolations []x402.Violation `json:"schemaViolations,omitempty"`
+
+	// BaselineDrift lists differences from the pinned payment options (--baseline only)
+	BaselineDrift []baseline.Drift `json:"baselineDrift,omitempty"`
+
+	// ListingDrift lists differences from a discovery list's advertised options (--from-discovery only)
+	ListingDrift []baseline.Drift `json:"listingDrift,omitempty"`
+}
+
+// Timing breaks a request's latency into phases, in milliseconds. Phases
+// skipped on a reused connection are zero.
+type Timing struct {
+	DNSMs      int64 `json:"dnsMs"`
+	ConnectMs  int64 `json:"connectMs"`
+	TLSMs      int64 `json:"tlsMs"`
+	ServerMs   int64 `json:"serverMs"`   // Request written to first response byte
+	TTFBMs     int64 `json:"ttfbMs"`     // Connection requested to first response byte
+	TransferMs int64 `json:"transferMs"` // First response byte to end of body
+	Reused     bool  `json:"reused,omitempty"`
+}
+
+// String formats the phases on one line, e.g. for verbose output.
+func (t *Timing) String() string {
+	s := fmt.Sprintf("DNS %dms, connect %dms, TLS %dms, server %dms, TTFB %dms, transfer %dms",
+		t.DNSMs, t.ConnectMs, t.TLSMs, t.ServerMs, t.TTFBMs, t.TransferMs)
+	if t.Reused {
+		s += " (reused connection)"
+	}
+	return s
 }
 
 // TestResult contains the complete test payment result.
@@ -63,12 +106,24 @@ type TestResult struct {
 	Status          int                  `json:"status"`
 	StatusText      string               `json:"statusText"`
 	Protocol        string               `json:"protocol"`
+	PaymentProtocol string               `json:"paymentProtocol,omitempty"` // Set when --protocol forces the payment version
 	PaymentOption   PaymentOptionDisplay `json:"paymentOption"`
 	Transaction     string               `json:"transaction,omitempty"`
 	TransactionURL  string               `json:"transactionUrl,omitempty"`
-	ResponseBody    string               `json:"responseBody,omitempty"`
+	ResponseBody    string               `json:"responseBody,omitempty"`       // Text response body
+	ResponseBase64  string               `json:"responseBodyBase64,omitempty"` // Binary response body, base64-encoded
+	ResponseType    string               `json:"responseContentType,omitempty"`
+	ResponseSize    int64                `json:"responseSize,omitempty"`
+	ResponseSHA256  string               `json:"responseSha256,omitempty"`
+	OutputFile      string               `json:"outputFile,omitempty"`         // Where --output saved the response body
+	Streamed        bool                 `json:"streamed,omitempty"`           // The body was a stream (server-sent events or chunked text)
+	TTFTMs          int64                `json:"timeToFirstTokenMs,omitempty"` // From sending the paid request to the first streamed token
+	DurationMs      int64                `json:"durationMs,omitempty"`         // From sending the paid request to the end of the body
 	PaymentResponse interface{}          `json:"paymentResponse,omitempty"`
 	DryRun          bool                 `json:"dryRun,omitempty"`
+	Cancelled       bool                 `json:"cancelled,omitempty"`
+	SignatureSent   bool                 `json:"signatureSent,omitempty"` // Set on cancellation if the paid request may have reached the server
+	PaymentTiming   *Timing              `json:"paymentTiming,omitempty"` // Phases of the paid request
 	ExitCode        int                  `json:"exitCode"`
 	Error           string               `json:"error,omitempty"`
 }
@@ -95,6 +150,9 @@ func PrintHealthResult(result *HealthResult, verbose bool) {
 		fmt.Printf("  Protocol: %s\n", formatProtocol(result.Protocol))
 	}
 	fmt.Printf("  Latency:  %dms\n", result.LatencyMs)
+	if verbose && result.Timing != nil {
+		fmt.Printf("  Timing:   %s\n", result.Timing)
+	}
 
 	// Payment - consolidated single line
 	if len(result.PaymentOptions) > 0 {
@@ -120,6 +178,33 @@ func PrintHealthResult(result *HealthResult, verbose bool) {
 		}
 	}
 
+	// Schema violations (when --strict flag used)
+	if len(result.SchemaViolations) > 0 {
+		fmt.Println()
+		fmt.Println("  Schema violations:")
+		for _, v := range result.SchemaViolations {
+			fmt.Printf("    %s\n", v)
+		}
+	}
+
+	// Baseline drift (when --baseline flag used)
+	if len(result.BaselineDrift) > 0 {
+		fmt.Println()
+		fmt.Println("  Baseline drift:")
+		for _, d := range result.BaselineDrift {
+			fmt.Printf("    %s\n", d)
+		}
+	}
+
+	// Listing drift (when checked against a discovery list)
+	if len(result.ListingDrift) > 0 {
+		fmt.Println()
+		fmt.Println("  Listing drift:")
+		for _, d := range result.ListingDrift {
+			fmt.Printf("    %s\n", d)
+		}
+	}
+
 	// Agent card section (when --agent flag used)
 	if result.AgentCard != nil {
 		printAgentSection(result.AgentCard)
@@ -210,6 +295,16 @@ func PrintTestResult(result *TestResult, verbose bool) {
 	fmt.Printf("  URL:      %s\n", result.URL)
 	fmt.Printf("  Status:   %s\n", result.StatusText)
 	fmt.Printf("  Payment:  %s on %s\n", result.PaymentOption.AmountHuman, tokens.GetNetworkName(result.PaymentOption.Network))
+	if result.PaymentProtocol != "" {
+		fmt.Printf("  Protocol: %s payment (forced, server advertised %s)\n", result.PaymentProtocol, result.Protocol)
+	}
+
+	if verbose && result.PaymentTiming != nil {
+		fmt.Printf("  Timing:   %s\n", result.PaymentTiming)
+	}
+	if result.Streamed && result.Error == "" {
+		fmt.Printf("  Stream:   first token after %dms, %dms total\n", result.TTFTMs, result.DurationMs)
+	}
 
 	// Transaction info (on success)
 	if result.Transaction != "" {
@@ -220,10 +315,17 @@ func PrintTestResult(result *TestResult, verbose bool) {
 	}
 
 	// Response body
-	if result.ResponseBody != "" && !result.DryRun && result.Error == "" {
-		fmt.Println()
-		fmt.Println("Response:")
-		fmt.Println(formatResponseBody(result.ResponseBody))
+	if !result.DryRun && result.Error == "" {
+		switch {
+		case result.OutputFile != "":
+			fmt.Printf("  Saved:    %s (%s)\n", result.OutputFile, describeBody(result))
+		case result.ResponseBase64 != "":
+			fmt.Printf("  Response: %s (binary, use --output to save)\n", describeBody(result))
+		case result.ResponseBody != "":
+			fmt.Println()
+			fmt.Println("Response:")
+			fmt.Println(formatResponseBody(result.ResponseBody))
+		}
 	}
 
 	// Dry run notice
@@ -261,13 +363,29 @@ func PrintInfo(msg string) {
 // PromptConfirm prompts the user for yes/no confirmation.
 // Returns true if user enters y/Y/yes.
 func PromptConfirm(prompt string) bool {
-	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+	confirmed, _ := PromptConfirmContext(context.Background(), prompt)
+	return confirmed
+}
 
-	var response string
-	fmt.Scanln(&response)
+// PromptConfirmContext is PromptConfirm, returning ctx's error if ctx is
+// cancelled before the user answers.
+func PromptConfirmContext(ctx context.Context, prompt string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
 
-	response = strings.ToLower(strings.TrimSpace(response))
-	return response == "y" || response == "yes"
+	answer := make(chan string, 1)
+	go func() {
+		var response string
+		fmt.Scanln(&response)
+		answer <- response
+	}()
+
+	select {
+	case response := <-answer:
+		response = strings.ToLower(strings.TrimSpace(response))
+		return response == "y" || response == "yes", nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
 }
 
 // PromptSelect prompts the user to select from a list of options.
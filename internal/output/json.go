@@ -45,6 +45,13 @@ type BatchHealthResult struct {
 	Duration   int64          `json:"durationMs"`
 }
 
+// StreamRecord is one line of NDJSON batch output: the result of the entry
+// at Index (0-based) in the input.
+type StreamRecord struct {
+	Index int `json:"index"`
+	*HealthResult
+}
+
 // PrintBatchHealthResult outputs batch health results.
 func PrintBatchHealthResult(result *BatchHealthResult, jsonOutput bool) {
 	if jsonOutput {
@@ -0,0 +1,501 @@
+package output
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ReportFormat is an output format for health check reports.
+type ReportFormat string
+
+// Supported report formats.
+const (
+	ReportText     ReportFormat = "text"
+	ReportJSON     ReportFormat = "json"
+	ReportJUnit    ReportFormat = "junit"
+	ReportTAP      ReportFormat = "tap"
+	ReportSARIF    ReportFormat = "sarif"
+	ReportMarkdown ReportFormat = "markdown"
+	ReportNDJSON   ReportFormat = "ndjson"
+)
+
+// ReportFormats lists the supported report formats.
+var ReportFormats = []ReportFormat{ReportText, ReportJSON, ReportJUnit, ReportTAP, ReportSARIF, ReportMarkdown, ReportNDJSON}
+
+// ParseReportFormat validates a --format value.
+func ParseReportFormat(s string) (ReportFormat, error) {
+	for _, f := range ReportFormats {
+		if ReportFormat(strings.ToLower(s)) == f {
+			return f, nil
+		}
+	}
+	names := make([]string, len(ReportFormats))
+	for i, f := range ReportFormats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown format %q (supported: %s)", s, strings.Join(names, ", "))
+}
+
+// NewBatchHealthResult summarizes health results. Endpoints with a non-zero
+// exit code fail; the rest pass, with or without warnings.
+func NewBatchHealthResult(results []HealthResult, durationMs int64) *BatchHealthResult {
+	batch := &BatchHealthResult{
+		TotalURLs: len(results),
+		Results:   results,
+		Duration:  durationMs,
+	}
+	for i := range results {
+		batch.Count(&results[i])
+	}
+	return batch
+}
+
+// Count adds r to the pass, warn and fail counts without keeping it.
+func (b *BatchHealthResult) Count(r *HealthResult) {
+	switch endpointStatus(r) {
+	case StatusFail:
+		b.Failed++
+	case StatusWarn:
+		b.PassedWarn++
+	default:
+		b.Passed++
+	}
+}
+
+// endpointStatus returns the overall status of one endpoint.
+func endpointStatus(r *HealthResult) CheckStatus {
+	if r.ExitCode != 0 {
+		return StatusFail
+	}
+	if _, warnCount := countChecks(r.Checks); warnCount > 0 {
+		return StatusWarn
+	}
+	return StatusPass
+}
+
+// endpointName identifies an endpoint in reports.
+func endpointName(r *HealthResult) string {
+	if r.Method == "" {
+		return r.URL
+	}
+	return r.Method + " " + r.URL
+}
+
+// reportConfig holds optional report settings.
+type reportConfig struct {
+	toolVersion string
+}
+
+// ReportOption configures WriteReport.
+type ReportOption func(*reportConfig)
+
+// WithToolVersion sets the tool version recorded in SARIF reports.
+func WithToolVersion(version string) ReportOption {
+	return func(c *reportConfig) {
+		c.toolVersion = version
+	}
+}
+
+// WriteReport writes batch health results in a machine-readable format.
+// Each endpoint is a test case and each check an assertion within it.
+func WriteReport(w io.Writer, format ReportFormat, result *BatchHealthResult, opts ...ReportOption) error {
+	cfg := &reportConfig{toolVersion: "dev"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	switch format {
+	case ReportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case ReportJUnit:
+		return writeJUnit(w, result)
+	case ReportTAP:
+		return writeTAP(w, result)
+	case ReportSARIF:
+		return writeSARIF(w, result, cfg)
+	case ReportMarkdown:
+		return writeMarkdown(w, result)
+	case ReportNDJSON:
+		return writeNDJSON(w, result)
+	default:
+		return fmt.Errorf("format %q is not a report format", format)
+	}
+}
+
+// writeNDJSON writes one StreamRecord per line, in result order.
+func writeNDJSON(w io.Writer, result *BatchHealthResult) error {
+	enc := json.NewEncoder(w)
+	for i := range result.Results {
+		if err := enc.Encode(StreamRecord{Index: i, HealthResult: &result.Results[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JUnit XML
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string        `xml:"name,attr"`
+	Classname  string        `xml:"classname,attr"`
+	Assertions int           `xml:"assertions,attr"`
+	Time       string        `xml:"time,attr"`
+	Failure    *junitFailure `xml:"failure,omitempty"`
+	SystemOut  string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, result *BatchHealthResult) error {
+	suite := junitTestSuite{
+		Name:     "x402 health",
+		Tests:    len(result.Results),
+		Failures: result.Failed,
+		Time:     seconds(result.Duration),
+	}
+
+	for i := range result.Results {
+		r := &result.Results[i]
+		tc := junitTestCase{
+			Name:       endpointName(r),
+			Classname:  "x402.health",
+			Assertions: len(r.Checks),
+			Time:       seconds(r.LatencyMs),
+		}
+
+		var out, failures []string
+		for _, c := range r.Checks {
+			line := fmt.Sprintf("[%s] %s: %s", c.Status, c.Name, c.Message)
+			out = append(out, line)
+			if c.Status == StatusFail {
+				failures = append(failures, line)
+			}
+		}
+		tc.SystemOut = strings.Join(out, "\n")
+
+		if endpointStatus(r) == StatusFail {
+			message := r.Error
+			for _, c := range r.Checks {
+				if c.Status == StatusFail {
+					message = fmt.Sprintf("%s: %s", c.Name, c.Message)
+					break
+				}
+			}
+			tc.Failure = &junitFailure{
+				Message: message,
+				Type:    fmt.Sprintf("exit code %d", r.ExitCode),
+				Body:    strings.Join(failures, "\n"),
+			}
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	doc := junitTestSuites{
+		Name:     "x402",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode JUnit XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// TAP version 14
+
+func writeTAP(w io.Writer, result *BatchHealthResult) error {
+	var b strings.Builder
+	b.WriteString("TAP version 14\n")
+	fmt.Fprintf(&b, "1..%d\n", len(result.Results))
+
+	for i := range result.Results {
+		r := &result.Results[i]
+		name := tapEscape(endpointName(r))
+
+		fmt.Fprintf(&b, "# Subtest: %s\n", name)
+		fmt.Fprintf(&b, "    1..%d\n", len(r.Checks))
+		for j, c := range r.Checks {
+			status := "ok"
+			if c.Status == StatusFail {
+				status = "not ok"
+			}
+			fmt.Fprintf(&b, "    %s %d - %s\n", status, j+1, tapEscape(c.Name))
+			if c.Status != StatusPass {
+				b.WriteString("      ---\n")
+				fmt.Fprintf(&b, "      severity: %s\n", c.Status)
+				fmt.Fprintf(&b, "      message: %s\n", yamlQuote(c.Message))
+				b.WriteString("      ...\n")
+			}
+		}
+
+		status := "ok"
+		if endpointStatus(r) == StatusFail {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, name)
+		if status == "not ok" && len(r.Checks) == 0 && r.Error != "" {
+			b.WriteString("  ---\n")
+			fmt.Fprintf(&b, "  message: %s\n", yamlQuote(r.Error))
+			b.WriteString("  ...\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var tapEscaper = strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ")
+
+func tapEscape(s string) string {
+	return tapEscaper.Replace(s)
+}
+
+// yamlQuote renders s as a double-quoted YAML scalar (JSON strings are valid YAML).
+func yamlQuote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// SARIF 2.1.0
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+var nonRuleChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ruleID derives a stable SARIF rule ID from a check name.
+func ruleID(checkName string) string {
+	return "x402/" + strings.Trim(nonRuleChars.ReplaceAllString(strings.ToLower(checkName), "-"), "-")
+}
+
+// writeSARIF reports every failed or warning check as a SARIF result located
+// at the endpoint URL. Passing checks produce no results.
+func writeSARIF(w io.Writer, result *BatchHealthResult, cfg *reportConfig) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "x402",
+			Version:        cfg.toolVersion,
+			InformationURI: "https://github.com/port402/x402-cli",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	seenRules := make(map[string]bool)
+	addRule := func(id, name string) {
+		if !seenRules[id] {
+			seenRules[id] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               id,
+				Name:             name,
+				ShortDescription: sarifMessage{Text: name},
+			})
+		}
+	}
+
+	for i := range result.Results {
+		r := &result.Results[i]
+		location := []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: r.URL},
+		}}}
+
+		for _, c := range r.Checks {
+			if c.Status == StatusPass {
+				continue
+			}
+			level := "warning"
+			if c.Status == StatusFail {
+				level = "error"
+			}
+			id := ruleID(c.Name)
+			addRule(id, c.Name)
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    id,
+				Level:     level,
+				Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", endpointName(r), c.Message)},
+				Locations: location,
+			})
+		}
+
+		if len(r.Checks) == 0 && r.Error != "" {
+			id := ruleID("Health check")
+			addRule(id, "Health check")
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    id,
+				Level:     "error",
+				Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", endpointName(r), r.Error)},
+				Locations: location,
+			})
+		}
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// Markdown
+
+var markdownIcons = map[CheckStatus]string{
+	StatusPass: "✅",
+	StatusWarn: "⚠️",
+	StatusFail: "❌",
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// writeMarkdown renders a summary table suitable for PR comments and job
+// summaries, followed by details for endpoints that did not fully pass.
+func writeMarkdown(w io.Writer, result *BatchHealthResult) error {
+	var b strings.Builder
+
+	b.WriteString("## x402 Health Check\n\n")
+	fmt.Fprintf(&b, "**%d endpoint(s)** · %s %d passed · %s %d warned · %s %d failed · %dms\n\n",
+		result.TotalURLs,
+		markdownIcons[StatusPass], result.Passed,
+		markdownIcons[StatusWarn], result.PassedWarn,
+		markdownIcons[StatusFail], result.Failed,
+		result.Duration)
+
+	b.WriteString("| | Endpoint | Protocol | Latency | Payment |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for i := range result.Results {
+		r := &result.Results[i]
+		payment := "-"
+		if len(r.PaymentOptions) > 0 {
+			payment = r.PaymentOptions[0].AmountHuman
+			if n := len(r.PaymentOptions); n > 1 {
+				payment = fmt.Sprintf("%s (+%d more)", payment, n-1)
+			}
+		}
+		protocol := r.Protocol
+		if protocol == "" {
+			protocol = "-"
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %dms | %s |\n",
+			markdownIcons[endpointStatus(r)],
+			markdownEscaper.Replace(endpointName(r)),
+			markdownEscaper.Replace(protocol),
+			r.LatencyMs,
+			markdownEscaper.Replace(payment))
+	}
+
+	for i := range result.Results {
+		r := &result.Results[i]
+		status := endpointStatus(r)
+		if status == StatusPass {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n### %s `%s`\n\n", markdownIcons[status], endpointName(r))
+		for _, c := range r.Checks {
+			if c.Status == StatusPass {
+				continue
+			}
+			fmt.Fprintf(&b, "- %s **%s**: %s\n", markdownIcons[c.Status], c.Name, c.Message)
+		}
+		if len(r.Checks) == 0 && r.Error != "" {
+			fmt.Fprintf(&b, "- %s %s\n", markdownIcons[StatusFail], r.Error)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleBatch() *BatchHealthResult {
+	return NewBatchHealthResult([]HealthResult{
+		{
+			URL: "https://ok.example/api", Method: "GET", LatencyMs: 120, Protocol: "v2",
+			PaymentOptions: []PaymentOptionDisplay{{AmountHuman: "0.01 USDC"}},
+			Checks: []Check{
+				{Name: "Endpoint reachable", Status: StatusPass, Message: "Connected in 120ms"},
+				{Name: "Known token", Status: StatusWarn, Message: "Token not in registry"},
+			},
+		},
+		{
+			URL: "https://bad.example/api", Method: "POST", LatencyMs: 30, ExitCode: 1,
+			Checks: []Check{
+				{Name: "Endpoint reachable", Status: StatusPass, Message: "Connected in 30ms"},
+				{Name: "Returns 402", Status: StatusFail, Message: "Got 200 instead of 402 # odd"},
+			},
+		},
+	}, 150)
+}
+
+func TestParseReportFormat(t *testing.T) {
+	f, err := ParseReportFormat("JUnit")
+	require.NoError(t, err)
+	assert.Equal(t, ReportJUnit, f)
+
+	_, err = ParseReportFormat("xml")
+	assert.ErrorContains(t, err, `unknown format "xml" (supported: text, json, junit, tap, sarif, markdown, ndjson)`)
+}
+
+func TestNewBatchHealthResult(t *testing.T) {
+	batch := sampleBatch()
+	assert.Equal(t, 2, batch.TotalURLs)
+	assert.Equal(t, 0, batch.Passed)
+	assert.Equal(t, 1, batch.PassedWarn)
+	assert.Equal(t, 1, batch.Failed)
+	assert.Equal(t, int64(150), batch.Duration)
+}
+
+func TestWriteReport_JUnit(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReport(&sb, ReportJUnit, sampleBatch()))
+	assert.True(t, strings.HasPrefix(sb.String(), `<?xml version="1.0" encoding="UTF-8"?>`))
+
+	var doc junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(sb.String()), &doc))
+	assert.Equal(t, 2, doc.Tests)
+	assert.Equal(t, 1, doc.Failures)
+	require.Len(t, doc.Suites, 1)
+	cases := doc.Suites[0].Cases
+	require.Len(t, cases, 2)
+
+	assert.Equal(t, "GET https://ok.example/api", cases[0].Name)
+	assert.Equal(t, 2, cases[0].Assertions)
+	assert.Equal(t, "0.120", cases[0].Time)
+	assert.Nil(t, cases[0].Failure)
+	assert.Contains(t, cases[0].SystemOut, "[warn] Known token: Token not in registry")
+
+	require.NotNil(t, cases[1].Failure)
+	assert.Equal(t, "Returns 402: Got 200 instead of 402 # odd", cases[1].Failure.Message)
+	assert.Equal(t, "exit code 1", cases[1].Failure.Type)
+}
+
+func TestWriteReport_TAP(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReport(&sb, ReportTAP, sampleBatch()))
+
+	expected := `TAP version 14
+1..2
+# Subtest: GET https://ok.example/api
+    1..2
+    ok 1 - Endpoint reachable
+    ok 2 - Known token
+      ---
+      severity: warn
+      message: "Token not in registry"
+      ...
+ok 1 - GET https://ok.example/api
+# Subtest: POST https://bad.example/api
+    1..2
+    ok 1 - Endpoint reachable
+    not ok 2 - Returns 402
+      ---
+      severity: fail
+      message: "Got 200 instead of 402 # odd"
+      ...
+not ok 2 - POST https://bad.example/api
+`
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestWriteReport_SARIF(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReport(&sb, ReportSARIF, sampleBatch(), WithToolVersion("1.2.3")))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+	require.Len(t, run.Results, 2)
+	assert.Equal(t, "x402/known-token", run.Results[0].RuleID)
+	assert.Equal(t, "warning", run.Results[0].Level)
+	assert.Equal(t, "x402/returns-402", run.Results[1].RuleID)
+	assert.Equal(t, "error", run.Results[1].Level)
+	assert.Equal(t, "POST https://bad.example/api: Got 200 instead of 402 # odd", run.Results[1].Message.Text)
+	assert.Equal(t, "https://bad.example/api", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Len(t, run.Tool.Driver.Rules, 2)
+}
+
+func TestWriteReport_Markdown(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReport(&sb, ReportMarkdown, sampleBatch()))
+	md := sb.String()
+
+	assert.Contains(t, md, "**2 endpoint(s)** · ✅ 0 passed · ⚠️ 1 warned · ❌ 1 failed · 150ms")
+	assert.Contains(t, md, "| ⚠️ | `GET https://ok.example/api` | v2 | 120ms | 0.01 USDC |")
+	assert.Contains(t, md, "| ❌ | `POST https://bad.example/api` | - | 30ms | - |")
+	assert.Contains(t, md, "### ❌ `POST https://bad.example/api`\n\n- ❌ **Returns 402**: Got 200 instead of 402 # odd")
+	assert.NotContains(t, md, "**Endpoint reachable**")
+}
+
+func TestWriteReport_NDJSON(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReport(&sb, ReportNDJSON, sampleBatch()))
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var rec StreamRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	assert.Equal(t, 1, rec.Index)
+	require.NotNil(t, rec.HealthResult)
+	assert.Equal(t, "https://bad.example/api", rec.URL)
+	assert.Equal(t, 1, rec.ExitCode)
+}
+
+func TestWriteReport_TextIsNotAReport(t *testing.T) {
+	var sb strings.Builder
+	assert.Error(t, WriteReport(&sb, ReportText, sampleBatch()))
+}
@@ -0,0 +1,409 @@
+// Package payment runs the x402 payment flow: request a resource, parse the
+// 402 response, select a payment option, sign it and retry with the payment.
+//
+// A Payment is a small state machine driven in two steps. Quote sends the
+// unpaid request and selects an option; Pay loads the signer, confirms,
+// signs and sends the paid request. Callers can stop between the two, e.g.
+// for a dry run, and customize each step with hooks.
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/wallet"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// State is a step of the payment flow.
+type State int
+
+// Payment flow states, in the order they are entered.
+const (
+	StateNew         State = iota
+	StateRequesting        // Sending the unpaid request
+	StateNotRequired       // The resource was served without payment (final)
+	StateParsing           // Parsing the 402 response
+	StateQuoted            // An option was selected; nothing has been signed
+	StateLoadingSigner
+	StateConfirming
+	StateSigning
+	StateEncoding
+	StateSending // Sending the paid request; the server may receive the payment
+	StatePaid    // The paid response arrived, whatever its status (final)
+)
+
+var stateNames = map[State]string{
+	StateNew:           "new",
+	StateRequesting:    "requesting",
+	StateNotRequired:   "not-required",
+	StateParsing:       "parsing",
+	StateQuoted:        "quoted",
+	StateLoadingSigner: "loading-signer",
+	StateConfirming:    "confirming",
+	StateSigning:       "signing",
+	StateEncoding:      "encoding",
+	StateSending:       "sending",
+	StatePaid:          "paid",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("state %d", int(s))
+}
+
+// SelectFunc chooses the payment option to pay from a 402 response.
+type SelectFunc func(required *x402.PaymentRequired) (*x402.PaymentRequirement, error)
+
+// SignerFunc returns the signer for the selected option, e.g. by loading a
+// wallet for its chain.
+type SignerFunc func(ctx context.Context, p *Payment) (wallet.Signer, error)
+
+// ConfirmFunc approves a payment before it is signed. Returning an error
+// stops the flow with that error.
+type ConfirmFunc func(ctx context.Context, p *Payment) error
+
+// Observer is notified as the payment enters each state.
+type Observer func(state State, p *Payment)
+
+// Payment is a single x402 payment flow for one request.
+type Payment struct {
+	client         *client.Client
+	method         string
+	url            string
+	headers        map[string]string
+	body           []byte
+	protocol       int
+	idempotencyKey string
+	selectOption   SelectFunc
+	signerFor      SignerFunc
+	confirm        ConfirmFunc
+	observers      []Observer
+
+	state State
+
+	// Set by Quote
+	Initial  *client.RequestResult    // The unpaid response
+	Required *x402.ParseResult        // The parsed 402 response
+	Option   *x402.PaymentRequirement // The selected option
+	Solana   bool                     // Option is paid on Solana
+	ChainID  int64                    // EVM chain ID of the option
+	Protocol int                      // Protocol version the payment is sent with
+
+	// Set by Pay
+	Signer   wallet.Signer
+	Paid     *client.RequestResult // The paid response; its body is unread
+	Response *x402.PaymentResponse // Payment response header, if any
+}
+
+// Option configures a Payment.
+type Option func(*Payment)
+
+// WithHeaders sets headers sent with both the unpaid and the paid request.
+func WithHeaders(headers map[string]string) Option {
+	return func(p *Payment) {
+		for k, v := range headers {
+			p.headers[k] = v
+		}
+	}
+}
+
+// WithBody sets the request body. The paid request resends the same bytes.
+func WithBody(body []byte) Option {
+	return func(p *Payment) {
+		p.body = body
+	}
+}
+
+// WithProtocol forces the protocol version of the payment. 0 follows the
+// 402 response, except that Solana payments default to v2.
+func WithProtocol(version int) Option {
+	return func(p *Payment) {
+		p.protocol = version
+	}
+}
+
+// WithIdempotencyKey sends an Idempotency-Key header with the paid request,
+// allowing the client to retry it.
+func WithIdempotencyKey(key string) Option {
+	return func(p *Payment) {
+		p.idempotencyKey = key
+	}
+}
+
+// WithSelector sets how the payment option is chosen. The default is
+// SelectEVM.
+func WithSelector(fn SelectFunc) Option {
+	return func(p *Payment) {
+		p.selectOption = fn
+	}
+}
+
+// WithSigner sets how the signer is loaded. Pay fails without one.
+func WithSigner(fn SignerFunc) Option {
+	return func(p *Payment) {
+		p.signerFor = fn
+	}
+}
+
+// WithConfirm sets a hook that approves the payment before signing.
+// Without one, payments are made without confirmation.
+func WithConfirm(fn ConfirmFunc) Option {
+	return func(p *Payment) {
+		p.confirm = fn
+	}
+}
+
+// WithObserver adds an observer of state changes.
+func WithObserver(fn Observer) Option {
+	return func(p *Payment) {
+		p.observers = append(p.observers, fn)
+	}
+}
+
+// New creates a payment flow for a request to url.
+func New(c *client.Client, method, url string, opts ...Option) *Payment {
+	p := &Payment{
+		client:       c,
+		method:       method,
+		url:          url,
+		headers:      map[string]string{},
+		selectOption: SelectEVM,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// State returns the current state of the flow.
+func (p *Payment) State() State {
+	return p.state
+}
+
+// SignatureSent reports whether the paid request was sent, so the server
+// may settle the payment even if the flow failed or was cancelled.
+func (p *Payment) SignatureSent() bool {
+	return p.state >= StateSending
+}
+
+func (p *Payment) enter(state State) {
+	p.state = state
+	for _, fn := range p.observers {
+		fn(state, p)
+	}
+}
+
+// Run quotes and pays in one step. A resource served without payment is
+// not an error; check State for StateNotRequired.
+func (p *Payment) Run(ctx context.Context) error {
+	if err := p.Quote(ctx); err != nil || p.state == StateNotRequired {
+		return err
+	}
+	return p.Pay(ctx)
+}
+
+// Quote sends the unpaid request, parses the 402 response and selects a
+// payment option. If the resource is served with 200 instead, the flow ends
+// in StateNotRequired with the response in Initial.
+func (p *Payment) Quote(ctx context.Context) error {
+	if p.state != StateNew {
+		return exitcode.Errorf(exitcode.General, "payment already quoted")
+	}
+
+	p.enter(StateRequesting)
+	result, err := p.client.TimedRequest(ctx, p.method, p.url, p.headers, p.body)
+	if err != nil {
+		return classify(ctx, exitcode.Network, "connection failed: %w", err)
+	}
+	p.Initial = result
+
+	switch result.Response.StatusCode {
+	case http.StatusPaymentRequired:
+	case http.StatusOK:
+		p.enter(StateNotRequired)
+		return nil
+	default:
+		return exitcode.Errorf(exitcode.Protocol, "expected 402 Payment Required, got %d", result.Response.StatusCode)
+	}
+
+	p.enter(StateParsing)
+	p.Required, err = x402.ParsePaymentRequired(result.Response)
+	if err != nil {
+		return exitcode.Errorf(exitcode.Protocol, "failed to parse payment requirements: %w", err)
+	}
+
+	p.Option, err = p.selectOption(p.Required.PaymentRequired)
+	if err != nil {
+		return exitcode.Errorf(exitcode.Protocol, "select payment option: %w", err)
+	}
+	p.Solana = x402.IsSolanaNetwork(p.Option.Network)
+
+	// Solana payments default to v2 regardless of the detected version
+	p.Protocol = p.Required.ProtocolVersion
+	if p.Solana {
+		p.Protocol = x402.ProtocolV2
+	}
+	if p.protocol != 0 {
+		p.Protocol = p.protocol
+	}
+
+	if !p.Solana {
+		p.ChainID, err = x402.ExtractChainID(p.Option.Network)
+		if err != nil {
+			return exitcode.Errorf(exitcode.Protocol, "invalid network: %w", err)
+		}
+	}
+
+	p.enter(StateQuoted)
+	return nil
+}
+
+// Pay signs the quoted option and sends the paid request. It returns once
+// the paid response headers arrive, whatever the status; the caller reads
+// Paid.Response.Body and checks the status. A failure after SignatureSent
+// is classified as exitcode.PaymentUnconfirmed, as the payment may have
+// settled. Errors caused by cancelling ctx are classified as
+// exitcode.Cancelled.
+func (p *Payment) Pay(ctx context.Context) error {
+	if p.state != StateQuoted {
+		return exitcode.Errorf(exitcode.General, "payment is %s, not quoted", p.state)
+	}
+	if p.signerFor == nil {
+		return exitcode.Errorf(exitcode.Input, "no signer configured")
+	}
+
+	p.enter(StateLoadingSigner)
+	signer, err := p.signerFor(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.Signer = signer
+
+	if p.confirm != nil {
+		p.enter(StateConfirming)
+		if err := p.confirm(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	p.enter(StateSigning)
+	var params wallet.SignParams
+	if p.Solana {
+		params = wallet.PrepareSolanaSignParams(p.Option, signer.Address())
+	} else {
+		params = wallet.PrepareSignParams(p.Option, signer.Address(), p.ChainID)
+	}
+	signed, err := signer.Sign(ctx, params)
+	if err != nil {
+		return classify(ctx, exitcode.General, "failed to sign authorization: %w", err)
+	}
+
+	p.enter(StateEncoding)
+	resource := p.Required.PaymentRequired.Resource
+	if resource.URL == "" {
+		// v1 doesn't have resource in top-level
+		resource = x402.ResourceInfo{URL: p.url}
+	}
+	// The option and version were validated by Quote, so encoding only fails
+	// on a bug
+	var headerName, headerValue string
+	if p.Solana {
+		// Solana uses the transaction as the payload
+		headerName, headerValue, err = x402.BuildAndEncodeSolanaPayload(p.Protocol, resource, p.Option, signed.Signature)
+		if err != nil {
+			return exitcode.Errorf(exitcode.General, "failed to encode Solana payload: %w", err)
+		}
+	} else {
+		headerName, headerValue, err = x402.BuildAndEncodePayload(p.Protocol, resource, p.Option, signed.Signature, signed.Authorization)
+		if err != nil {
+			return exitcode.Errorf(exitcode.General, "failed to build payment payload: %w", err)
+		}
+	}
+
+	headers := make(map[string]string, len(p.headers)+2)
+	for k, v := range p.headers {
+		headers[k] = v
+	}
+	headers[headerName] = headerValue
+	if p.idempotencyKey != "" {
+		headers[client.HeaderIdempotencyKey] = p.idempotencyKey
+	}
+
+	p.enter(StateSending)
+	result, err := p.client.TimedRequest(ctx, p.method, p.url, headers, p.body)
+	if err != nil {
+		return classify(ctx, exitcode.PaymentUnconfirmed, "retry request failed: %w", err)
+	}
+	p.Paid = result
+	p.Response, _ = x402.ParsePaymentResponse(result.Response, p.Protocol)
+
+	p.enter(StatePaid)
+	return nil
+}
+
+// classify wraps err with code, or as exitcode.Cancelled if ctx was
+// cancelled.
+func classify(ctx context.Context, code exitcode.Code, format string, err error) error {
+	if ctx.Err() != nil {
+		code = exitcode.Cancelled
+	}
+	return exitcode.Errorf(code, format, err)
+}
+
+// Close closes the response bodies held by the payment.
+func (p *Payment) Close() error {
+	for _, r := range []*client.RequestResult{p.Initial, p.Paid} {
+		if r != nil {
+			r.Response.Body.Close()
+		}
+	}
+	return nil
+}
+
+// SelectEVM selects the first EVM option, or fails if there is none.
+func SelectEVM(required *x402.PaymentRequired) (*x402.PaymentRequirement, error) {
+	if option := x402.FindEVMOption(required); option != nil {
+		return option, nil
+	}
+	return nil, fmt.Errorf("no supported EVM payment options found")
+}
+
+// SelectSolana selects the first Solana option, or fails if there is none.
+func SelectSolana(required *x402.PaymentRequired) (*x402.PaymentRequirement, error) {
+	if option := x402.FindSolanaOption(required); option != nil {
+		return option, nil
+	}
+	return nil, fmt.Errorf("no supported Solana payment options found")
+}
+
+// RejectionCode classifies a rejected payment from the payment response
+// header and the response body.
+func RejectionCode(paymentResp *x402.PaymentResponse, body []byte) exitcode.Code {
+	reasons := []string{}
+	if paymentResp != nil {
+		reasons = append(reasons, paymentResp.ErrorReason, paymentResp.Error)
+	}
+	var rejected struct {
+		Error         string `json:"error"`
+		InvalidReason string `json:"invalidReason"`
+		ErrorReason   string `json:"errorReason"`
+	}
+	if json.Unmarshal(body, &rejected) == nil {
+		reasons = append(reasons, rejected.Error, rejected.InvalidReason, rejected.ErrorReason)
+	}
+
+	for _, reason := range reasons {
+		if strings.Contains(strings.ToLower(reason), "insufficient_funds") {
+			return exitcode.InsufficientFunds
+		}
+	}
+	return exitcode.PaymentRejected
+}
@@ -0,0 +1,239 @@
+package payment
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/client"
+	"github.com/port402/x402-cli/internal/exitcode"
+	"github.com/port402/x402-cli/internal/wallet"
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// fakeSigner signs without a key, recording what it was asked to sign.
+type fakeSigner struct {
+	params *wallet.SignParams
+}
+
+func (s *fakeSigner) Sign(ctx context.Context, params wallet.SignParams) (*wallet.SignResult, error) {
+	s.params = &params
+	return &wallet.SignResult{
+		Signature:     "0xsigned",
+		Authorization: x402.Authorization{From: params.From, To: params.To, Value: params.Value},
+	}, nil
+}
+
+func (s *fakeSigner) Address() string {
+	return "0x1111111111111111111111111111111111111111"
+}
+
+func signerHook(s wallet.Signer) SignerFunc {
+	return func(context.Context, *Payment) (wallet.Signer, error) { return s, nil }
+}
+
+var testRequired = &x402.PaymentRequired{
+	X402Version: 2,
+	Resource:    x402.ResourceInfo{URL: "https://example.com/api"},
+	Accepts: []x402.PaymentRequirement{{
+		Scheme:            "exact",
+		Network:           "eip155:84532",
+		Amount:            "1000",
+		Asset:             "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+		PayTo:             "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		MaxTimeoutSeconds: 300,
+		Extra:             map[string]interface{}{"name": "USDC", "version": "2"},
+	}},
+}
+
+// newPaywall serves 402 with testRequired until a payment header arrives,
+// then answers with paidStatus and paidBody.
+func newPaywall(t *testing.T, paidStatus int, paidBody string) (*httptest.Server, *[]*http.Request) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.Header.Get(x402.HeaderPaymentSignature) == "" {
+			data, err := json.Marshal(testRequired)
+			require.NoError(t, err)
+			w.Header().Set(x402.HeaderPaymentRequired, base64.StdEncoding.EncodeToString(data))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.WriteHeader(paidStatus)
+		io.WriteString(w, paidBody)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestPayment_Run(t *testing.T) {
+	server, requests := newPaywall(t, http.StatusOK, "paid content")
+	signer := &fakeSigner{}
+
+	var states []State
+	p := New(client.New(), http.MethodGet, server.URL,
+		WithHeaders(map[string]string{"X-Trace": "abc"}),
+		WithIdempotencyKey("order-42"),
+		WithSigner(signerHook(signer)),
+		WithConfirm(func(ctx context.Context, p *Payment) error { return nil }),
+		WithObserver(func(state State, p *Payment) { states = append(states, state) }),
+	)
+	defer p.Close()
+
+	require.NoError(t, p.Run(context.Background()))
+
+	assert.Equal(t, []State{
+		StateRequesting, StateParsing, StateQuoted, StateLoadingSigner,
+		StateConfirming, StateSigning, StateEncoding, StateSending, StatePaid,
+	}, states)
+	assert.True(t, p.SignatureSent())
+	assert.Equal(t, x402.ProtocolV2, p.Protocol)
+	assert.Equal(t, int64(84532), p.ChainID)
+	assert.False(t, p.Solana)
+
+	require.NotNil(t, signer.params)
+	assert.Equal(t, signer.Address(), signer.params.From)
+	assert.Equal(t, "1000", signer.params.Value)
+
+	body, err := io.ReadAll(p.Paid.Response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "paid content", string(body))
+
+	require.Len(t, *requests, 2)
+	paid := (*requests)[1]
+	assert.NotEmpty(t, paid.Header.Get(x402.HeaderPaymentSignature))
+	assert.Equal(t, "order-42", paid.Header.Get(client.HeaderIdempotencyKey))
+	assert.Equal(t, "abc", paid.Header.Get("X-Trace"))
+	assert.Empty(t, (*requests)[0].Header.Get(client.HeaderIdempotencyKey))
+}
+
+func TestPayment_QuoteThenStop(t *testing.T) {
+	server, requests := newPaywall(t, http.StatusOK, "")
+
+	p := New(client.New(), http.MethodGet, server.URL, WithProtocol(x402.ProtocolV1))
+	defer p.Close()
+
+	require.NoError(t, p.Quote(context.Background()))
+	assert.Equal(t, StateQuoted, p.State())
+	assert.Equal(t, x402.ProtocolV1, p.Protocol)
+	assert.Equal(t, "1000", p.Option.GetAmount())
+	assert.False(t, p.SignatureSent())
+	assert.Len(t, *requests, 1)
+
+	err := p.Pay(context.Background())
+	assert.ErrorContains(t, err, "no signer configured")
+	assert.Equal(t, exitcode.Input, exitcode.Of(err))
+
+	err = p.Quote(context.Background())
+	assert.ErrorContains(t, err, "already quoted")
+	assert.Equal(t, exitcode.General, exitcode.Of(err))
+}
+
+func TestPayment_ConfirmDeclined(t *testing.T) {
+	server, requests := newPaywall(t, http.StatusOK, "")
+	signer := &fakeSigner{}
+	declined := errors.New("declined")
+
+	p := New(client.New(), http.MethodGet, server.URL,
+		WithSigner(signerHook(signer)),
+		WithConfirm(func(ctx context.Context, p *Payment) error { return declined }),
+	)
+	defer p.Close()
+
+	assert.ErrorIs(t, p.Run(context.Background()), declined)
+	assert.Equal(t, StateConfirming, p.State())
+	assert.False(t, p.SignatureSent())
+	assert.Nil(t, signer.params, "nothing may be signed before confirmation")
+	assert.Len(t, *requests, 1)
+}
+
+func TestPayment_Rejected(t *testing.T) {
+	server, _ := newPaywall(t, http.StatusPaymentRequired, `{"error":"insufficient_funds"}`)
+
+	p := New(client.New(), http.MethodGet, server.URL, WithSigner(signerHook(&fakeSigner{})))
+	defer p.Close()
+
+	// A rejection is a completed flow; the caller inspects the response
+	require.NoError(t, p.Run(context.Background()))
+	assert.Equal(t, StatePaid, p.State())
+	assert.Equal(t, http.StatusPaymentRequired, p.Paid.Response.StatusCode)
+
+	body, _ := io.ReadAll(p.Paid.Response.Body)
+	assert.Equal(t, exitcode.InsufficientFunds, RejectionCode(p.Response, body))
+}
+
+func TestPayment_NotRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "free")
+	}))
+	defer server.Close()
+
+	p := New(client.New(), http.MethodGet, server.URL)
+	defer p.Close()
+
+	require.NoError(t, p.Run(context.Background()))
+	assert.Equal(t, StateNotRequired, p.State())
+	body, _ := io.ReadAll(p.Initial.Response.Body)
+	assert.Equal(t, "free", string(body))
+}
+
+func TestPayment_QuoteErrors(t *testing.T) {
+	teapot := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer teapot.Close()
+	paywall, _ := newPaywall(t, http.StatusOK, "")
+
+	tests := []struct {
+		name string
+		url  string
+		opts []Option
+		want exitcode.Code
+	}{
+		{"unexpected status", teapot.URL, nil, exitcode.Protocol},
+		{"no matching option", paywall.URL, []Option{WithSelector(SelectSolana)}, exitcode.Protocol},
+		{"unreachable", "http://127.0.0.1:1", nil, exitcode.Network},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(client.New(), http.MethodGet, tt.url, tt.opts...)
+			defer p.Close()
+			assert.Equal(t, tt.want, exitcode.Of(p.Quote(context.Background())))
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := New(client.New(), http.MethodGet, paywall.URL)
+	assert.Equal(t, exitcode.Cancelled, exitcode.Of(p.Quote(ctx)))
+}
+
+func TestRejectionCode(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *x402.PaymentResponse
+		body string
+		want exitcode.Code
+	}{
+		{"no details", nil, "", exitcode.PaymentRejected},
+		{"header reason", &x402.PaymentResponse{ErrorReason: "insufficient_funds"}, "", exitcode.InsufficientFunds},
+		{"body reason", nil, `{"invalidReason":"INSUFFICIENT_FUNDS"}`, exitcode.InsufficientFunds},
+		{"other reason", nil, `{"error":"invalid_signature"}`, exitcode.PaymentRejected},
+		{"not JSON", nil, "insufficient funds", exitcode.PaymentRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RejectionCode(tt.resp, []byte(tt.body)))
+		})
+	}
+}
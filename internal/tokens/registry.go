@@ -172,6 +172,22 @@ var networkNames = map[string]NetworkInfo{
 	"testnet":             {Name: "Solana Testnet", IsTestnet: true},
 }
 
+// v1NetworkNames maps CAIP-2 identifiers to the plain network names used by
+// the v1 protocol.
+var v1NetworkNames = map[string]string{
+	"eip155:1":        "ethereum",
+	"eip155:8453":     "base",
+	"eip155:84532":    "base-sepolia",
+	"eip155:11155111": "sepolia",
+	"eip155:137":      "polygon",
+	"eip155:42161":    "arbitrum",
+	"eip155:10":       "optimism",
+
+	"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp": "solana",
+	"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1": "solana-devnet",
+	"solana:4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z": "solana-testnet",
+}
+
 // GetTokenInfo looks up token metadata by network and asset address.
 // Returns nil if the token is not in the registry.
 // Solana addresses are case-sensitive (base58), EVM addresses are not (hex).
@@ -198,6 +214,25 @@ func GetNetworkInfo(network string) *NetworkInfo {
 	return nil
 }
 
+// SameNetwork reports whether a and b identify the same network, in CAIP-2
+// or simple-name form, e.g. "base" and "eip155:8453".
+func SameNetwork(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	infoA, infoB := lookupNetwork(a), lookupNetwork(b)
+	return infoA != nil && infoB != nil && infoA.Name == infoB.Name
+}
+
+// lookupNetwork is GetNetworkInfo, falling back to the lowercase name for
+// simple names such as "Base". CAIP-2 Solana identifiers are case-sensitive.
+func lookupNetwork(network string) *NetworkInfo {
+	if info := GetNetworkInfo(network); info != nil {
+		return info
+	}
+	return GetNetworkInfo(strings.ToLower(network))
+}
+
 // GetNetworkName returns a human-readable network name.
 // Falls back to the raw network identifier if not found.
 func GetNetworkName(network string) string {
@@ -215,6 +250,13 @@ func IsTestnet(network string) bool {
 	return false
 }
 
+// V1NetworkName returns the v1 protocol name of a CAIP-2 network, e.g.
+// "base-sepolia" for eip155:84532. Returns empty string if the network has
+// no v1 name.
+func V1NetworkName(network string) string {
+	return v1NetworkNames[network]
+}
+
 // NetworkEntry represents a canonical network for listing purposes.
 type NetworkEntry struct {
 	ID        string // CAIP-2 identifier
@@ -133,3 +133,25 @@ func TestIsTestnet(t *testing.T) {
 		})
 	}
 }
+
+func TestV1NetworkName(t *testing.T) {
+	assert.Equal(t, "base-sepolia", V1NetworkName("eip155:84532"))
+	assert.Equal(t, "solana-devnet", V1NetworkName("solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"))
+	assert.Empty(t, V1NetworkName("eip155:999999"))
+
+	// Every v1 name resolves back to the same network
+	for _, n := range ListNetworks() {
+		name := V1NetworkName(n.ID)
+		assert.NotEmpty(t, name, n.ID)
+		assert.Equal(t, n.Name, GetNetworkName(name), n.ID)
+	}
+}
+
+func TestSameNetwork(t *testing.T) {
+	assert.True(t, SameNetwork("base", "eip155:8453"))
+	assert.True(t, SameNetwork("eip155:84532", "Base-Sepolia"))
+	assert.True(t, SameNetwork("solana", "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"))
+	assert.True(t, SameNetwork("eip155:999999", "EIP155:999999"))
+	assert.False(t, SameNetwork("base", "eip155:84532"))
+	assert.False(t, SameNetwork("eip155:999999", "eip155:999998"))
+}
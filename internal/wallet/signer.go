@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"context"
 	"crypto/ecdsa"
 
 	"github.com/port402/x402-cli/internal/x402"
@@ -11,7 +12,8 @@ import (
 type Signer interface {
 	// Sign creates a signature for the given payment parameters.
 	// Returns the signature and authorization details needed for the payment payload.
-	Sign(params SignParams) (*SignResult, error)
+	// Cancelling ctx aborts any network calls made while signing.
+	Sign(ctx context.Context, params SignParams) (*SignResult, error)
 
 	// Address returns the signer's address in the appropriate format for the chain.
 	Address() string
@@ -86,5 +88,5 @@ func PrepareSignParams(option *x402.PaymentRequirement, fromAddress string, chai
 // It maintains backward compatibility with existing code.
 func SignTransferAuthorization(key *ecdsa.PrivateKey, params SignParams) (*SignResult, error) {
 	signer := NewEVMSigner(key)
-	return signer.Sign(params)
+	return signer.Sign(context.Background(), params)
 }
@@ -1,10 +1,12 @@
 package wallet
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -33,7 +35,11 @@ func NewEVMSigner(key *ecdsa.PrivateKey) *EVMSigner {
 // Sign creates an EIP-712 signature for EIP-3009 TransferWithAuthorization.
 // This enables gasless token transfers: the signer authorizes a transfer off-chain,
 // and a third party (the facilitator) executes it on-chain, paying the gas.
-func (s *EVMSigner) Sign(params SignParams) (*SignResult, error) {
+func (s *EVMSigner) Sign(ctx context.Context, params SignParams) (*SignResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Generate random nonce (32 bytes)
 	nonceBytes := make([]byte, 32)
 	if _, err := rand.Read(nonceBytes); err != nil {
@@ -58,26 +64,12 @@ func (s *EVMSigner) Sign(params SignParams) (*SignResult, error) {
 		return nil, fmt.Errorf("invalid payment value: %q", params.Value)
 	}
 
-	// Build EIP-712 typed data
-	typedData := buildTypedData(params, nonce, validBefore, value)
-
-	// Hash the typed data (EIP-712)
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash domain: %w", err)
-	}
-
-	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	// Build and hash EIP-712 typed data
+	hash, err := hashTypedData(buildTypedData(params, nonce, validBefore, value))
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash message: %w", err)
+		return nil, err
 	}
 
-	// Construct final hash: keccak256("\x19\x01" || domainSeparator || messageHash)
-	rawData := []byte{0x19, 0x01}
-	rawData = append(rawData, domainSeparator...)
-	rawData = append(rawData, messageHash...)
-	hash := crypto.Keccak256Hash(rawData)
-
 	// Sign the hash
 	signature, err := crypto.Sign(hash.Bytes(), s.privateKey)
 	if err != nil {
@@ -115,6 +107,76 @@ func (s *EVMSigner) Address() string {
 	return crypto.PubkeyToAddress(s.privateKey.PublicKey).Hex()
 }
 
+// RecoverTransferAuthorizationSigner returns the address that signed an EIP-3009
+// TransferWithAuthorization. The EIP-712 domain (token name, version and
+// contract) is derived from the payment requirement the authorization was made for.
+func RecoverTransferAuthorizationSigner(option *x402.PaymentRequirement, chainID int64, auth x402.Authorization, signature string) (string, error) {
+	sig := common.FromHex(signature)
+	if len(sig) != 65 {
+		return "", fmt.Errorf("unexpected signature length: got %d, want 65", len(sig))
+	}
+	// Copy before normalizing v so the caller's bytes are untouched
+	sig = append([]byte(nil), sig...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	if sig[64] > 1 {
+		return "", fmt.Errorf("unexpected recovery id: %d", sig[64])
+	}
+
+	validAfter, err := strconv.ParseInt(auth.ValidAfter, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid validAfter: %q", auth.ValidAfter)
+	}
+	validBefore, err := strconv.ParseInt(auth.ValidBefore, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid validBefore: %q", auth.ValidBefore)
+	}
+	value := new(big.Int)
+	if _, ok := value.SetString(auth.Value, 10); !ok {
+		return "", fmt.Errorf("invalid payment value: %q", auth.Value)
+	}
+	nonceBytes := common.FromHex(auth.Nonce)
+	if len(nonceBytes) != 32 {
+		return "", fmt.Errorf("invalid nonce: expected 32 bytes, got %d", len(nonceBytes))
+	}
+
+	params := PrepareSignParams(option, auth.From, chainID)
+	params.To = auth.To
+	params.Value = auth.Value
+	params.ValidAfter = validAfter
+
+	hash, err := hashTypedData(buildTypedData(params, common.BytesToHash(nonceBytes), validBefore, value))
+	if err != nil {
+		return "", err
+	}
+
+	pubKey, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubKey).Hex(), nil
+}
+
+// hashTypedData computes the EIP-712 digest that is signed for typed data:
+// keccak256("\x19\x01" || domainSeparator || messageHash).
+func hashTypedData(typedData apitypes.TypedData) (common.Hash, error) {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash domain: %w", err)
+	}
+
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash message: %w", err)
+	}
+
+	rawData := []byte{0x19, 0x01}
+	rawData = append(rawData, domainSeparator...)
+	rawData = append(rawData, messageHash...)
+	return crypto.Keccak256Hash(rawData), nil
+}
+
 // buildTypedData constructs the EIP-712 typed data for TransferWithAuthorization.
 func buildTypedData(params SignParams, nonce common.Hash, validBefore int64, value *big.Int) apitypes.TypedData {
 	return apitypes.TypedData{
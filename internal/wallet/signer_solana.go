@@ -26,6 +26,9 @@ const (
 	// The spec requires ≤5 lamports/unit. We use 1 microLamport for low priority.
 	// For higher priority, facilitators can adjust this.
 	defaultComputeUnitPrice uint64 = 1
+
+	// rpcTimeout bounds the RPC calls made while building a transaction.
+	rpcTimeout = 15 * time.Second
 )
 
 // SolanaSigner implements the Signer interface for Solana payments.
@@ -55,8 +58,10 @@ func NewSolanaSigner(privateKey solana.PrivateKey, rpcURL string) *SolanaSigner
 //
 // The transaction is signed by the token owner but requires the fee payer's signature
 // to be fully valid (facilitator adds this).
-func (s *SolanaSigner) Sign(params SignParams) (*SignResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+//
+// RPC calls are cancelled when ctx is, and time out after rpcTimeout.
+func (s *SolanaSigner) Sign(ctx context.Context, params SignParams) (*SignResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
 	defer cancel()
 
 	payerPubkey := s.privateKey.PublicKey()
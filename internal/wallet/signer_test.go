@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"context"
 	"encoding/hex"
 	"strings"
 	"testing"
@@ -282,3 +283,62 @@ func TestSignerGetAddress(t *testing.T) {
 	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
 	assert.Equal(t, signerTestAddress, address)
 }
+
+func TestRecoverTransferAuthorizationSigner(t *testing.T) {
+	key, err := LoadFromHex(signerTestPrivateKey)
+	require.NoError(t, err)
+
+	option := &x402.PaymentRequirement{
+		Scheme:            "exact",
+		Network:           "eip155:84532",
+		Amount:            "1000",
+		Asset:             "0x036cbd53842c5426634e7929541ec2318f3dcf7e",
+		PayTo:             "0x64c2310BD1151266AA2Ad2410447E133b7F84e29",
+		MaxTimeoutSeconds: 300,
+	}
+	result, err := SignTransferAuthorization(key, PrepareSignParams(option, signerTestAddress, 84532))
+	require.NoError(t, err)
+
+	t.Run("recovers signer", func(t *testing.T) {
+		signer, err := RecoverTransferAuthorizationSigner(option, 84532, result.Authorization, result.Signature)
+		require.NoError(t, err)
+		assert.Equal(t, signerTestAddress, signer)
+	})
+
+	t.Run("different chain recovers different address", func(t *testing.T) {
+		signer, err := RecoverTransferAuthorizationSigner(option, 8453, result.Authorization, result.Signature)
+		require.NoError(t, err)
+		assert.NotEqual(t, signerTestAddress, signer)
+	})
+
+	t.Run("tampered value recovers different address", func(t *testing.T) {
+		auth := result.Authorization
+		auth.Value = "999999"
+		signer, err := RecoverTransferAuthorizationSigner(option, 84532, auth, result.Signature)
+		require.NoError(t, err)
+		assert.NotEqual(t, signerTestAddress, signer)
+	})
+
+	t.Run("invalid signature length", func(t *testing.T) {
+		_, err := RecoverTransferAuthorizationSigner(option, 84532, result.Authorization, "0x1234")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid nonce", func(t *testing.T) {
+		auth := result.Authorization
+		auth.Nonce = "0x01"
+		_, err := RecoverTransferAuthorizationSigner(option, 84532, auth, result.Signature)
+		assert.Error(t, err)
+	})
+}
+
+func TestEVMSigner_CancelledContext(t *testing.T) {
+	key, err := LoadFromHex(signerTestPrivateKey)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = NewEVMSigner(key).Sign(ctx, SignParams{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
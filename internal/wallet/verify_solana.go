@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/token"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+// maxComputeUnitPrice is the highest compute unit price (in microLamports)
+// the x402 SVM spec allows: 5 lamports per compute unit.
+const maxComputeUnitPrice uint64 = 5_000_000
+
+// SolanaTransfer describes the SPL token transfer found in a verified transaction.
+type SolanaTransfer struct {
+	Payer       string // Token owner that signed the transfer
+	FeePayer    string // Account expected to pay fees (facilitator)
+	Mint        string // Token mint address
+	Destination string // Destination associated token account
+	Amount      uint64 // Amount in atomic units
+}
+
+// VerifySolanaTransaction decodes a base64-encoded, partially-signed transaction
+// and checks it against a payment requirement without contacting an RPC node.
+//
+// It enforces the instruction layout produced by SolanaSigner.Sign:
+// an optional CreateAssociatedTokenAccount, SetComputeUnitLimit,
+// SetComputeUnitPrice and a final TransferChecked to the payTo's token account
+// for exactly the required amount. The token owner's signature must be valid;
+// the fee payer's signature is expected to be missing.
+func VerifySolanaTransaction(txBase64 string, option *x402.PaymentRequirement) (*SolanaTransfer, error) {
+	tx, err := solana.TransactionFromBase64(txBase64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction encoding: %w", err)
+	}
+
+	instructions := tx.Message.Instructions
+	if len(instructions) != 3 && len(instructions) != 4 {
+		return nil, fmt.Errorf("expected 3 or 4 instructions, got %d", len(instructions))
+	}
+
+	// Optional leading ATA creation for a recipient without a token account
+	if len(instructions) == 4 {
+		programID, err := tx.ResolveProgramIDIndex(instructions[0].ProgramIDIndex)
+		if err != nil {
+			return nil, fmt.Errorf("instruction 1: %w", err)
+		}
+		if !programID.Equals(solana.SPLAssociatedTokenAccountProgramID) {
+			return nil, fmt.Errorf("instruction 1: expected associated token account program, got %s", programID)
+		}
+		instructions = instructions[1:]
+	}
+
+	if err := verifyComputeBudget(tx, instructions[0], instructions[1]); err != nil {
+		return nil, err
+	}
+
+	transfer, err := decodeTransferChecked(tx, instructions[2])
+	if err != nil {
+		return nil, err
+	}
+
+	// Mint, destination and amount must match the requirement exactly
+	mint := transfer.GetMintAccount().PublicKey
+	if mint.String() != option.Asset {
+		return nil, fmt.Errorf("mint mismatch: transaction uses %s, requirement expects %s", mint, option.Asset)
+	}
+
+	payTo, err := solana.PublicKeyFromBase58(option.PayTo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payTo address: %w", err)
+	}
+	expectedATA, _, err := solana.FindAssociatedTokenAddress(payTo, mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive payTo token account: %w", err)
+	}
+	destination := transfer.GetDestinationAccount().PublicKey
+	if !destination.Equals(expectedATA) {
+		return nil, fmt.Errorf("destination mismatch: transaction pays %s, expected %s", destination, expectedATA)
+	}
+
+	required, err := strconv.ParseUint(option.GetAmount(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid required amount: %w", err)
+	}
+	if *transfer.Amount != required {
+		return nil, fmt.Errorf("amount mismatch: transaction transfers %d, requirement expects %d", *transfer.Amount, required)
+	}
+
+	// Fee payer is always the first account key
+	if len(tx.Message.AccountKeys) == 0 {
+		return nil, fmt.Errorf("transaction has no account keys")
+	}
+	feePayer := tx.Message.AccountKeys[0]
+	if expected := option.GetExtraString("feePayer"); expected != "" && feePayer.String() != expected {
+		return nil, fmt.Errorf("fee payer mismatch: transaction uses %s, requirement expects %s", feePayer, expected)
+	}
+
+	owner := transfer.GetOwnerAccount().PublicKey
+	if owner.Equals(feePayer) {
+		return nil, fmt.Errorf("fee payer must not be the transfer authority")
+	}
+
+	if err := verifySignerSignature(tx, owner); err != nil {
+		return nil, err
+	}
+
+	return &SolanaTransfer{
+		Payer:       owner.String(),
+		FeePayer:    feePayer.String(),
+		Mint:        mint.String(),
+		Destination: destination.String(),
+		Amount:      *transfer.Amount,
+	}, nil
+}
+
+// verifyComputeBudget checks the SetComputeUnitLimit and SetComputeUnitPrice instructions.
+func verifyComputeBudget(tx *solana.Transaction, limitIx, priceIx solana.CompiledInstruction) error {
+	limit, err := decodeComputeBudget(tx, limitIx)
+	if err != nil {
+		return err
+	}
+	if _, ok := limit.Impl.(*computebudget.SetComputeUnitLimit); !ok {
+		return fmt.Errorf("expected SetComputeUnitLimit instruction")
+	}
+
+	price, err := decodeComputeBudget(tx, priceIx)
+	if err != nil {
+		return err
+	}
+	setPrice, ok := price.Impl.(*computebudget.SetComputeUnitPrice)
+	if !ok {
+		return fmt.Errorf("expected SetComputeUnitPrice instruction")
+	}
+	if setPrice.MicroLamports > maxComputeUnitPrice {
+		return fmt.Errorf("compute unit price %d exceeds maximum %d microLamports", setPrice.MicroLamports, maxComputeUnitPrice)
+	}
+	return nil
+}
+
+// decodeComputeBudget decodes a compiled instruction that must target the compute budget program.
+func decodeComputeBudget(tx *solana.Transaction, ix solana.CompiledInstruction) (*computebudget.Instruction, error) {
+	programID, err := tx.ResolveProgramIDIndex(ix.ProgramIDIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !programID.Equals(solana.ComputeBudget) {
+		return nil, fmt.Errorf("expected compute budget program, got %s", programID)
+	}
+
+	accounts, err := ix.ResolveInstructionAccounts(&tx.Message)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := computebudget.DecodeInstruction(accounts, ix.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compute budget instruction: %w", err)
+	}
+	return decoded, nil
+}
+
+// decodeTransferChecked decodes a compiled instruction that must be a token TransferChecked.
+func decodeTransferChecked(tx *solana.Transaction, ix solana.CompiledInstruction) (*token.TransferChecked, error) {
+	programID, err := tx.ResolveProgramIDIndex(ix.ProgramIDIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !programID.Equals(solana.TokenProgramID) {
+		return nil, fmt.Errorf("expected token program, got %s", programID)
+	}
+
+	accounts, err := ix.ResolveInstructionAccounts(&tx.Message)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := token.DecodeInstruction(accounts, ix.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token instruction: %w", err)
+	}
+
+	transfer, ok := decoded.Impl.(*token.TransferChecked)
+	if !ok {
+		return nil, fmt.Errorf("expected TransferChecked instruction")
+	}
+	if transfer.Amount == nil || transfer.GetMintAccount() == nil ||
+		transfer.GetDestinationAccount() == nil || transfer.GetOwnerAccount() == nil {
+		return nil, fmt.Errorf("incomplete TransferChecked instruction")
+	}
+	return transfer, nil
+}
+
+// verifySignerSignature checks that the transaction carries a valid signature from key.
+func verifySignerSignature(tx *solana.Transaction, key solana.PublicKey) error {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+
+	for i, signer := range tx.Message.Signers() {
+		if !signer.Equals(key) {
+			continue
+		}
+		if i >= len(tx.Signatures) {
+			return fmt.Errorf("missing signature for %s", key)
+		}
+		if !key.Verify(message, tx.Signatures[i]) {
+			return fmt.Errorf("invalid signature by %s", key)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not a signer of the transaction", key)
+}
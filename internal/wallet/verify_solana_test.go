@@ -0,0 +1,93 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/port402/x402-cli/internal/x402"
+)
+
+func TestVerifySolanaTransaction(t *testing.T) {
+	owner := solana.NewWallet().PrivateKey
+	feePayer := solana.NewWallet().PublicKey()
+	payTo := solana.NewWallet().PublicKey()
+	mint := solana.MustPublicKeyFromBase58("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU")
+
+	option := &x402.PaymentRequirement{
+		Network: x402.SolanaDevnet,
+		Amount:  "5000",
+		Asset:   mint.String(),
+		PayTo:   payTo.String(),
+		Extra:   map[string]interface{}{"feePayer": feePayer.String()},
+	}
+
+	build := func(t *testing.T, price uint64, sign bool) string {
+		source, _, err := solana.FindAssociatedTokenAddress(owner.PublicKey(), mint)
+		require.NoError(t, err)
+		dest, _, err := solana.FindAssociatedTokenAddress(payTo, mint)
+		require.NoError(t, err)
+
+		tx, err := solana.NewTransaction([]solana.Instruction{
+			computebudget.NewSetComputeUnitLimitInstruction(defaultComputeUnitLimit).Build(),
+			computebudget.NewSetComputeUnitPriceInstruction(price).Build(),
+			token.NewTransferCheckedInstruction(5000, 6, source, mint, dest, owner.PublicKey(), nil).Build(),
+		}, solana.Hash{9}, solana.TransactionPayer(feePayer))
+		require.NoError(t, err)
+
+		if sign {
+			_, err = tx.PartialSign(func(key solana.PublicKey) *solana.PrivateKey {
+				if key.Equals(owner.PublicKey()) {
+					return &owner
+				}
+				return nil
+			})
+			require.NoError(t, err)
+		}
+
+		encoded, err := tx.ToBase64()
+		require.NoError(t, err)
+		return encoded
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		transfer, err := VerifySolanaTransaction(build(t, defaultComputeUnitPrice, true), option)
+		require.NoError(t, err)
+		assert.Equal(t, owner.PublicKey().String(), transfer.Payer)
+		assert.Equal(t, feePayer.String(), transfer.FeePayer)
+		assert.Equal(t, uint64(5000), transfer.Amount)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		_, err := VerifySolanaTransaction(build(t, defaultComputeUnitPrice, false), option)
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("compute price too high", func(t *testing.T) {
+		_, err := VerifySolanaTransaction(build(t, maxComputeUnitPrice+1, true), option)
+		assert.ErrorContains(t, err, "compute unit price")
+	})
+
+	t.Run("fee payer mismatch", func(t *testing.T) {
+		other := *option
+		other.Extra = map[string]interface{}{"feePayer": solana.NewWallet().PublicKey().String()}
+		_, err := VerifySolanaTransaction(build(t, defaultComputeUnitPrice, true), &other)
+		assert.ErrorContains(t, err, "fee payer mismatch")
+	})
+
+	t.Run("recipient mismatch", func(t *testing.T) {
+		other := *option
+		other.PayTo = solana.NewWallet().PublicKey().String()
+		_, err := VerifySolanaTransaction(build(t, defaultComputeUnitPrice, true), &other)
+		assert.ErrorContains(t, err, "destination mismatch")
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		_, err := VerifySolanaTransaction("not-base64!!", option)
+		assert.Error(t, err)
+	})
+}
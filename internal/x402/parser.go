@@ -44,17 +44,55 @@ var solanaNetworkAliases = map[string]string{
 // ParseResult contains the parsed payment requirements and metadata.
 type ParseResult struct {
 	PaymentRequired *PaymentRequired
-	ProtocolVersion int
+	ProtocolVersion int    // Version detected from the transport (header vs body)
+	DeclaredVersion int    // x402Version field of the payload (0 if absent)
 	RawHeader       string // Original header value (v2) or empty (v1)
 	RawBody         []byte // Response body
 }
 
+// VersionMismatch reports whether the payload's x402Version disagrees with
+// the version implied by how it was delivered, e.g. a v2 header declaring
+// x402Version 1.
+func (r *ParseResult) VersionMismatch() bool {
+	return r.DeclaredVersion != 0 && r.DeclaredVersion != r.ProtocolVersion
+}
+
+// RawJSON returns the undecoded payment requirements document: the decoded
+// requirements header for header-based versions, or the response body.
+func (r *ParseResult) RawJSON() ([]byte, error) {
+	if r.RawHeader != "" {
+		decoded, err := base64.StdEncoding.DecodeString(r.RawHeader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 in %s header: %w", HeaderPaymentRequired, err)
+		}
+		return decoded, nil
+	}
+	return r.RawBody, nil
+}
+
+// DetectProtocol returns the protocol a 402 response was delivered with:
+// the newest protocol whose requirements header is present, otherwise the
+// body-based protocol (v1).
+func DetectProtocol(header http.Header) Protocol {
+	detected := protocols[0]
+	for _, p := range protocols {
+		if p.RequirementsHeader != "" && header.Get(p.RequirementsHeader) != "" {
+			detected = p
+		}
+	}
+	return detected
+}
+
 // ParsePaymentRequired extracts payment requirements from a 402 response.
-// Auto-detects v1 vs v2 based on the presence of the Payment-Required header.
+// Auto-detects the protocol version based on the presence of the Payment-Required header.
 //
 // Protocol detection:
 //   - v2: Payment-Required header present (base64 encoded JSON)
 //   - v1: No header, payment requirements in response body (plain JSON)
+//
+// A payload declaring an x402Version this client does not support returns an
+// *UnsupportedVersionError. A declared version that merely disagrees with the
+// detected one is reported via ParseResult.VersionMismatch.
 func ParsePaymentRequired(resp *http.Response) (*ParseResult, error) {
 	result := &ParseResult{}
 
@@ -65,56 +103,81 @@ func ParsePaymentRequired(resp *http.Response) (*ParseResult, error) {
 	}
 	result.RawBody = body
 
-	// Check for v2 header
-	paymentRequiredHeader := resp.Header.Get(HeaderPaymentRequired)
+	protocol := DetectProtocol(resp.Header)
+	result.ProtocolVersion = protocol.Version
+	if protocol.RequirementsHeader != "" {
+		result.RawHeader = resp.Header.Get(protocol.RequirementsHeader)
+	}
+
+	pr, err := ParsePaymentRequiredAs(resp.Header, body, result.ProtocolVersion)
+	if err != nil {
+		return nil, err
+	}
+	result.PaymentRequired = pr
+	result.DeclaredVersion = pr.X402Version
+
+	return result, nil
+}
 
-	if paymentRequiredHeader != "" {
-		// v2: Decode base64 header
-		result.ProtocolVersion = ProtocolV2
-		result.RawHeader = paymentRequiredHeader
+// ParsePaymentRequiredAs parses payment requirements the way a client of the
+// given protocol version would, without auto-detection:
+//   - v1 clients read plain JSON from the response body
+//   - v2 clients decode the base64 Payment-Required header
+func ParsePaymentRequiredAs(header http.Header, body []byte, protocolVersion int) (*PaymentRequired, error) {
+	protocol, err := LookupProtocol(protocolVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PaymentRequired
+
+	if protocol.RequirementsHeader != "" {
+		paymentRequiredHeader := header.Get(protocol.RequirementsHeader)
+		if paymentRequiredHeader == "" {
+			return nil, fmt.Errorf("missing %s header", protocol.RequirementsHeader)
+		}
 
 		decoded, err := base64.StdEncoding.DecodeString(paymentRequiredHeader)
 		if err != nil {
-			return nil, fmt.Errorf("invalid base64 in %s header: %w", HeaderPaymentRequired, err)
+			return nil, fmt.Errorf("invalid base64 in %s header: %w", protocol.RequirementsHeader, err)
 		}
 
-		var pr PaymentRequired
 		if err := json.Unmarshal(decoded, &pr); err != nil {
-			return nil, fmt.Errorf("invalid JSON in %s header: %w", HeaderPaymentRequired, err)
+			return nil, fmt.Errorf("invalid JSON in %s header: %w", protocol.RequirementsHeader, err)
 		}
-		result.PaymentRequired = &pr
 	} else {
-		// v1: Parse body as JSON
-		result.ProtocolVersion = ProtocolV1
-
 		if len(body) == 0 {
 			return nil, fmt.Errorf("empty response body (expected JSON payment requirements)")
 		}
 
-		var pr PaymentRequired
 		if err := json.Unmarshal(body, &pr); err != nil {
 			return nil, fmt.Errorf("invalid JSON in response body: %w", err)
 		}
-		result.PaymentRequired = &pr
+	}
+
+	// Reject versions we cannot interpret rather than guessing
+	if pr.X402Version != 0 {
+		if _, err := LookupProtocol(pr.X402Version); err != nil {
+			return nil, err
+		}
 	}
 
 	// Validate we have payment options
-	if len(result.PaymentRequired.Accepts) == 0 {
+	if len(pr.Accepts) == 0 {
 		return nil, fmt.Errorf("no payment options in accepts[] array")
 	}
 
-	return result, nil
+	return &pr, nil
 }
 
 // ParsePaymentResponse extracts the payment response from a successful response.
 // Checks the appropriate header based on protocol version.
 func ParsePaymentResponse(resp *http.Response, protocolVersion int) (*PaymentResponse, error) {
-	var headerName string
-	if protocolVersion == ProtocolV2 {
-		headerName = HeaderPaymentResponse
-	} else {
-		headerName = HeaderXPaymentResponse
+	protocol, err := LookupProtocol(protocolVersion)
+	if err != nil {
+		return nil, err
 	}
+	headerName := protocol.ResponseHeader
 
 	headerValue := resp.Header.Get(headerName)
 	if headerValue == "" {
@@ -159,6 +159,40 @@ func TestParsePaymentRequired_MultipleOptions(t *testing.T) {
 	assert.Len(t, result.PaymentRequired.Accepts, 3)
 }
 
+func TestParsePaymentRequiredAs(t *testing.T) {
+	v1Body := `{"x402Version":1,"accepts":[{"scheme":"exact","network":"base-sepolia","maxAmountRequired":"1000"}]}`
+	v2JSON, err := json.Marshal(PaymentRequired{
+		X402Version: 2,
+		Accepts:     []PaymentRequirement{{Scheme: "exact", Network: "eip155:84532", Amount: "1000"}},
+	})
+	require.NoError(t, err)
+
+	header := make(http.Header)
+	header.Set(HeaderPaymentRequired, base64.StdEncoding.EncodeToString(v2JSON))
+
+	t.Run("v1 reads body", func(t *testing.T) {
+		pr, err := ParsePaymentRequiredAs(header, []byte(v1Body), ProtocolV1)
+		require.NoError(t, err)
+		assert.Equal(t, "base-sepolia", pr.Accepts[0].Network)
+	})
+
+	t.Run("v2 reads header", func(t *testing.T) {
+		pr, err := ParsePaymentRequiredAs(header, []byte(v1Body), ProtocolV2)
+		require.NoError(t, err)
+		assert.Equal(t, "eip155:84532", pr.Accepts[0].Network)
+	})
+
+	t.Run("v2 without header", func(t *testing.T) {
+		_, err := ParsePaymentRequiredAs(make(http.Header), []byte(v1Body), ProtocolV2)
+		assert.ErrorContains(t, err, "missing")
+	})
+
+	t.Run("v1 without body", func(t *testing.T) {
+		_, err := ParsePaymentRequiredAs(header, nil, ProtocolV1)
+		assert.ErrorContains(t, err, "empty response body")
+	})
+}
+
 func TestParsePaymentResponse_V2_Success(t *testing.T) {
 	pr := PaymentResponse{
 		Success:     true,
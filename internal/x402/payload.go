@@ -60,6 +60,19 @@ func BuildPayloadV1(option *PaymentRequirement, signature string, auth Authoriza
 	}
 }
 
+// BuildPayloadV1Solana constructs the v1 Solana payment payload for the X-PAYMENT header.
+// The transaction parameter is a base64-encoded, partially-signed Solana transaction.
+func BuildPayloadV1Solana(option *PaymentRequirement, transaction string) *PaymentPayloadV1Solana {
+	return &PaymentPayloadV1Solana{
+		X402Version: ProtocolV1,
+		Scheme:      option.Scheme,
+		Network:     option.Network,
+		Payload: ExactSvmPayload{
+			Transaction: transaction,
+		},
+	}
+}
+
 // EncodePayload serializes a payload to base64-encoded JSON.
 func EncodePayload(payload interface{}) (string, error) {
 	jsonBytes, err := json.Marshal(payload)
@@ -77,17 +90,34 @@ func BuildAndEncodePayload(
 	signature string,
 	auth Authorization,
 ) (headerName string, headerValue string, err error) {
-	var payload interface{}
+	protocol, err := LookupProtocol(protocolVersion)
+	if err != nil {
+		return "", "", err
+	}
+	headerName = protocol.PaymentHeader
 
-	if protocolVersion == ProtocolV2 {
-		headerName = HeaderPaymentSignature
-		payload = BuildPayloadV2(resource, option, signature, auth)
-	} else {
-		headerName = HeaderXPayment
-		payload = BuildPayloadV1(option, signature, auth)
+	headerValue, err = EncodePayload(protocol.evmPayload(resource, option, signature, auth))
+	if err != nil {
+		return "", "", err
+	}
+
+	return headerName, headerValue, nil
+}
+
+// BuildAndEncodeSolanaPayload builds and encodes a Solana payment payload based on protocol version.
+func BuildAndEncodeSolanaPayload(
+	protocolVersion int,
+	resource ResourceInfo,
+	option *PaymentRequirement,
+	transaction string,
+) (headerName string, headerValue string, err error) {
+	protocol, err := LookupProtocol(protocolVersion)
+	if err != nil {
+		return "", "", err
 	}
+	headerName = protocol.PaymentHeader
 
-	headerValue, err = EncodePayload(payload)
+	headerValue, err = EncodePayload(protocol.solanaPayload(resource, option, transaction))
 	if err != nil {
 		return "", "", err
 	}
@@ -175,3 +175,36 @@ func TestBuildAndEncodePayload_V1(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, payload.X402Version)
 }
+
+func TestBuildAndEncodeSolanaPayload(t *testing.T) {
+	option := &PaymentRequirement{
+		Scheme:  "exact",
+		Network: SolanaDevnet,
+		Amount:  "1000",
+	}
+
+	t.Run("v2", func(t *testing.T) {
+		headerName, headerValue, err := BuildAndEncodeSolanaPayload(ProtocolV2, ResourceInfo{URL: "https://example.com"}, option, "dHg=")
+		require.NoError(t, err)
+		assert.Equal(t, HeaderPaymentSignature, headerName)
+
+		decoded, _ := base64.StdEncoding.DecodeString(headerValue)
+		var payload PaymentPayloadV2Solana
+		require.NoError(t, json.Unmarshal(decoded, &payload))
+		assert.Equal(t, 2, payload.X402Version)
+		assert.Equal(t, "dHg=", payload.Payload.Transaction)
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		headerName, headerValue, err := BuildAndEncodeSolanaPayload(ProtocolV1, ResourceInfo{}, option, "dHg=")
+		require.NoError(t, err)
+		assert.Equal(t, HeaderXPayment, headerName)
+
+		decoded, _ := base64.StdEncoding.DecodeString(headerValue)
+		var payload PaymentPayloadV1Solana
+		require.NoError(t, json.Unmarshal(decoded, &payload))
+		assert.Equal(t, 1, payload.X402Version)
+		assert.Equal(t, SolanaDevnet, payload.Network)
+		assert.Equal(t, "dHg=", payload.Payload.Transaction)
+	})
+}
@@ -0,0 +1,313 @@
+package x402
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// schemaFiles holds the JSON schemas used for strict validation.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Violation is a single strict-validation failure, located by an RFC 6901
+// JSON pointer into the validated document ("" is the document root).
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// String formats the violation as "pointer: message".
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return pointer + ": " + v.Message
+}
+
+// ValidatePaymentRequired strictly validates raw PaymentRequired JSON against
+// the embedded schema for the given protocol version. Unlike ParsePaymentRequired,
+// unknown fields, missing fields and wrongly-typed values are all reported.
+// The x402Version field must agree with protocolVersion.
+func ValidatePaymentRequired(data []byte, protocolVersion int) ([]Violation, error) {
+	if _, err := LookupProtocol(protocolVersion); err != nil {
+		return nil, err
+	}
+	return validateDocument(fmt.Sprintf("payment-required-v%d", protocolVersion), data)
+}
+
+// ValidatePaymentResponse strictly validates raw PaymentResponse JSON (the decoded
+// PAYMENT-RESPONSE or X-PAYMENT-RESPONSE header) for the given protocol version.
+func ValidatePaymentResponse(data []byte, protocolVersion int) ([]Violation, error) {
+	if _, err := LookupProtocol(protocolVersion); err != nil {
+		return nil, err
+	}
+	return validateDocument(fmt.Sprintf("payment-response-v%d", protocolVersion), data)
+}
+
+// jsonSchema is the subset of JSON Schema used by the embedded x402 schemas.
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 schemaTypes            `json:"type"`
+	Const                json.RawMessage        `json:"const"`
+	Enum                 []json.RawMessage      `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MinLength            *int                   `json:"minLength"`
+	Minimum              *float64               `json:"minimum"`
+	Pattern              string                 `json:"pattern"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["object", "null"].
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+var (
+	schemaCache   = make(map[string]*jsonSchema)
+	schemaCacheMu sync.Mutex
+)
+
+// loadSchema reads and compiles an embedded schema by name.
+func loadSchema(name string) (*jsonSchema, error) {
+	schemaCacheMu.Lock()
+	defer schemaCacheMu.Unlock()
+
+	if s, ok := schemaCache[name]; ok {
+		return s, nil
+	}
+
+	data, err := schemaFiles.ReadFile("schemas/" + name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no schema %q", name)
+	}
+
+	var s jsonSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %w", name, err)
+	}
+	if err := compileSchema(&s); err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %w", name, err)
+	}
+
+	schemaCache[name] = &s
+	return &s, nil
+}
+
+// compileSchema pre-compiles patterns throughout the schema tree.
+func compileSchema(s *jsonSchema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	for _, child := range s.Properties {
+		if err := compileSchema(child); err != nil {
+			return err
+		}
+	}
+	for _, child := range s.Defs {
+		if err := compileSchema(child); err != nil {
+			return err
+		}
+	}
+	return compileSchema(s.Items)
+}
+
+func validateDocument(schemaName string, data []byte) ([]Violation, error) {
+	root, err := loadSchema(schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	v := &schemaValidator{root: root}
+	v.validate(root, doc, "")
+	return v.violations, nil
+}
+
+type schemaValidator struct {
+	root       *jsonSchema
+	violations []Violation
+}
+
+func (v *schemaValidator) addf(pointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) validate(s *jsonSchema, value interface{}, pointer string) {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		ref, ok := v.root.Defs[name]
+		if !ok {
+			v.addf(pointer, "schema error: unresolved reference %s", s.Ref)
+			return
+		}
+		s = ref
+	}
+
+	if len(s.Type) > 0 && !matchesType(value, s.Type) {
+		v.addf(pointer, "expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeName(value))
+		return
+	}
+
+	if s.Const != nil && !jsonEqual(value, s.Const) {
+		v.addf(pointer, "must be %s, got %s", string(s.Const), formatJSONValue(value))
+	}
+
+	if len(s.Enum) > 0 {
+		matched := false
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			allowed[i] = string(e)
+			if jsonEqual(value, e) {
+				matched = true
+			}
+		}
+		if !matched {
+			v.addf(pointer, "must be one of %s, got %s", strings.Join(allowed, ", "), formatJSONValue(value))
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(s, val, pointer)
+	case []interface{}:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			v.addf(pointer, "must have at least %d item(s), got %d", *s.MinItems, len(val))
+		}
+		if s.Items != nil {
+			for i, item := range val {
+				v.validate(s.Items, item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	case string:
+		if s.MinLength != nil && len(val) < *s.MinLength {
+			v.addf(pointer, "must not be empty")
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			v.addf(pointer, "%q does not match pattern %s", val, s.Pattern)
+		}
+	case json.Number:
+		if s.Minimum != nil {
+			if f, err := val.Float64(); err == nil && f < *s.Minimum {
+				v.addf(pointer, "must be >= %v, got %s", *s.Minimum, val)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s *jsonSchema, obj map[string]interface{}, pointer string) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.addf(pointer+"/"+escapePointer(name), "required field missing")
+		}
+	}
+
+	// Sorted for deterministic output
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPointer := pointer + "/" + escapePointer(k)
+		if prop, ok := s.Properties[k]; ok {
+			v.validate(prop, obj[k], childPointer)
+		} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+			v.addf(childPointer, "unknown field")
+		}
+	}
+}
+
+// escapePointer escapes a JSON pointer reference token (RFC 6901).
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func matchesType(value interface{}, types []string) bool {
+	actual := jsonTypeName(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeName(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func jsonEqual(value interface{}, raw json.RawMessage) bool {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var expected interface{}
+	if err := dec.Decode(&expected); err != nil {
+		return false
+	}
+	return formatJSONValue(value) == formatJSONValue(expected)
+}
+
+func formatJSONValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
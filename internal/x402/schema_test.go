@@ -0,0 +1,154 @@
+package x402
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validV2PaymentRequired = `{
+  "x402Version": 2,
+  "resource": {"url": "https://api.example.com/data"},
+  "accepts": [{
+    "scheme": "exact",
+    "network": "eip155:84532",
+    "amount": "10000",
+    "asset": "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+    "payTo": "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+    "maxTimeoutSeconds": 60,
+    "extra": {"name": "USDC", "version": "2"}
+  }]
+}`
+
+const validV1PaymentRequired = `{
+  "x402Version": 1,
+  "error": "X-PAYMENT header is required",
+  "accepts": [{
+    "scheme": "exact",
+    "network": "base-sepolia",
+    "maxAmountRequired": "10000",
+    "resource": "https://api.example.com/data",
+    "description": "Premium data",
+    "mimeType": "application/json",
+    "payTo": "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+    "maxTimeoutSeconds": 60,
+    "asset": "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+    "outputSchema": null,
+    "extra": {"name": "USDC", "version": "2"}
+  }]
+}`
+
+func TestValidatePaymentRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		version  int
+		expected []Violation
+	}{
+		{
+			name:    "valid v2",
+			data:    validV2PaymentRequired,
+			version: ProtocolV2,
+		},
+		{
+			name:    "valid v1",
+			data:    validV1PaymentRequired,
+			version: ProtocolV1,
+		},
+		{
+			name: "v2 numeric amount and missing payTo",
+			data: `{"x402Version": 2, "resource": {"url": "https://a"}, "accepts": [
+				{"scheme": "exact", "network": "eip155:8453", "amount": 10000, "asset": "0xa", "maxTimeoutSeconds": 60}]}`,
+			version: ProtocolV2,
+			expected: []Violation{
+				{Pointer: "/accepts/0/payTo", Message: "required field missing"},
+				{Pointer: "/accepts/0/amount", Message: "expected string, got integer"},
+			},
+		},
+		{
+			name: "v2 legacy network name and unknown field",
+			data: `{"x402Version": 2, "resource": {"url": "https://a"}, "accepts": [
+				{"scheme": "exact", "network": "base", "amount": "1", "asset": "0xa", "payTo": "0xb", "maxTimeoutSeconds": 60, "maxAmountRequired": "1"}]}`,
+			version: ProtocolV2,
+			expected: []Violation{
+				{Pointer: "/accepts/0/maxAmountRequired", Message: "unknown field"},
+				{Pointer: "/accepts/0/network", Message: `"base" does not match pattern ^[-a-z0-9]{3,8}:[-_a-zA-Z0-9]{1,32}$`},
+			},
+		},
+		{
+			name:    "version mismatch",
+			data:    validV1PaymentRequired,
+			version: ProtocolV2,
+			expected: []Violation{
+				{Pointer: "/resource", Message: "required field missing"},
+				{Pointer: "/accepts/0/amount", Message: "required field missing"},
+				{Pointer: "/accepts/0/description", Message: "unknown field"},
+				{Pointer: "/accepts/0/maxAmountRequired", Message: "unknown field"},
+				{Pointer: "/accepts/0/mimeType", Message: "unknown field"},
+				{Pointer: "/accepts/0/network", Message: `"base-sepolia" does not match pattern ^[-a-z0-9]{3,8}:[-_a-zA-Z0-9]{1,32}$`},
+				{Pointer: "/accepts/0/outputSchema", Message: "unknown field"},
+				{Pointer: "/accepts/0/resource", Message: "unknown field"},
+				{Pointer: "/x402Version", Message: "must be 2, got 1"},
+			},
+		},
+		{
+			name:    "empty accepts",
+			data:    `{"x402Version": 2, "resource": {"url": "https://a"}, "accepts": []}`,
+			version: ProtocolV2,
+			expected: []Violation{
+				{Pointer: "/accepts", Message: "must have at least 1 item(s), got 0"},
+			},
+		},
+		{
+			name:    "not an object",
+			data:    `[]`,
+			version: ProtocolV1,
+			expected: []Violation{
+				{Pointer: "", Message: "expected object, got array"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := ValidatePaymentRequired([]byte(tt.data), tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, violations)
+		})
+	}
+}
+
+func TestValidatePaymentRequired_Errors(t *testing.T) {
+	_, err := ValidatePaymentRequired([]byte(`{"x402Version":`), ProtocolV2)
+	assert.ErrorContains(t, err, "invalid JSON")
+
+	_, err = ValidatePaymentRequired([]byte(`{}`), 3)
+	assert.ErrorContains(t, err, "unsupported protocol version 3")
+}
+
+func TestValidatePaymentResponse(t *testing.T) {
+	violations, err := ValidatePaymentResponse([]byte(
+		`{"success": true, "transaction": "0xabc", "network": "eip155:84532", "payer": "0xdef"}`), ProtocolV2)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = ValidatePaymentResponse([]byte(
+		`{"success": "true", "transaction": "0xabc", "network": "base-sepolia", "txHash": "0xabc"}`), ProtocolV1)
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{
+		{Pointer: "/success", Message: "expected boolean, got string"},
+		{Pointer: "/txHash", Message: "unknown field"},
+	}, violations)
+}
+
+func TestViolationString(t *testing.T) {
+	assert.Equal(t, "/accepts/0/payTo: required field missing",
+		Violation{Pointer: "/accepts/0/payTo", Message: "required field missing"}.String())
+	assert.Equal(t, "/: expected object, got array",
+		Violation{Message: "expected object, got array"}.String())
+}
+
+func TestEscapePointer(t *testing.T) {
+	assert.Equal(t, "a~1b~0c", escapePointer("a/b~c"))
+}
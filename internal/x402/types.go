@@ -1,6 +1,8 @@
 // Package x402 implements the x402 payment protocol types and parsing.
 package x402
 
+import "encoding/json"
+
 // PaymentRequired represents the decoded payment requirements from a 402 response.
 // Supports both v1 and v2 protocol formats.
 type PaymentRequired struct {
@@ -117,12 +119,79 @@ type PaymentPayloadV1 struct {
 	Payload     ExactEvmPayload `json:"payload"`
 }
 
+// PaymentPayloadV1Solana is the v1 protocol payment payload structure for Solana.
+// Sent in the X-PAYMENT header (base64 encoded).
+type PaymentPayloadV1Solana struct {
+	X402Version int             `json:"x402Version"`
+	Scheme      string          `json:"scheme"`
+	Network     string          `json:"network"`
+	Payload     ExactSvmPayload `json:"payload"`
+}
+
 // PaymentResponse represents the server's response after successful payment.
+// It is also the body returned by a facilitator's /settle endpoint.
 type PaymentResponse struct {
 	Success     bool   `json:"success"`
 	Transaction string `json:"transaction,omitempty"`
 	Network     string `json:"network,omitempty"`
+	Payer       string `json:"payer,omitempty"`
 	Error       string `json:"error,omitempty"`
+	ErrorReason string `json:"errorReason,omitempty"`
+}
+
+// PaymentPayload is a version-agnostic view of a decoded payment payload.
+// v1 payloads carry scheme and network at the top level, v2 payloads carry
+// them in Accepted. The scheme-specific payload is left undecoded.
+type PaymentPayload struct {
+	X402Version int             `json:"x402Version"`
+	Scheme      string          `json:"scheme,omitempty"`
+	Network     string          `json:"network,omitempty"`
+	Resource    *ResourceInfo   `json:"resource,omitempty"`
+	Accepted    *AcceptedOption `json:"accepted,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// GetScheme returns the payment scheme, handling v1 vs v2 placement.
+func (p *PaymentPayload) GetScheme() string {
+	if p.Accepted != nil && p.Accepted.Scheme != "" {
+		return p.Accepted.Scheme
+	}
+	return p.Scheme
+}
+
+// GetNetwork returns the payment network, handling v1 vs v2 placement.
+func (p *PaymentPayload) GetNetwork() string {
+	if p.Accepted != nil && p.Accepted.Network != "" {
+		return p.Accepted.Network
+	}
+	return p.Network
+}
+
+// FacilitatorRequest is the body of a facilitator /verify or /settle request.
+type FacilitatorRequest struct {
+	X402Version         int                `json:"x402Version"`
+	PaymentPayload      PaymentPayload     `json:"paymentPayload"`
+	PaymentRequirements PaymentRequirement `json:"paymentRequirements"`
+}
+
+// VerifyResponse is the body returned by a facilitator's /verify endpoint.
+type VerifyResponse struct {
+	IsValid        bool   `json:"isValid"`
+	InvalidReason  string `json:"invalidReason,omitempty"`
+	InvalidMessage string `json:"invalidMessage,omitempty"`
+	Payer          string `json:"payer,omitempty"`
+}
+
+// SupportedKind describes a scheme/network pair a facilitator can handle.
+type SupportedKind struct {
+	X402Version int    `json:"x402Version"`
+	Scheme      string `json:"scheme"`
+	Network     string `json:"network"`
+}
+
+// SupportedResponse is the body returned by a facilitator's /supported endpoint.
+type SupportedResponse struct {
+	Kinds []SupportedKind `json:"kinds"`
 }
 
 // Protocol version constants.
@@ -0,0 +1,128 @@
+package x402
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Protocol describes how one x402 protocol version is carried over HTTP.
+// Adding a protocol version means adding an entry to protocols; parsing,
+// header selection, amounts, payment payloads and version flags all read
+// from the registry.
+type Protocol struct {
+	Version int
+
+	// RequirementsHeader carries the base64 PaymentRequired.
+	// Empty means the requirements are plain JSON in the response body.
+	RequirementsHeader string
+
+	// PaymentHeader carries the base64 payment payload on the paid retry.
+	PaymentHeader string
+
+	// ResponseHeader carries the base64 settlement response.
+	ResponseHeader string
+
+	// AmountField is the JSON name of the amount in each payment requirement.
+	AmountField string
+
+	amount        func(option *PaymentRequirement) string
+	evmPayload    func(resource ResourceInfo, option *PaymentRequirement, signature string, auth Authorization) interface{}
+	solanaPayload func(resource ResourceInfo, option *PaymentRequirement, transaction string) interface{}
+}
+
+// protocols lists supported protocol versions, oldest first.
+var protocols = []Protocol{
+	{
+		Version:        ProtocolV1,
+		PaymentHeader:  HeaderXPayment,
+		ResponseHeader: HeaderXPaymentResponse,
+		AmountField:    "maxAmountRequired",
+		amount:         func(option *PaymentRequirement) string { return option.MaxAmountRequired },
+		evmPayload: func(_ ResourceInfo, option *PaymentRequirement, signature string, auth Authorization) interface{} {
+			return BuildPayloadV1(option, signature, auth)
+		},
+		solanaPayload: func(_ ResourceInfo, option *PaymentRequirement, transaction string) interface{} {
+			return BuildPayloadV1Solana(option, transaction)
+		},
+	},
+	{
+		Version:            ProtocolV2,
+		RequirementsHeader: HeaderPaymentRequired,
+		PaymentHeader:      HeaderPaymentSignature,
+		ResponseHeader:     HeaderPaymentResponse,
+		AmountField:        "amount",
+		amount:             func(option *PaymentRequirement) string { return option.Amount },
+		evmPayload: func(resource ResourceInfo, option *PaymentRequirement, signature string, auth Authorization) interface{} {
+			return BuildPayloadV2(resource, option, signature, auth)
+		},
+		solanaPayload: func(resource ResourceInfo, option *PaymentRequirement, transaction string) interface{} {
+			return BuildPayloadV2Solana(resource, option, transaction)
+		},
+	},
+}
+
+// UnsupportedVersionError is returned for protocol versions this client does not know.
+type UnsupportedVersionError struct {
+	Version int
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("unsupported protocol version %d", e.Version)
+}
+
+// Protocols returns all supported protocol versions, oldest first.
+func Protocols() []Protocol {
+	return append([]Protocol(nil), protocols...)
+}
+
+// LookupProtocol returns the protocol for a version number.
+// Unknown versions return an *UnsupportedVersionError.
+func LookupProtocol(version int) (Protocol, error) {
+	for _, p := range protocols {
+		if p.Version == version {
+			return p, nil
+		}
+	}
+	return Protocol{}, &UnsupportedVersionError{Version: version}
+}
+
+// String returns the short version name, e.g. "v2".
+func (p Protocol) String() string {
+	return fmt.Sprintf("v%d", p.Version)
+}
+
+// Amount returns the amount field this protocol version reads from an option.
+func (p Protocol) Amount(option *PaymentRequirement) string {
+	return p.amount(option)
+}
+
+// ParseProtocolVersion parses a user-supplied protocol version such as "v1" or "2".
+// An empty string returns 0, meaning the version should be auto-detected.
+func ParseProtocolVersion(s string) (int, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(s))
+	if trimmed == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(trimmed, "v"))
+	if err != nil {
+		return 0, fmt.Errorf("unknown protocol version %q (expected %s)", s, supportedVersionList())
+	}
+	if _, err := LookupProtocol(version); err != nil {
+		return 0, fmt.Errorf("%w (expected %s)", err, supportedVersionList())
+	}
+	return version, nil
+}
+
+// supportedVersionList formats supported versions as "v1 or v2".
+func supportedVersionList() string {
+	names := make([]string, len(protocols))
+	for i, p := range protocols {
+		names[i] = p.String()
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
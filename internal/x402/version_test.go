@@ -0,0 +1,153 @@
+package x402
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupProtocol(t *testing.T) {
+	v1, err := LookupProtocol(ProtocolV1)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", v1.String())
+	assert.Empty(t, v1.RequirementsHeader)
+	assert.Equal(t, HeaderXPayment, v1.PaymentHeader)
+	assert.Equal(t, HeaderXPaymentResponse, v1.ResponseHeader)
+
+	v2, err := LookupProtocol(ProtocolV2)
+	require.NoError(t, err)
+	assert.Equal(t, HeaderPaymentRequired, v2.RequirementsHeader)
+	assert.Equal(t, HeaderPaymentSignature, v2.PaymentHeader)
+	assert.Equal(t, HeaderPaymentResponse, v2.ResponseHeader)
+
+	_, err = LookupProtocol(3)
+	var unsupported *UnsupportedVersionError
+	require.True(t, errors.As(err, &unsupported))
+	assert.Equal(t, 3, unsupported.Version)
+	assert.EqualError(t, err, "unsupported protocol version 3")
+}
+
+func TestProtocolAmount(t *testing.T) {
+	option := &PaymentRequirement{Amount: "200", MaxAmountRequired: "100"}
+
+	v1, _ := LookupProtocol(ProtocolV1)
+	v2, _ := LookupProtocol(ProtocolV2)
+	assert.Equal(t, "100", v1.Amount(option))
+	assert.Equal(t, "200", v2.Amount(option))
+}
+
+// Every registered version builds its own payloads, so a new version cannot
+// silently fall back to another's format.
+func TestProtocolPayloads(t *testing.T) {
+	option := &PaymentRequirement{Scheme: "exact", Network: "eip155:84532", Amount: "200", MaxAmountRequired: "200"}
+
+	for _, protocol := range Protocols() {
+		t.Run(protocol.String(), func(t *testing.T) {
+			require.NotNil(t, protocol.amount)
+			require.NotNil(t, protocol.evmPayload)
+			require.NotNil(t, protocol.solanaPayload)
+
+			for _, encode := range []func() (string, string, error){
+				func() (string, string, error) {
+					return BuildAndEncodePayload(protocol.Version, ResourceInfo{}, option, "0xsig", Authorization{})
+				},
+				func() (string, string, error) {
+					return BuildAndEncodeSolanaPayload(protocol.Version, ResourceInfo{}, option, "dHg=")
+				},
+			} {
+				name, value, err := encode()
+				require.NoError(t, err)
+				assert.Equal(t, protocol.PaymentHeader, name)
+
+				decoded, err := base64.StdEncoding.DecodeString(value)
+				require.NoError(t, err)
+				var payload struct {
+					X402Version int `json:"x402Version"`
+				}
+				require.NoError(t, json.Unmarshal(decoded, &payload))
+				assert.Equal(t, protocol.Version, payload.X402Version)
+			}
+		})
+	}
+}
+
+func TestParsePaymentRequired_VersionHandling(t *testing.T) {
+	newResponse := func(headerJSON, body string) *http.Response {
+		headers := map[string]string{}
+		if headerJSON != "" {
+			headers[HeaderPaymentRequired] = base64.StdEncoding.EncodeToString([]byte(headerJSON))
+		}
+		return mockResponse(402, body, headers)
+	}
+	accepts := `"accepts":[{"scheme":"exact","network":"base","amount":"1","maxAmountRequired":"1","asset":"0xa","payTo":"0xb"}]`
+
+	t.Run("consistent v2", func(t *testing.T) {
+		result, err := ParsePaymentRequired(newResponse(`{"x402Version":2,`+accepts+`}`, ""))
+		require.NoError(t, err)
+		assert.Equal(t, ProtocolV2, result.ProtocolVersion)
+		assert.Equal(t, ProtocolV2, result.DeclaredVersion)
+		assert.False(t, result.VersionMismatch())
+	})
+
+	t.Run("header declaring v1", func(t *testing.T) {
+		result, err := ParsePaymentRequired(newResponse(`{"x402Version":1,`+accepts+`}`, ""))
+		require.NoError(t, err)
+		assert.Equal(t, ProtocolV2, result.ProtocolVersion)
+		assert.Equal(t, ProtocolV1, result.DeclaredVersion)
+		assert.True(t, result.VersionMismatch())
+	})
+
+	t.Run("body declaring v2", func(t *testing.T) {
+		result, err := ParsePaymentRequired(newResponse("", `{"x402Version":2,`+accepts+`}`))
+		require.NoError(t, err)
+		assert.Equal(t, ProtocolV1, result.ProtocolVersion)
+		assert.True(t, result.VersionMismatch())
+	})
+
+	t.Run("missing version is not a mismatch", func(t *testing.T) {
+		result, err := ParsePaymentRequired(newResponse("", `{`+accepts+`}`))
+		require.NoError(t, err)
+		assert.False(t, result.VersionMismatch())
+	})
+
+	t.Run("future version", func(t *testing.T) {
+		_, err := ParsePaymentRequired(newResponse(`{"x402Version":3,`+accepts+`}`, ""))
+		var unsupported *UnsupportedVersionError
+		require.True(t, errors.As(err, &unsupported))
+		assert.EqualError(t, err, "unsupported protocol version 3")
+	})
+}
+
+func TestParseProtocolVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"v1", ProtocolV1, false},
+		{"1", ProtocolV1, false},
+		{"V2", ProtocolV2, false},
+		{"2", ProtocolV2, false},
+		{"v3", 0, true},
+		{"latest", 0, true},
+		{"v", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseProtocolVersion(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}